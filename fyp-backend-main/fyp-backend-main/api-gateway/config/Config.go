@@ -0,0 +1,310 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"shared/envconfig"
+	"shared/platform"
+)
+
+// Config holds every setting of the api-gateway, see shared/envconfig for how it is read.
+type Config struct {
+	// Server
+	Port               int           `env:"PORT" default:"8000" usage:"HTTP listen port"`
+	AdminPort          int           `env:"ADMIN_PORT" default:"9100" usage:"port of the admin server serving /metrics, keep it private"`
+	ShutdownTimeout    time.Duration `env:"SHUTDOWN_TIMEOUT" default:"20s" usage:"how long in-flight requests may drain after SIGTERM"`
+	StartupMaxWait     time.Duration `env:"STARTUP_MAX_WAIT" default:"2m" usage:"how long connections to dependencies are retried at startup"`
+	GatewayAddress     string        `env:"GATEWAY_ADDRESS" default:"http://localhost:8000" usage:"public base URL of the gateway, used in file links"`
+	TrustedProxies     []string      `env:"TRUSTED_PROXIES" usage:"proxy IPs or CIDRs allowed to set X-Forwarded-For"`
+	CORSAllowedOrigins []string      `env:"CORS_ALLOWED_ORIGINS" default:"*" usage:"origins allowed by CORS"`
+
+	// Request deadlines, backend calls inherit them
+	RequestTimeout time.Duration `env:"REQUEST_TIMEOUT" default:"15s" usage:"deadline of a request, including its backend calls"`
+	RouteTimeouts  []string      `env:"ROUTE_TIMEOUTS" default:"POST /docs/:bucket=2m,GET /docs/:bucket/:file=2m,GET /blobs/:bucket/*key=2m,PUT /blobs/:bucket/*key=2m,POST /docs/:bucket/tus=10m,PATCH /docs/:bucket/tus/:id=10m" usage:"per-route deadlines as METHOD /path=duration, using the route pattern"`
+
+	// Rate limits per route group, counted per user, API key or client IP
+	RateLimitEnabled bool     `env:"RATE_LIMIT_ENABLED" default:"true" usage:"reject requests over the rate limits with 429"`
+	RateLimits       []string `env:"RATE_LIMITS" default:"auth=20/1m,upload=30/1m,integration=300/1m,workflow=300/1m,api-key=60/1m" usage:"requests allowed per route group as group=count/window, bursts up to count are allowed"`
+
+	// Backend services
+	AuthServiceAddr        string `env:"AUTH_SERVICE_ADDR" default:"auth-service:50000" usage:"auth service host:port"`
+	IntegrationServiceAddr string `env:"INTEGRATION_SERVICE_ADDR" default:"integration-service:50051" usage:"integration service host:port"`
+	WorkflowServiceAddr    string `env:"WORKFLOW_SERVICE_ADDR" default:"workflow-service:50002" usage:"workflow service host:port"`
+	InternalAuthKeyFile    string `env:"INTERNAL_AUTH_KEY_FILE" required:"true" usage:"Ed25519 private key (PKCS#8 PEM) backend calls are signed with"`
+	TLSCAFile              string `env:"GRPC_TLS_CA_FILE" usage:"CA bundle the services' certificates must chain to, enables TLS"`
+	TLSCertFile            string `env:"GRPC_TLS_CERT_FILE" usage:"client certificate presented for mutual TLS"`
+	TLSKeyFile             string `env:"GRPC_TLS_KEY_FILE" usage:"private key of the client certificate"`
+
+	// Backend calls, spread over every address a service name resolves to
+	GRPCRetryMaxAttempts int           `env:"GRPC_RETRY_MAX_ATTEMPTS" default:"3" usage:"attempts of idempotent calls (Get*, List*, Search*) failing with UNAVAILABLE, 1 disables retries"`
+	GRPCKeepaliveTime    time.Duration `env:"GRPC_KEEPALIVE_TIME" default:"30s" usage:"idle time after which connections are pinged"`
+	GRPCKeepaliveTimeout time.Duration `env:"GRPC_KEEPALIVE_TIMEOUT" default:"10s" usage:"how long a ping may go unanswered before the connection is closed"`
+	GRPCBreakerFailures  int           `env:"GRPC_BREAKER_FAILURES" default:"5" usage:"consecutive UNAVAILABLE calls after which calls to the service fail fast"`
+	GRPCBreakerCooldown  time.Duration `env:"GRPC_BREAKER_COOLDOWN" default:"10s" usage:"how long calls fail fast before one is let through to probe the service"`
+
+	// Access tokens, must match the auth service
+	JWTIssuer   string `env:"JWT_ISSUER" default:"flomny-auth-service" usage:"expected iss claim"`
+	JWTAudience string `env:"JWT_AUDIENCE" default:"flomny-api" usage:"expected aud claim"`
+
+	// Redis
+	RedisAddr     string `env:"REDIS_ADDR" default:"redis:6379" usage:"Redis host:port"`
+	RedisPassword string `env:"REDIS_PASSWORD" secret:"true" usage:"Redis password"`
+	RedisDB       int    `env:"REDIS_DB" default:"0" usage:"Redis database number"`
+
+	// File storage
+	StorageDriver      string        `env:"STORAGE_DRIVER" default:"s3" usage:"where uploaded files are kept: s3, local or memory"`
+	StorageLocalDir    string        `env:"STORAGE_LOCAL_DIR" default:"./data/storage" usage:"directory of the local driver"`
+	StorageSigningKey  string        `env:"STORAGE_SIGNING_KEY" secret:"true" usage:"key signing the presigned URLs of the local and memory drivers, at least 32 characters"`
+	UploadMaxSize      int           `env:"UPLOAD_MAX_SIZE" default:"104857600" usage:"largest file in bytes uploaded directly or through a presigned upload URL"`
+	UploadURLExpiry    time.Duration `env:"UPLOAD_URL_EXPIRY" default:"15m" usage:"how long presigned upload URLs are valid, uploads not completed by then are abandoned"`
+	DownloadURLExpiry  time.Duration `env:"DOWNLOAD_URL_EXPIRY" default:"5m" usage:"how long presigned download URLs are valid"`
+	ResumableMaxSize   int           `env:"RESUMABLE_UPLOAD_MAX_SIZE" default:"2147483648" usage:"largest file in bytes uploaded through the resumable tus endpoint"`
+	ResumablePartSize  int           `env:"RESUMABLE_UPLOAD_PART_SIZE" default:"8388608" usage:"bytes of each part resumable uploads are stored in, the gateway buffers one part per request"`
+	ResumableExpiry    time.Duration `env:"RESUMABLE_UPLOAD_EXPIRY" default:"24h" usage:"how long a resumable upload can take, unfinished uploads are removed afterwards"`
+	ResumableSweep     time.Duration `env:"RESUMABLE_UPLOAD_SWEEP_INTERVAL" default:"10m" usage:"how often expired resumable and presigned uploads and orphaned parts are removed"`
+	StorageNamespaces  []string      `env:"STORAGE_NAMESPACES" default:"integrations=flomny-integrations,workflows=flomny-workflows,assets=flomny-assets" usage:"bucket of each namespace files are uploaded to as namespace=bucket, other buckets can't be reached"`
+	RegistrySince      string        `env:"STORAGE_REGISTRY_SINCE" usage:"RFC 3339 time the upload registry was deployed, files without a record can be claimed by the owner of an integration or workflow created before it, claiming is off when unset"`
+	AWSRegion          string        `env:"AWS_REGION" usage:"S3 region, required by the s3 driver"`
+	AWSAccessKeyID     string        `env:"AWS_ACCESS_KEY_ID" secret:"true" usage:"S3 access key, the default AWS credential chain is used when unset"`
+	AWSSecretAccessKey string        `env:"AWS_SECRET_ACCESS_KEY" secret:"true" usage:"S3 secret key"`
+	S3Endpoint         string        `env:"S3_ENDPOINT" usage:"S3 compatible endpoint replacing AWS, e.g. http://minio:9000"`
+	S3ForcePathStyle   bool          `env:"S3_FORCE_PATH_STYLE" usage:"address buckets in the URL path, as MinIO needs"`
+
+	// Logging
+	LogLevel  string `env:"LOG_LEVEL" default:"info" usage:"debug, info, warn or error"`
+	LogFormat string `env:"LOG_FORMAT" default:"json" usage:"json or text"`
+
+	// Tracing
+	TracingExporter string `env:"TRACING_EXPORTER" default:"none" usage:"where spans are sent: none, stdout or otlp"`
+	TracingSampler  string `env:"TRACING_SAMPLER" default:"always_on" usage:"always_on, always_off or the ratio of new traces sampled, calls follow the caller's decision"`
+	OTLPEndpoint    string `env:"OTLP_ENDPOINT" default:"localhost:4317" usage:"OTLP gRPC collector host:port"`
+	OTLPInsecure    bool   `env:"OTLP_INSECURE" usage:"send spans to the collector without TLS"`
+}
+
+// Current is the configuration loaded at startup.
+var Current *Config
+
+// Load reads the configuration from the defaults, config file, environment and command line flags.
+func Load() (*Config, error) {
+	cfg := &Config{}
+	if err := envconfig.Load(cfg, os.Args[1:]); err != nil {
+		return nil, fmt.Errorf("invalid configuration:\n%w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid configuration:\n%w", err)
+	}
+	return cfg, nil
+}
+
+// Validate checks settings that depend on each other.
+func (c *Config) Validate() error {
+	var errs []error
+	if c.Port < 1 || c.Port > 65535 {
+		errs = append(errs, fmt.Errorf("PORT must be between 1 and 65535, got %d", c.Port))
+	}
+	if c.AdminPort < 1 || c.AdminPort > 65535 || c.AdminPort == c.Port {
+		errs = append(errs, fmt.Errorf("ADMIN_PORT must be between 1 and 65535 and differ from PORT, got %d", c.AdminPort))
+	}
+	if c.ShutdownTimeout <= 0 {
+		errs = append(errs, fmt.Errorf("SHUTDOWN_TIMEOUT must be positive, got %s", c.ShutdownTimeout))
+	}
+	if c.StartupMaxWait <= 0 {
+		errs = append(errs, fmt.Errorf("STARTUP_MAX_WAIT must be positive, got %s", c.StartupMaxWait))
+	}
+	if u, err := url.Parse(c.GatewayAddress); err != nil || u.Scheme == "" || u.Host == "" {
+		errs = append(errs, fmt.Errorf("GATEWAY_ADDRESS must be an absolute URL, got %q", c.GatewayAddress))
+	}
+	if c.RequestTimeout <= 0 {
+		errs = append(errs, fmt.Errorf("REQUEST_TIMEOUT must be positive, got %s", c.RequestTimeout))
+	}
+	if _, err := c.RouteTimeoutMap(); err != nil {
+		errs = append(errs, err)
+	}
+	if _, err := c.RateLimitMap(); err != nil {
+		errs = append(errs, err)
+	}
+	if c.AuthServiceAddr == "" || c.IntegrationServiceAddr == "" || c.WorkflowServiceAddr == "" {
+		errs = append(errs, errors.New("AUTH_SERVICE_ADDR, INTEGRATION_SERVICE_ADDR and WORKFLOW_SERVICE_ADDR are required"))
+	}
+	if (c.TLSCertFile == "") != (c.TLSKeyFile == "") {
+		errs = append(errs, errors.New("GRPC_TLS_CERT_FILE and GRPC_TLS_KEY_FILE must be set together"))
+	}
+	if c.TLSCertFile != "" && c.TLSCAFile == "" {
+		errs = append(errs, errors.New("GRPC_TLS_CERT_FILE requires GRPC_TLS_CA_FILE"))
+	}
+	switch c.StorageDriver {
+	case "s3":
+		if c.AWSRegion == "" {
+			errs = append(errs, errors.New("AWS_REGION is required when STORAGE_DRIVER is s3"))
+		}
+		if (c.AWSAccessKeyID == "") != (c.AWSSecretAccessKey == "") {
+			errs = append(errs, errors.New("AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY must be set together"))
+		}
+		if c.S3Endpoint != "" {
+			if u, err := url.Parse(c.S3Endpoint); err != nil || u.Scheme == "" || u.Host == "" {
+				errs = append(errs, fmt.Errorf("S3_ENDPOINT must be an absolute URL, got %q", c.S3Endpoint))
+			}
+		}
+	case "local", "memory":
+		if c.StorageDriver == "local" && c.StorageLocalDir == "" {
+			errs = append(errs, errors.New("STORAGE_LOCAL_DIR is required when STORAGE_DRIVER is local"))
+		}
+		// Every instance has to verify the URLs the others signed, after restarts too
+		if len(c.StorageSigningKey) < 32 {
+			errs = append(errs, fmt.Errorf("STORAGE_SIGNING_KEY of at least 32 characters is required when STORAGE_DRIVER is %s", c.StorageDriver))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("STORAGE_DRIVER must be s3, local or memory, got %q", c.StorageDriver))
+	}
+	if _, err := c.StorageNamespaceMap(); err != nil {
+		errs = append(errs, err)
+	}
+	if _, err := c.RegistrySinceTime(); err != nil {
+		errs = append(errs, err)
+	}
+	// S3 takes at most 5GiB in one PUT and presigns URLs for at most 7 days
+	if c.UploadMaxSize < 1 || c.UploadMaxSize > 5<<30 {
+		errs = append(errs, fmt.Errorf("UPLOAD_MAX_SIZE must be between 1 and 5GiB, got %d", c.UploadMaxSize))
+	}
+	if c.UploadURLExpiry <= 0 || c.UploadURLExpiry > 7*24*time.Hour {
+		errs = append(errs, fmt.Errorf("UPLOAD_URL_EXPIRY must be positive and at most 7 days, got %s", c.UploadURLExpiry))
+	}
+	if c.DownloadURLExpiry <= 0 || c.DownloadURLExpiry > 7*24*time.Hour {
+		errs = append(errs, fmt.Errorf("DOWNLOAD_URL_EXPIRY must be positive and at most 7 days, got %s", c.DownloadURLExpiry))
+	}
+	// S3 parts are 5MiB to 5GiB, at most 10000 of them
+	if c.ResumablePartSize < 5<<20 || c.ResumablePartSize > 5<<30 {
+		errs = append(errs, fmt.Errorf("RESUMABLE_UPLOAD_PART_SIZE must be between 5MiB and 5GiB, got %d", c.ResumablePartSize))
+	}
+	if c.ResumableMaxSize < 1 || c.ResumableMaxSize > 10000*c.ResumablePartSize {
+		errs = append(errs, fmt.Errorf("RESUMABLE_UPLOAD_MAX_SIZE must be between 1 and 10000 parts, got %d", c.ResumableMaxSize))
+	}
+	if c.ResumableExpiry <= 0 {
+		errs = append(errs, fmt.Errorf("RESUMABLE_UPLOAD_EXPIRY must be positive, got %s", c.ResumableExpiry))
+	}
+	if c.ResumableSweep <= 0 {
+		errs = append(errs, fmt.Errorf("RESUMABLE_UPLOAD_SWEEP_INTERVAL must be positive, got %s", c.ResumableSweep))
+	}
+	// gRPC allows at most 5 attempts and pings no more often than every 10s
+	if c.GRPCRetryMaxAttempts < 1 || c.GRPCRetryMaxAttempts > 5 {
+		errs = append(errs, fmt.Errorf("GRPC_RETRY_MAX_ATTEMPTS must be between 1 and 5, got %d", c.GRPCRetryMaxAttempts))
+	}
+	if c.GRPCKeepaliveTime < 10*time.Second {
+		errs = append(errs, fmt.Errorf("GRPC_KEEPALIVE_TIME must be at least 10s, got %s", c.GRPCKeepaliveTime))
+	}
+	if c.GRPCKeepaliveTimeout <= 0 {
+		errs = append(errs, fmt.Errorf("GRPC_KEEPALIVE_TIMEOUT must be positive, got %s", c.GRPCKeepaliveTimeout))
+	}
+	if c.GRPCBreakerFailures < 1 {
+		errs = append(errs, fmt.Errorf("GRPC_BREAKER_FAILURES must be at least 1, got %d", c.GRPCBreakerFailures))
+	}
+	if c.GRPCBreakerCooldown <= 0 {
+		errs = append(errs, fmt.Errorf("GRPC_BREAKER_COOLDOWN must be positive, got %s", c.GRPCBreakerCooldown))
+	}
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(c.LogLevel)); err != nil {
+		errs = append(errs, fmt.Errorf("LOG_LEVEL must be debug, info, warn or error, got %q", c.LogLevel))
+	}
+	if c.LogFormat != "json" && c.LogFormat != "text" {
+		errs = append(errs, fmt.Errorf("LOG_FORMAT must be json or text, got %q", c.LogFormat))
+	}
+	switch c.TracingExporter {
+	case "none", "stdout", "otlp":
+	default:
+		errs = append(errs, fmt.Errorf("TRACING_EXPORTER must be none, stdout or otlp, got %q", c.TracingExporter))
+	}
+	if _, err := platform.TracingSampleRatio(c.TracingSampler); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// RouteTimeoutMap parses RouteTimeouts into deadlines keyed by "METHOD /path".
+func (c *Config) RouteTimeoutMap() (map[string]time.Duration, error) {
+	timeouts := make(map[string]time.Duration, len(c.RouteTimeouts))
+	for _, entry := range c.RouteTimeouts {
+		route, value, ok := strings.Cut(entry, "=")
+		method, path, hasPath := strings.Cut(strings.TrimSpace(route), " ")
+		if !ok || !hasPath || !strings.HasPrefix(path, "/") {
+			return nil, fmt.Errorf("ROUTE_TIMEOUTS entry %q must look like METHOD /path=duration", entry)
+		}
+		timeout, err := time.ParseDuration(strings.TrimSpace(value))
+		if err != nil || timeout <= 0 {
+			return nil, fmt.Errorf("ROUTE_TIMEOUTS entry %q has an invalid duration", entry)
+		}
+		timeouts[strings.ToUpper(method)+" "+path] = timeout
+	}
+	return timeouts, nil
+}
+
+// RateLimit allows Limit requests per Window.
+type RateLimit struct {
+	Limit  int
+	Window time.Duration
+}
+
+// RateLimitMap parses RateLimits into limits keyed by route group.
+func (c *Config) RateLimitMap() (map[string]RateLimit, error) {
+	limits := make(map[string]RateLimit, len(c.RateLimits))
+	for _, entry := range c.RateLimits {
+		group, value, ok := strings.Cut(entry, "=")
+		count, window, hasWindow := strings.Cut(value, "/")
+		group = strings.TrimSpace(group)
+		if !ok || !hasWindow || group == "" {
+			return nil, fmt.Errorf("RATE_LIMITS entry %q must look like group=count/window", entry)
+		}
+		limit, err := strconv.Atoi(strings.TrimSpace(count))
+		if err != nil || limit < 1 {
+			return nil, fmt.Errorf("RATE_LIMITS entry %q has an invalid count", entry)
+		}
+		duration, err := time.ParseDuration(strings.TrimSpace(window))
+		if err != nil || duration < time.Millisecond*time.Duration(limit) {
+			return nil, fmt.Errorf("RATE_LIMITS entry %q has an invalid window, it must be at least 1ms per request", entry)
+		}
+		limits[group] = RateLimit{Limit: limit, Window: duration}
+	}
+	return limits, nil
+}
+
+// StorageNamespaceMap parses StorageNamespaces into buckets keyed by namespace.
+func (c *Config) StorageNamespaceMap() (map[string]string, error) {
+	buckets := make(map[string]string, len(c.StorageNamespaces))
+	for _, entry := range c.StorageNamespaces {
+		namespace, bucket, ok := strings.Cut(entry, "=")
+		namespace, bucket = strings.TrimSpace(namespace), strings.TrimSpace(bucket)
+		if !ok || namespace == "" || bucket == "" {
+			return nil, fmt.Errorf("STORAGE_NAMESPACES entry %q must look like namespace=bucket", entry)
+		}
+		buckets[namespace] = bucket
+	}
+	return buckets, nil
+}
+
+// AdminAddr is the address the admin server listens on.
+func (c *Config) AdminAddr() string {
+	return fmt.Sprintf(":%d", c.AdminPort)
+}
+
+// ListenAddr is the address the HTTP server listens on.
+func (c *Config) ListenAddr() string {
+	return fmt.Sprintf(":%d", c.Port)
+}
+
+// RegistrySinceTime parses RegistrySince, the zero time when it's unset.
+func (c *Config) RegistrySinceTime() (time.Time, error) {
+	if c.RegistrySince == "" {
+		return time.Time{}, nil
+	}
+	since, err := time.Parse(time.RFC3339, c.RegistrySince)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("STORAGE_REGISTRY_SINCE must be an RFC 3339 time, got %q", c.RegistrySince)
+	}
+	return since, nil
+}
@@ -3,31 +3,29 @@ package authcontrollers
 import (
 	auth_service "api-gateway/proto/generated/github.com/multiagentai/backend/auth-service"
 	"api-gateway/server"
-	"context"
+	"api-gateway/utils"
+	"net/http"
 
 	"github.com/gin-gonic/gin"
-	"google.golang.org/grpc/metadata"
 )
 
 func DeleteUser(c *gin.Context) {
 	// Extract userID from context
-	userID, exists := c.Get("userID")
-	if !exists {
-		c.JSON(401, gin.H{"error": "Unauthorized: userID not found in context"})
+	if _, exists := c.Get("userID"); !exists {
+		utils.RespondWithError(c, http.StatusUnauthorized, utils.CodeUnauthenticated, "userID not found in context")
 		return
 	}
 	// Retrieve the server instance from context
 	s, _ := c.Get("server")
 	serverInstance := s.(*server.Server)
 
-	// Create a context with metadata containing the userID
-	md := metadata.New(map[string]string{"userID": userID.(string)})
-	ctx := metadata.NewOutgoingContext(context.Background(), md)
+	// Make the call on behalf of the signed in user
+	ctx := utils.OutgoingContext(c)
 
 	// Make the gRPC call with the modified context
 	res, err := serverInstance.AuthService.DeleteUser(ctx, &auth_service.DeleteUserRequest{})
 	if err != nil {
-		c.JSON(500, gin.H{"error": err.Error()})
+		utils.RespondWithGRPCError(c, err)
 		return
 	}
 
@@ -0,0 +1,42 @@
+package authcontrollers
+
+import (
+	auth_service "api-gateway/proto/generated/github.com/multiagentai/backend/auth-service"
+	"api-gateway/server"
+	"api-gateway/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+func DisableMFA(c *gin.Context) {
+	// Extract userID from context
+	if _, exists := c.Get("userID"); !exists {
+		utils.RespondWithError(c, http.StatusUnauthorized, utils.CodeUnauthenticated, "userID not found in context")
+		return
+	}
+	//bind body
+	var req auth_service.DisableMFARequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, utils.CodeInvalidArgument, err.Error())
+		return
+	}
+	// Retrieve the server instance from context
+	s, _ := c.Get("server")
+	serverInstance := s.(*server.Server)
+
+	// Make the call on behalf of the signed in user
+	ctx := utils.OutgoingContext(c)
+
+	// Make the gRPC call with the modified context
+	res, err := serverInstance.AuthService.DisableMFA(ctx, &req)
+	if err != nil {
+		utils.RespondWithGRPCError(c, err)
+		return
+	}
+
+	// Return the response
+	c.JSON(200, gin.H{
+		"response": res,
+	})
+}
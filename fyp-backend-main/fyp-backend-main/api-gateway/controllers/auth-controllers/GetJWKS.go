@@ -0,0 +1,15 @@
+package authcontrollers
+
+import (
+	"api-gateway/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetJWKS publishes the keys access tokens are signed with so other services can verify them.
+func GetJWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(200, gin.H{
+		"keys": utils.JWKS.Document(),
+	})
+}
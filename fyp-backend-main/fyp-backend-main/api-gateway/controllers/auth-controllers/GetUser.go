@@ -3,17 +3,16 @@ package authcontrollers
 import (
 	auth_service "api-gateway/proto/generated/github.com/multiagentai/backend/auth-service"
 	"api-gateway/server"
-	"context"
+	"api-gateway/utils"
+	"net/http"
 
 	"github.com/gin-gonic/gin"
-	"google.golang.org/grpc/metadata"
 )
 
 func GetUser(c *gin.Context) {
 	// Extract userID from context
-	userID, exists := c.Get("userID")
-	if !exists {
-		c.JSON(401, gin.H{"error": "Unauthorized: userID not found in context"})
+	if _, exists := c.Get("userID"); !exists {
+		utils.RespondWithError(c, http.StatusUnauthorized, utils.CodeUnauthenticated, "userID not found in context")
 		return
 	}
 
@@ -21,14 +20,13 @@ func GetUser(c *gin.Context) {
 	s, _ := c.Get("server")
 	serverInstance := s.(*server.Server)
 
-	// Create a context with metadata containing the userID
-	md := metadata.New(map[string]string{"userID": userID.(string)})
-	ctx := metadata.NewOutgoingContext(context.Background(), md)
+	// Make the call on behalf of the signed in user
+	ctx := utils.OutgoingContext(c)
 
 	// Make the gRPC call with the modified context
 	res, err := serverInstance.AuthService.GetUser(ctx, &auth_service.GetUserRequest{})
 	if err != nil {
-		c.JSON(500, gin.H{"error": err.Error()})
+		utils.RespondWithGRPCError(c, err)
 		return
 	}
 
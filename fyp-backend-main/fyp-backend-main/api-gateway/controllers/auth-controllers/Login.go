@@ -3,6 +3,8 @@ package authcontrollers
 import (
 	auth_service "api-gateway/proto/generated/github.com/multiagentai/backend/auth-service"
 	"api-gateway/server"
+	"api-gateway/utils"
+	"net/http"
 
 	"github.com/gin-gonic/gin"
 )
@@ -11,15 +13,18 @@ func Login(c *gin.Context) {
 	//bind request
 	var req auth_service.LoginRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(400, gin.H{"error": err.Error()})
+		utils.RespondWithError(c, http.StatusBadRequest, utils.CodeInvalidArgument, err.Error())
 		return
 	}
 	//validate request
 	s, _ := c.Get("server")
 	serverInstance := s.(*server.Server)
-	res, err := serverInstance.AuthService.Login(c, &req)
+	// The signed assertion carries the client IP, used to throttle failed logins
+	ctx := utils.OutgoingContext(c)
+
+	res, err := serverInstance.AuthService.Login(ctx, &req)
 	if err != nil {
-		c.JSON(500, gin.H{"error": err.Error()})
+		utils.RespondWithGRPCError(c, err)
 		return
 	}
 	// You can now use serverInstance here
@@ -0,0 +1,45 @@
+package authcontrollers
+
+import (
+	auth_service "api-gateway/proto/generated/github.com/multiagentai/backend/auth-service"
+	"api-gateway/server"
+	"api-gateway/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+func Logout(c *gin.Context) {
+	// Extract userID from context
+	if _, exists := c.Get("userID"); !exists {
+		utils.RespondWithError(c, http.StatusUnauthorized, utils.CodeUnauthenticated, "userID not found in context")
+		return
+	}
+
+	// The refresh token is optional, the session is also found through the access token
+	var req auth_service.LogoutRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.RespondWithError(c, http.StatusBadRequest, utils.CodeInvalidArgument, err.Error())
+			return
+		}
+	}
+	req.AccessToken = c.GetString("accessToken")
+
+	// Retrieve the server instance from context
+	s, _ := c.Get("server")
+	serverInstance := s.(*server.Server)
+
+	// Make the call on behalf of the signed in user
+	ctx := utils.OutgoingContext(c)
+
+	_, err := serverInstance.AuthService.Logout(ctx, &req)
+	if err != nil {
+		utils.RespondWithGRPCError(c, err)
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"message": "Logged out successfully",
+	})
+}
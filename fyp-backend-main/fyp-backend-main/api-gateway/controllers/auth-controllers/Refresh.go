@@ -0,0 +1,31 @@
+package authcontrollers
+
+import (
+	auth_service "api-gateway/proto/generated/github.com/multiagentai/backend/auth-service"
+	"api-gateway/server"
+	"api-gateway/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+func Refresh(c *gin.Context) {
+	//bind request
+	var req auth_service.RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, utils.CodeInvalidArgument, err.Error())
+		return
+	}
+
+	s, _ := c.Get("server")
+	serverInstance := s.(*server.Server)
+	res, err := serverInstance.AuthService.Refresh(utils.OutgoingContext(c), &req)
+	if err != nil {
+		utils.RespondWithGRPCError(c, err)
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"response": res,
+	})
+}
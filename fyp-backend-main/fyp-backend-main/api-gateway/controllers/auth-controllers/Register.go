@@ -3,6 +3,8 @@ package authcontrollers
 import (
 	auth_service "api-gateway/proto/generated/github.com/multiagentai/backend/auth-service"
 	"api-gateway/server"
+	"api-gateway/utils"
+	"net/http"
 
 	"github.com/gin-gonic/gin"
 )
@@ -11,15 +13,15 @@ func Register(c *gin.Context) {
 	//bind request
 	var req auth_service.RegisterRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(400, gin.H{"error": err.Error()})
+		utils.RespondWithError(c, http.StatusBadRequest, utils.CodeInvalidArgument, err.Error())
 		return
 	}
 	//validate request
 	s, _ := c.Get("server")
 	serverInstance := s.(*server.Server)
-	res, err := serverInstance.AuthService.Register(c, &req)
+	res, err := serverInstance.AuthService.Register(utils.OutgoingContext(c), &req)
 	if err != nil {
-		c.JSON(500, gin.H{"error": err.Error()})
+		utils.RespondWithGRPCError(c, err)
 		return
 	}
 	// You can now use serverInstance here
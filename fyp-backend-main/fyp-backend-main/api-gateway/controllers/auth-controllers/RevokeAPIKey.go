@@ -0,0 +1,49 @@
+package authcontrollers
+
+import (
+	auth_service "api-gateway/proto/generated/github.com/multiagentai/backend/auth-service"
+	"api-gateway/server"
+	"api-gateway/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RevokeAPIKey(c *gin.Context) {
+	// Extract userID from context
+	if _, exists := c.Get("userID"); !exists {
+		utils.RespondWithError(c, http.StatusUnauthorized, utils.CodeUnauthenticated, "userID not found in context")
+		return
+	}
+
+	// Extract the ID from the route parameter
+	id := c.Param("id")
+	if id == "" {
+		utils.RespondWithError(c, http.StatusBadRequest, utils.CodeInvalidArgument, "Missing required field: id in route parameter")
+		return
+	}
+
+	// Retrieve the server instance from context
+	s, _ := c.Get("server")
+	serverInstance := s.(*server.Server)
+
+	// Make the call on behalf of the signed in user
+	ctx := utils.OutgoingContext(c)
+
+	// Make the gRPC call to revoke the key
+	_, err := serverInstance.AuthService.RevokeAPIKey(ctx, &auth_service.RevokeAPIKeyRequest{Id: id})
+	if err != nil {
+		utils.RespondWithGRPCError(c, err)
+		return
+	}
+
+	// Stop accepting the key right away instead of when the cache expires, at every instance
+	if utils.APIKeys != nil {
+		utils.APIKeys.Forget(c.Request.Context(), id)
+	}
+
+	// Return the response
+	c.JSON(200, gin.H{
+		"success": true,
+	})
+}
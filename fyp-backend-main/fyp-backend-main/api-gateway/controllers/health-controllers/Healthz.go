@@ -0,0 +1,12 @@
+package healthcontrollers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Healthz is the liveness probe, it only reports that the process is serving requests.
+func Healthz(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
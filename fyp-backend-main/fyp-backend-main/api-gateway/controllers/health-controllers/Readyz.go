@@ -0,0 +1,101 @@
+package healthcontrollers
+
+import (
+	"api-gateway/server"
+	"api-gateway/utils"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// readinessTimeout bounds each dependency check
+const readinessTimeout = 2 * time.Second
+
+// DependencyStatus is the result of checking one dependency. Why a check failed is
+// only logged, the probe is unauthenticated.
+type DependencyStatus struct {
+	Status    string `json:"status"`
+	LatencyMs int64  `json:"latencyMs"`
+}
+
+// Readyz is the readiness probe. It checks the backend services over the gRPC
+// health protocol, Redis and the file storage in parallel and responds with 503 if any is down.
+func Readyz(c *gin.Context) {
+	// Retrieve the server instance from context
+	s, _ := c.Get("server")
+	serverInstance := s.(*server.Server)
+
+	checks := map[string]func(ctx context.Context) error{
+		"auth-service":        grpcHealthCheck(serverInstance.AuthHealth),
+		"integration-service": grpcHealthCheck(serverInstance.IntegrationHealth),
+		"workflow-service":    grpcHealthCheck(serverInstance.WorkflowHealth),
+		"redis": func(ctx context.Context) error {
+			if utils.RDB == nil {
+				return errors.New("not connected")
+			}
+			return utils.RDB.Ping(ctx).Err()
+		},
+		"storage": func(ctx context.Context) error {
+			if serverInstance.Storage == nil {
+				return errors.New("not initialized")
+			}
+			return serverInstance.Storage.Ping(ctx)
+		},
+	}
+
+	// Run the checks in parallel
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	dependencies := make(map[string]DependencyStatus, len(checks))
+	ready := true
+	for name, check := range checks {
+		wg.Add(1)
+		go func(name string, check func(ctx context.Context) error) {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(c.Request.Context(), readinessTimeout)
+			defer cancel()
+
+			start := time.Now()
+			err := check(ctx)
+			result := DependencyStatus{Status: "up", LatencyMs: time.Since(start).Milliseconds()}
+			if err != nil {
+				result.Status = "down"
+				slog.WarnContext(c.Request.Context(), "Readiness check failed", "dependency", name, "error", err)
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			dependencies[name] = result
+			if err != nil {
+				ready = false
+			}
+		}(name, check)
+	}
+	wg.Wait()
+
+	if !ready {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": "not ready", "dependencies": dependencies})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "ready", "dependencies": dependencies})
+}
+
+// grpcHealthCheck asks a backend's health service for the status of the whole server
+func grpcHealthCheck(client healthpb.HealthClient) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		res, err := client.Check(ctx, &healthpb.HealthCheckRequest{})
+		if err != nil {
+			return err
+		}
+		if res.Status != healthpb.HealthCheckResponse_SERVING {
+			return errors.New(res.Status.String())
+		}
+		return nil
+	}
+}
@@ -3,37 +3,42 @@ package integrationcontrollers
 import (
 	integration_service "api-gateway/proto/generated/github.com/multiagentai/backend/integration-service"
 	"api-gateway/server"
-	"context"
+	"api-gateway/storage"
+	"api-gateway/utils"
+	"net/http"
 
 	"github.com/gin-gonic/gin"
-	"google.golang.org/grpc/metadata"
 )
 
 func CreateIntegration(c *gin.Context) {
 	// Extract userID from context
-	userID, exists := c.Get("userID")
-	if !exists {
-		c.JSON(401, gin.H{"error": "Unauthorized: userID not found in context"})
+	if _, exists := c.Get("userID"); !exists {
+		utils.RespondWithError(c, http.StatusUnauthorized, utils.CodeUnauthenticated, "userID not found in context")
 		return
 	}
 	//bind body
 	var req integration_service.CreateIntegrationRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(400, gin.H{"error": err.Error()})
+		utils.RespondWithError(c, http.StatusBadRequest, utils.CodeInvalidArgument, err.Error())
 		return
 	}
 	// Retrieve the server instance from context
 	s, _ := c.Get("server")
 	serverInstance := s.(*server.Server)
 
-	// Create a context with metadata containing the userID
-	md := metadata.New(map[string]string{"userID": userID.(string)})
-	ctx := metadata.NewOutgoingContext(context.Background(), md)
+	// Make the call on behalf of the signed in user
+	ctx := utils.OutgoingContext(c)
+
+	// Attaching a file makes it readable, so only its uploader can
+	if err := serverInstance.CheckAttachment(ctx, storage.NamespaceIntegrations, c.GetString("userID"), req.DocumentationUrl); err != nil {
+		utils.RespondWithGRPCError(c, err)
+		return
+	}
 
 	// Make the gRPC call with the modified context
 	res, err := serverInstance.IntegrationService.CreateIntegration(ctx, &req)
 	if err != nil {
-		c.JSON(500, gin.H{"error": err.Error()})
+		utils.RespondWithGRPCError(c, err)
 		return
 	}
 
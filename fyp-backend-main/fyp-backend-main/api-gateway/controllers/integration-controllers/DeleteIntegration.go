@@ -3,24 +3,23 @@ package integrationcontrollers
 import (
 	integration_service "api-gateway/proto/generated/github.com/multiagentai/backend/integration-service"
 	"api-gateway/server"
-	"context"
+	"api-gateway/utils"
+	"net/http"
 
 	"github.com/gin-gonic/gin"
-	"google.golang.org/grpc/metadata"
 )
 
 func DeleteIntegration(c *gin.Context) {
 	// Extract userID from context
-	userID, exists := c.Get("userID")
-	if !exists {
-		c.JSON(401, gin.H{"error": "Unauthorized: userID not found in context"})
+	if _, exists := c.Get("userID"); !exists {
+		utils.RespondWithError(c, http.StatusUnauthorized, utils.CodeUnauthenticated, "userID not found in context")
 		return
 	}
 
 	// Extract the ID from the route parameter
 	id := c.Param("id")
 	if id == "" {
-		c.JSON(400, gin.H{"error": "Missing required field: id in route parameter"})
+		utils.RespondWithError(c, http.StatusBadRequest, utils.CodeInvalidArgument, "Missing required field: id in route parameter")
 		return
 	}
 
@@ -33,14 +32,13 @@ func DeleteIntegration(c *gin.Context) {
 		Id: id,
 	}
 
-	// Create a context with metadata containing the userID
-	md := metadata.New(map[string]string{"userID": userID.(string)})
-	ctx := metadata.NewOutgoingContext(context.Background(), md)
+	// Make the call on behalf of the signed in user
+	ctx := utils.OutgoingContext(c)
 
 	// Make the gRPC call to delete the integration
 	res, err := serverInstance.IntegrationService.DeleteIntegration(ctx, req)
 	if err != nil {
-		c.JSON(500, gin.H{"error": err.Error()})
+		utils.RespondWithGRPCError(c, err)
 		return
 	}
 
@@ -3,7 +3,8 @@ package integrationcontrollers
 import (
 	integration_service "api-gateway/proto/generated/github.com/multiagentai/backend/integration-service"
 	"api-gateway/server"
-	"context"
+	"api-gateway/utils"
+	"net/http"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
@@ -14,19 +15,19 @@ func GetPaginatedCommunityIntegrations(c *gin.Context) {
 	limit := c.Query("limit")
 
 	if offset == "" || limit == "" {
-		c.JSON(400, gin.H{"error": "Offset and limit are required"})
+		utils.RespondWithError(c, http.StatusBadRequest, utils.CodeInvalidArgument, "Offset and limit are required")
 		return
 	}
 
 	offsetInt, err := strconv.Atoi(offset)
 	if err != nil {
-		c.JSON(400, gin.H{"error": "Invalid offset"})
+		utils.RespondWithError(c, http.StatusBadRequest, utils.CodeInvalidArgument, "Invalid offset")
 		return
 	}
 
 	limitInt, err := strconv.Atoi(limit)
 	if err != nil {
-		c.JSON(400, gin.H{"error": "Invalid limit"})
+		utils.RespondWithError(c, http.StatusBadRequest, utils.CodeInvalidArgument, "Invalid limit")
 		return
 	}
 
@@ -41,9 +42,9 @@ func GetPaginatedCommunityIntegrations(c *gin.Context) {
 	}
 
 	// Make the gRPC call to delete the integration
-	res, err := serverInstance.IntegrationService.GetPaginatedCommunityIntegrations(context.Background(), req)
+	res, err := serverInstance.IntegrationService.GetPaginatedCommunityIntegrations(utils.OutgoingContext(c), req)
 	if err != nil {
-		c.JSON(500, gin.H{"error": err.Error()})
+		utils.RespondWithGRPCError(c, err)
 		return
 	}
 	c.JSON(200, res)
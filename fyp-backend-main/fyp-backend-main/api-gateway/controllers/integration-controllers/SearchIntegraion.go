@@ -3,38 +3,78 @@ package integrationcontrollers
 import (
 	integration_service "api-gateway/proto/generated/github.com/multiagentai/backend/integration-service"
 	"api-gateway/server"
-	"context"
+	"api-gateway/utils"
+	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 )
 
 func SearchIntegration(c *gin.Context) {
+	// Extract userID from context
+	if _, exists := c.Get("userID"); !exists {
+		utils.RespondWithError(c, http.StatusUnauthorized, utils.CodeUnauthenticated, "userID not found in context")
+		return
+	}
+
 	// Parse query parameter
 	query := c.Query("query")
 	if query == "" {
-		c.JSON(400, gin.H{"error": "Query parameter 'query' is required"})
+		utils.RespondWithError(c, http.StatusBadRequest, utils.CodeInvalidArgument, "Query parameter 'query' is required")
 		return
 	}
 
+	// Create the gRPC request
+	req := &integration_service.SearchIntegrationRequest{
+		Query:      query,
+		Cursor:     c.Query("cursor"),
+		FileStatus: c.Query("status"),
+	}
+
+	// Paging is optional, the integration service applies defaults
+	if offset := c.Query("offset"); offset != "" {
+		offsetInt, err := strconv.Atoi(offset)
+		if err != nil {
+			utils.RespondWithError(c, http.StatusBadRequest, utils.CodeInvalidArgument, "Invalid offset")
+			return
+		}
+		req.Offset = int32(offsetInt)
+	}
+	if limit := c.Query("limit"); limit != "" {
+		limitInt, err := strconv.Atoi(limit)
+		if err != nil {
+			utils.RespondWithError(c, http.StatusBadRequest, utils.CodeInvalidArgument, "Invalid limit")
+			return
+		}
+		req.Limit = int32(limitInt)
+	}
+	if fileBased := c.Query("fileBased"); fileBased != "" {
+		fileBasedBool, err := strconv.ParseBool(fileBased)
+		if err != nil {
+			utils.RespondWithError(c, http.StatusBadRequest, utils.CodeInvalidArgument, "Invalid fileBased")
+			return
+		}
+		req.IsFileBased = &fileBasedBool
+	}
+
 	// Retrieve the server instance from context
 	s, _ := c.Get("server")
 	serverInstance := s.(*server.Server)
 
-	// Create the gRPC request
-	req := &integration_service.SearchIntegrationRequest{
-		Query: query,
-	}
+	// Make the call on behalf of the signed in user
+	ctx := utils.OutgoingContext(c)
 
 	// Make the gRPC call
-	ctx := context.Background()
 	res, err := serverInstance.IntegrationService.SearchIntegration(ctx, req)
 	if err != nil {
-		c.JSON(500, gin.H{"error": err.Error()})
+		utils.RespondWithGRPCError(c, err)
 		return
 	}
 
 	// Return the response
 	c.JSON(200, gin.H{
 		"integrations": res.Integrations,
+		"total":        res.Total,
+		"nextCursor":   res.NextCursor,
 	})
 }
@@ -3,17 +3,16 @@ package projectcontrollers
 import (
 	workflow_service "api-gateway/proto/generated/github.com/multiagentai/backend/workflow-service"
 	"api-gateway/server"
-	"context"
+	"api-gateway/utils"
+	"net/http"
 
 	"github.com/gin-gonic/gin"
-	"google.golang.org/grpc/metadata"
 )
 
 func GetUserProjects(c *gin.Context) {
 	// Extract userID from context
-	userID, exists := c.Get("userID")
-	if !exists {
-		c.JSON(401, gin.H{"error": "Unauthorized: userID not found in context"})
+	if _, exists := c.Get("userID"); !exists {
+		utils.RespondWithError(c, http.StatusUnauthorized, utils.CodeUnauthenticated, "userID not found in context")
 		return
 	}
 
@@ -21,9 +20,8 @@ func GetUserProjects(c *gin.Context) {
 	s, _ := c.Get("server")
 	serverInstance := s.(*server.Server)
 
-	// Create a context with metadata containing the userID
-	md := metadata.New(map[string]string{"userID": userID.(string)})
-	ctx := metadata.NewOutgoingContext(context.Background(), md)
+	// Make the call on behalf of the signed in user
+	ctx := utils.OutgoingContext(c)
 
 	// Create the gRPC request
 	req := &workflow_service.GetProjectsRequest{}
@@ -31,7 +29,7 @@ func GetUserProjects(c *gin.Context) {
 	// Make the gRPC call
 	res, err := serverInstance.WorkflowService.GetProjects(ctx, req)
 	if err != nil {
-		c.JSON(500, gin.H{"error": err.Error()})
+		utils.RespondWithGRPCError(c, err)
 		return
 	}
 	if res.Projects == nil {
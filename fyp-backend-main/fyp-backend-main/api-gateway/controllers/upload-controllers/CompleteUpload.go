@@ -0,0 +1,118 @@
+package uploadcontrollers
+
+import (
+	"api-gateway/server"
+	"api-gateway/storage"
+	"api-gateway/utils"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CompleteUpload verifies that the file issued by CreateUpload was uploaded with the
+// declared size, content type and checksum, records it and returns its URL. Completing
+// an upload again returns the same URL, so clients can retry.
+func CompleteUpload(c *gin.Context) {
+	// Extract userID from context
+	userID := c.GetString("userID")
+	if userID == "" {
+		utils.RespondWithError(c, http.StatusUnauthorized, utils.CodeUnauthenticated, "userID not found in context")
+		return
+	}
+
+	// Retrieve the server instance from context
+	s, _ := c.Get("server")
+	serverInstance := s.(*server.Server)
+	name := c.Param("bucket")
+	fileName := c.Param("file")
+	namespace, bucketName, ok := serverInstance.Namespaces.Resolve(name)
+	if !ok {
+		utils.RespondWithError(c, http.StatusNotFound, utils.CodeNotFound, fmt.Sprintf("Namespace '%s' not found", name))
+		return
+	}
+	notFound := fmt.Sprintf("Upload '%s' not found in namespace '%s'", fileName, namespace)
+
+	// Only the user the upload was issued to can complete it
+	upload, err := serverInstance.Uploads.Lookup(c.Request.Context(), bucketName, fileName)
+	if err := c.Request.Context().Err(); err != nil {
+		// The deadline passed or the client went away
+		utils.RespondWithGRPCError(c, err)
+		return
+	}
+	switch {
+	case errors.Is(err, storage.ErrNotFound), errors.Is(err, storage.ErrBucketNotFound), errors.Is(err, storage.ErrInvalidKey):
+		utils.RespondWithError(c, http.StatusNotFound, utils.CodeNotFound, notFound)
+		return
+	case err != nil:
+		utils.RespondWithGRPCError(c, err)
+		return
+	}
+	if upload.Owner != userID {
+		utils.RespondWithError(c, http.StatusNotFound, utils.CodeNotFound, notFound)
+		return
+	}
+	if upload.Status == storage.UploadPending {
+		if upload.ExpiresAt != nil && time.Now().After(*upload.ExpiresAt) {
+			abandonUpload(c.Request.Context(), serverInstance, upload)
+			utils.RespondWithError(c, http.StatusGone, utils.CodeFailedPrecondition, "The upload URL expired, request a new one")
+			return
+		}
+
+		// Check the file against the upload it was issued for
+		info, err := serverInstance.Storage.Head(c.Request.Context(), bucketName, fileName)
+		if err := c.Request.Context().Err(); err != nil {
+			utils.RespondWithGRPCError(c, err)
+			return
+		}
+		if errors.Is(err, storage.ErrNotFound) {
+			utils.RespondWithError(c, http.StatusBadRequest, utils.CodeFailedPrecondition, "The file hasn't been uploaded yet")
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to check upload", "bucket", bucketName, "key", fileName, "error", err)
+			utils.RespondWithError(c, http.StatusInternalServerError, utils.CodeInternal, "Failed to complete upload")
+			return
+		}
+		if info.Size != upload.Size || info.ContentType != upload.ContentType || info.ChecksumSHA256 != upload.ChecksumSHA256 {
+			// Remove it so the URL can be used again until it expires
+			if err := serverInstance.Storage.Delete(c.Request.Context(), bucketName, fileName); err != nil {
+				slog.WarnContext(c.Request.Context(), "Failed to delete mismatched upload", "bucket", bucketName, "key", fileName, "error", err)
+			}
+			utils.RespondWithError(c, http.StatusBadRequest, utils.CodeFailedPrecondition, "The uploaded file doesn't match the size, content type or checksum it was declared with")
+			return
+		}
+
+		upload.ETag = info.ETag
+		upload.Status = storage.UploadComplete
+		upload.ExpiresAt = nil
+		if err := serverInstance.Uploads.Record(c.Request.Context(), *upload); err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to record upload", "bucket", bucketName, "key", fileName, "error", err)
+			utils.RespondWithError(c, http.StatusInternalServerError, utils.CodeInternal, "Failed to complete upload")
+			return
+		}
+	}
+
+	// Return the file URL, as UploadFile does
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "File uploaded successfully",
+		"file_url": utils.FileURL(namespace, fileName),
+		"file":     upload,
+	})
+}
+
+// abandonUpload removes an upload that wasn't completed in time and its record
+func abandonUpload(ctx context.Context, serverInstance *server.Server, upload *storage.Upload) {
+	ctx = context.WithoutCancel(ctx)
+	if err := serverInstance.Storage.Delete(ctx, upload.Bucket, upload.Key); err != nil {
+		slog.WarnContext(ctx, "Failed to delete abandoned upload", "bucket", upload.Bucket, "key", upload.Key, "error", err)
+		return
+	}
+	if err := serverInstance.Uploads.Delete(ctx, upload.Bucket, upload.Key); err != nil {
+		slog.WarnContext(ctx, "Failed to delete abandoned upload record", "bucket", upload.Bucket, "key", upload.Key, "error", err)
+	}
+}
@@ -0,0 +1,129 @@
+package uploadcontrollers
+
+import (
+	"api-gateway/config"
+	"api-gateway/server"
+	"api-gateway/storage"
+	"api-gateway/utils"
+	"errors"
+	"fmt"
+	"log/slog"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// CreateResumableUpload starts a tus upload of Upload-Length bytes and returns its URL in
+// Location. Upload-Metadata carries the filename, its filetype and the visibility of the
+// file, as UploadFile takes them. A body sent with the request is written right away.
+func CreateResumableUpload(c *gin.Context) {
+	// Extract userID from context
+	userID := c.GetString("userID")
+	if userID == "" {
+		utils.RespondWithError(c, http.StatusUnauthorized, utils.CodeUnauthenticated, "userID not found in context")
+		return
+	}
+	if !checkTusResumable(c) {
+		return
+	}
+
+	// Read the length and metadata of the upload
+	if c.GetHeader("Upload-Defer-Length") != "" {
+		utils.RespondWithError(c, http.StatusBadRequest, utils.CodeInvalidArgument, "Upload-Defer-Length isn't supported, send Upload-Length")
+		return
+	}
+	length, ok := parseUploadOffset(c.GetHeader("Upload-Length"))
+	if !ok {
+		utils.RespondWithError(c, http.StatusBadRequest, utils.CodeInvalidArgument, "Upload-Length must be a non-negative integer")
+		return
+	}
+	if length > int64(config.Current.ResumableMaxSize) {
+		utils.RespondWithError(c, http.StatusRequestEntityTooLarge, utils.CodeInvalidArgument, fmt.Sprintf("Files can be at most %d bytes", config.Current.ResumableMaxSize))
+		return
+	}
+	metadataHeader := c.GetHeader("Upload-Metadata")
+	metadata, err := parseTusMetadata(metadataHeader)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, utils.CodeInvalidArgument, err.Error())
+		return
+	}
+	// Keep the base name only, as multipart uploads do
+	originalFilename := path.Base(strings.ReplaceAll(metadata["filename"], "\\", "/"))
+	if originalFilename == "." || originalFilename == "/" {
+		utils.RespondWithError(c, http.StatusBadRequest, utils.CodeInvalidArgument, "Upload-Metadata must have a filename")
+		return
+	}
+	contentType := metadata["filetype"]
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	visibility := metadata["visibility"]
+	if visibility == "" {
+		visibility = storage.VisibilityPrivate
+	}
+	if visibility != storage.VisibilityPrivate && visibility != storage.VisibilityPublic {
+		utils.RespondWithError(c, http.StatusBadRequest, utils.CodeInvalidArgument, "Visibility must be private or public")
+		return
+	}
+
+	// Retrieve the server instance from context
+	s, _ := c.Get("server")
+	serverInstance := s.(*server.Server)
+	name := c.Param("bucket")
+	namespace, bucketName, ok := serverInstance.Namespaces.Resolve(name)
+	if !ok {
+		utils.RespondWithError(c, http.StatusNotFound, utils.CodeNotFound, fmt.Sprintf("Namespace '%s' not found", name))
+		return
+	}
+
+	// Generate a unique file name, the upload is named by its UUID
+	uniqueID := uuid.New().String()
+	fileName := fmt.Sprintf("%s-%s", uniqueID, originalFilename)
+
+	upload, err := serverInstance.ResumableUploads.Create(c.Request.Context(), uniqueID, storage.Upload{
+		Namespace:        namespace,
+		Bucket:           bucketName,
+		Key:              fileName,
+		Owner:            userID,
+		OriginalFilename: originalFilename,
+		Size:             length,
+		ContentType:      contentType,
+		Visibility:       visibility,
+	}, length, metadataHeader, time.Now().UTC().Add(config.Current.ResumableExpiry))
+	if err := c.Request.Context().Err(); err != nil {
+		// The deadline passed or the client went away
+		utils.RespondWithGRPCError(c, err)
+		return
+	}
+	if errors.Is(err, storage.ErrBucketNotFound) {
+		slog.ErrorContext(c.Request.Context(), "Bucket of namespace not found", "namespace", namespace, "bucket", bucketName)
+		utils.RespondWithError(c, http.StatusNotFound, utils.CodeNotFound, fmt.Sprintf("Namespace '%s' not found", namespace))
+		return
+	}
+	if errors.Is(err, storage.ErrInvalidKey) {
+		utils.RespondWithError(c, http.StatusBadRequest, utils.CodeInvalidArgument, "Invalid file name")
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "Failed to create resumable upload", "bucket", bucketName, "key", fileName, "error", err)
+		utils.RespondWithError(c, http.StatusInternalServerError, utils.CodeInternal, "Failed to create upload")
+		return
+	}
+	c.Header("Location", fmt.Sprintf("%s/docs/%s/tus/%s", strings.TrimSuffix(config.Current.GatewayAddress, "/"), namespace, uniqueID))
+
+	// Write the first chunk sent with the request, an empty file is finished already
+	mediaType, _, _ := mime.ParseMediaType(c.ContentType())
+	if mediaType == tusContentType || length == 0 {
+		if !writeResumableUpload(c, serverInstance, upload, 0) {
+			return
+		}
+	} else {
+		setResumableUploadHeaders(c, upload)
+	}
+	c.Status(http.StatusCreated)
+}
@@ -0,0 +1,149 @@
+package uploadcontrollers
+
+import (
+	"api-gateway/config"
+	"api-gateway/server"
+	"api-gateway/storage"
+	"api-gateway/utils"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// createUploadRequest describes the file a presigned upload URL is issued for
+type createUploadRequest struct {
+	Filename    string `json:"filename" binding:"required"`
+	ContentType string `json:"content_type" binding:"required"`
+	Size        int64  `json:"size" binding:"required,gt=0"`
+	// ChecksumSHA256 is the base64 SHA-256 of the file, storage rejects other content
+	ChecksumSHA256 string `json:"checksum_sha256" binding:"required"`
+	Visibility     string `json:"visibility"`
+}
+
+// CreateUpload issues a short-lived presigned URL the client uploads the file to straight
+// to storage, instead of streaming it through the gateway. The URL only accepts the
+// declared content type, size and checksum, and the upload counts once CompleteUpload
+// has verified it.
+func CreateUpload(c *gin.Context) {
+	// Extract userID from context
+	userID := c.GetString("userID")
+	if userID == "" {
+		utils.RespondWithError(c, http.StatusUnauthorized, utils.CodeUnauthenticated, "userID not found in context")
+		return
+	}
+
+	// Bind the request body
+	var req createUploadRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, utils.CodeInvalidArgument, err.Error())
+		return
+	}
+	if req.Size > int64(config.Current.UploadMaxSize) {
+		utils.RespondWithError(c, http.StatusRequestEntityTooLarge, utils.CodeInvalidArgument, fmt.Sprintf("Files can be at most %d bytes", config.Current.UploadMaxSize))
+		return
+	}
+	if checksum, err := base64.StdEncoding.DecodeString(req.ChecksumSHA256); err != nil || len(checksum) != sha256.Size {
+		utils.RespondWithError(c, http.StatusBadRequest, utils.CodeInvalidArgument, "checksum_sha256 must be the base64 SHA-256 of the file")
+		return
+	}
+	if req.Visibility == "" {
+		req.Visibility = storage.VisibilityPrivate
+	}
+	if req.Visibility != storage.VisibilityPrivate && req.Visibility != storage.VisibilityPublic {
+		utils.RespondWithError(c, http.StatusBadRequest, utils.CodeInvalidArgument, "Visibility must be private or public")
+		return
+	}
+	// Keep the base name only, as multipart uploads do
+	originalFilename := path.Base(strings.ReplaceAll(req.Filename, "\\", "/"))
+	if originalFilename == "." || originalFilename == "/" {
+		utils.RespondWithError(c, http.StatusBadRequest, utils.CodeInvalidArgument, "Invalid file name")
+		return
+	}
+
+	// Retrieve the server instance from context
+	s, _ := c.Get("server")
+	serverInstance := s.(*server.Server)
+	name := c.Param("bucket")
+	namespace, bucketName, ok := serverInstance.Namespaces.Resolve(name)
+	if !ok {
+		utils.RespondWithError(c, http.StatusNotFound, utils.CodeNotFound, fmt.Sprintf("Namespace '%s' not found", name))
+		return
+	}
+
+	// Generate a unique file name
+	uniqueID := uuid.New().String()
+	fileName := fmt.Sprintf("%s-%s", uniqueID, originalFilename)
+
+	// Sign the upload
+	expiry := config.Current.UploadURLExpiry
+	presigned, err := serverInstance.Storage.PresignPut(c.Request.Context(), bucketName, fileName, storage.PutConditions{
+		ContentType:    req.ContentType,
+		Size:           req.Size,
+		ChecksumSHA256: req.ChecksumSHA256,
+	}, expiry)
+	if errors.Is(err, storage.ErrInvalidKey) {
+		utils.RespondWithError(c, http.StatusBadRequest, utils.CodeInvalidArgument, "Invalid file name")
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "Failed to presign upload", "bucket", bucketName, "key", fileName, "error", err)
+		utils.RespondWithError(c, http.StatusInternalServerError, utils.CodeInternal, "Failed to create upload URL")
+		return
+	}
+
+	// Record the pending upload, CompleteUpload checks the file against it
+	now := time.Now().UTC()
+	expiresAt := now.Add(expiry)
+	err = serverInstance.Uploads.Record(c.Request.Context(), storage.Upload{
+		Namespace:        namespace,
+		Bucket:           bucketName,
+		Key:              fileName,
+		Owner:            userID,
+		OriginalFilename: originalFilename,
+		Size:             req.Size,
+		ContentType:      req.ContentType,
+		ChecksumSHA256:   req.ChecksumSHA256,
+		Visibility:       req.Visibility,
+		Status:           storage.UploadPending,
+		CreatedAt:        now,
+		ExpiresAt:        &expiresAt,
+	})
+	if err := c.Request.Context().Err(); err != nil {
+		// The deadline passed or the client went away
+		utils.RespondWithGRPCError(c, err)
+		return
+	}
+	if errors.Is(err, storage.ErrBucketNotFound) {
+		slog.ErrorContext(c.Request.Context(), "Bucket of namespace not found", "namespace", namespace, "bucket", bucketName)
+		utils.RespondWithError(c, http.StatusNotFound, utils.CodeNotFound, fmt.Sprintf("Namespace '%s' not found", namespace))
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "Failed to record upload", "bucket", bucketName, "key", fileName, "error", err)
+		utils.RespondWithError(c, http.StatusInternalServerError, utils.CodeInternal, "Failed to create upload URL")
+		return
+	}
+
+	// Return the URL and the headers the PUT must send
+	headers := make(map[string]string, len(presigned.Header))
+	for header := range presigned.Header {
+		headers[header] = presigned.Header.Get(header)
+	}
+	c.JSON(http.StatusCreated, gin.H{
+		"file":         fileName,
+		"upload_url":   presigned.URL,
+		"method":       http.MethodPut,
+		"headers":      headers,
+		"expires_at":   expiresAt,
+		"complete_url": fmt.Sprintf("%s/docs/%s/uploads/%s/complete", strings.TrimSuffix(config.Current.GatewayAddress, "/"), namespace, fileName),
+	})
+}
@@ -0,0 +1,32 @@
+package uploadcontrollers
+
+import (
+	"api-gateway/server"
+	"api-gateway/storage"
+	"api-gateway/utils"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetBlob serves a presigned download URL of the local and memory storage drivers
+func GetBlob(c *gin.Context) {
+	// Retrieve the server instance from context
+	s, _ := c.Get("server")
+	serverInstance := s.(*server.Server)
+	if serverInstance.StorageURLs == nil {
+		utils.RespondWithError(c, http.StatusNotFound, utils.CodeNotFound, "Not found")
+		return
+	}
+	bucketName := c.Param("bucket")
+	key := strings.TrimPrefix(c.Param("key"), "/")
+
+	// The signature stands in for authentication
+	if err := serverInstance.StorageURLs.Verify(http.MethodGet, bucketName, key, storage.PutConditions{}, c.Request.URL.Query()); err != nil {
+		utils.RespondWithError(c, http.StatusForbidden, utils.CodePermissionDenied, err.Error())
+		return
+	}
+
+	serveObject(c, serverInstance.Storage, bucketName, key)
+}
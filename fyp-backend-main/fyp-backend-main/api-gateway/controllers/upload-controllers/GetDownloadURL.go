@@ -0,0 +1,71 @@
+package uploadcontrollers
+
+import (
+	"api-gateway/config"
+	"api-gateway/server"
+	"api-gateway/storage"
+	"api-gateway/utils"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetDownloadURL returns a short-lived presigned URL downloading the file straight from
+// storage. It's handed to the same users GetFile serves the file to.
+func GetDownloadURL(c *gin.Context) {
+	// Retrieve the server instance from context
+	s, _ := c.Get("server")
+	serverInstance := s.(*server.Server)
+	name := c.Param("bucket")
+	fileName := c.Param("file")
+	namespace, bucketName, ok := serverInstance.Namespaces.Resolve(name)
+	if !ok {
+		utils.RespondWithError(c, http.StatusNotFound, utils.CodeNotFound, fmt.Sprintf("Namespace '%s' not found", name))
+		return
+	}
+	notFound := fmt.Sprintf("File '%s' not found in namespace '%s'", fileName, namespace)
+
+	allowed, err := canReadFile(c, serverInstance, namespace, bucketName, fileName)
+	if err != nil {
+		utils.RespondWithGRPCError(c, err)
+		return
+	}
+	if !allowed {
+		utils.RespondWithError(c, http.StatusNotFound, utils.CodeNotFound, notFound)
+		return
+	}
+
+	// Presigning doesn't check that the file is there
+	_, err = serverInstance.Storage.Head(c.Request.Context(), bucketName, fileName)
+	if err := c.Request.Context().Err(); err != nil {
+		// The deadline passed or the client went away
+		utils.RespondWithGRPCError(c, err)
+		return
+	}
+	if errors.Is(err, storage.ErrNotFound) || errors.Is(err, storage.ErrBucketNotFound) {
+		utils.RespondWithError(c, http.StatusNotFound, utils.CodeNotFound, notFound)
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "Failed to check file", "bucket", bucketName, "key", fileName, "error", err)
+		utils.RespondWithError(c, http.StatusInternalServerError, utils.CodeInternal, "Failed to create download URL")
+		return
+	}
+
+	expiry := config.Current.DownloadURLExpiry
+	url, err := serverInstance.Storage.PresignGet(c.Request.Context(), bucketName, fileName, expiry)
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "Failed to presign download", "bucket", bucketName, "key", fileName, "error", err)
+		utils.RespondWithError(c, http.StatusInternalServerError, utils.CodeInternal, "Failed to create download URL")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"url":        url,
+		"expires_at": time.Now().UTC().Add(expiry),
+	})
+}
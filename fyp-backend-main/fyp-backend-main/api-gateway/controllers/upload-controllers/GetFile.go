@@ -2,58 +2,156 @@ package uploadcontrollers
 
 import (
 	"api-gateway/server"
-	"context"
+	"api-gateway/storage"
+	"api-gateway/utils"
+	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
+	"strconv"
+	"strings"
 
-	"github.com/aws/aws-sdk-go-v2/aws"
-	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/gin-gonic/gin"
 )
 
+// GetFile serves an uploaded file to its owner, the users it's public or shared with, and
+// everyone once its owner attached it to a public integration or workflow. Others get a
+// 404, so file names can't be probed.
 func GetFile(c *gin.Context) {
 	// Retrieve the server instance from context
 	s, _ := c.Get("server")
 	serverInstance := s.(*server.Server)
 	// Extract query parameters
-	bucketName, _ := c.Params.Get("bucket")
+	name, _ := c.Params.Get("bucket")
 	fileName, _ := c.Params.Get("file")
 
 	// Validate query parameters
-	if bucketName == "" || fileName == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Missing bucket or file parameter"})
+	if name == "" || fileName == "" {
+		utils.RespondWithError(c, http.StatusBadRequest, utils.CodeInvalidArgument, "Missing namespace or file parameter")
+		return
+	}
+	namespace, bucketName, ok := serverInstance.Namespaces.Resolve(name)
+	if !ok {
+		utils.RespondWithError(c, http.StatusNotFound, utils.CodeNotFound, fmt.Sprintf("Namespace '%s' not found", name))
 		return
 	}
 
-	// Retrieve the file from S3
-	result, err := serverInstance.S3Client.GetObject(context.TODO(), &s3.GetObjectInput{
-		Bucket: aws.String(bucketName),
-		Key:    aws.String(fileName),
-	})
+	allowed, err := canReadFile(c, serverInstance, namespace, bucketName, fileName)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to fetch file '%s' from bucket '%s'", fileName, bucketName)})
+		utils.RespondWithGRPCError(c, err)
+		return
+	}
+	if !allowed {
+		utils.RespondWithError(c, http.StatusNotFound, utils.CodeNotFound, fmt.Sprintf("File '%s' not found in namespace '%s'", fileName, namespace))
 		return
 	}
-	defer result.Body.Close()
+
+	serveObject(c, serverInstance.Storage, bucketName, fileName)
+}
+
+// canReadFile checks the registry record of the file, then whether its owner attached it
+// to a public integration or workflow. Files uploaded before the registry existed have no
+// record and are authorized by their attachments alone.
+func canReadFile(c *gin.Context, serverInstance *server.Server, namespace, bucketName, fileName string) (bool, error) {
+	// Make the call on behalf of the signed in user
+	ctx := utils.OutgoingContext(c)
+
+	upload, err := serverInstance.Uploads.Lookup(c.Request.Context(), bucketName, fileName)
+	switch {
+	case err == nil:
+		if upload.CanRead(c.GetString("userID")) {
+			return true, nil
+		}
+		// Attaching a file doesn't publish it before its upload is verified
+		if upload.Status == storage.UploadPending {
+			return false, nil
+		}
+		attachment, err := serverInstance.FileAttachment(ctx, namespace, fileName, upload.Owner)
+		return attachment.Public, err
+	case errors.Is(err, storage.ErrNotFound), errors.Is(err, storage.ErrBucketNotFound):
+		attachment, err := serverInstance.FileAttachment(ctx, namespace, fileName, "")
+		return attachment.Public || attachment.Owned, err
+	case errors.Is(err, storage.ErrInvalidKey):
+		return false, nil
+	default:
+		return false, err
+	}
+}
+
+// serveObject streams the object, or the single byte range the client asked for
+func serveObject(c *gin.Context, store storage.BlobStore, bucketName, fileName string) {
+	// Retrieve the file from storage
+	object, err := store.Get(c.Request.Context(), bucketName, fileName, parseRange(c.GetHeader("Range")))
+	if err := c.Request.Context().Err(); err != nil {
+		// The deadline passed or the client went away
+		utils.RespondWithGRPCError(c, err)
+		return
+	}
+	switch {
+	case errors.Is(err, storage.ErrNotFound), errors.Is(err, storage.ErrBucketNotFound):
+		utils.RespondWithError(c, http.StatusNotFound, utils.CodeNotFound, fmt.Sprintf("File '%s' not found", fileName))
+		return
+	case errors.Is(err, storage.ErrInvalidKey):
+		utils.RespondWithError(c, http.StatusBadRequest, utils.CodeInvalidArgument, "Invalid bucket or file name")
+		return
+	case errors.Is(err, storage.ErrInvalidRange):
+		utils.RespondWithError(c, http.StatusRequestedRangeNotSatisfiable, utils.CodeInvalidArgument, "Requested range is not satisfiable")
+		return
+	case err != nil:
+		slog.ErrorContext(c.Request.Context(), "Failed to fetch file", "bucket", bucketName, "file", fileName, "error", err)
+		utils.RespondWithError(c, http.StatusInternalServerError, utils.CodeInternal, fmt.Sprintf("Failed to fetch file '%s'", fileName))
+		return
+	}
+	defer object.Close()
 
 	// Get content type from metadata
 	contentType := "application/octet-stream" // Default to binary stream
-	if result.ContentType != nil {
-		contentType = *result.ContentType
+	if object.Info.ContentType != "" {
+		contentType = object.Info.ContentType
 	}
 
 	// Set headers for inline display
 	c.Writer.Header().Set("Content-Type", contentType)
 	c.Writer.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=%s", fileName))
-	if result.ContentLength != nil {
-		c.Writer.Header().Set("Content-Length", fmt.Sprintf("%d", *result.ContentLength))
+	c.Writer.Header().Set("Accept-Ranges", "bytes")
+	if object.Info.ETag != "" {
+		c.Writer.Header().Set("ETag", strconv.Quote(object.Info.ETag))
 	}
+	status := http.StatusOK
+	if object.Range != nil {
+		status = http.StatusPartialContent
+		c.Writer.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", object.Range.Offset, object.Range.Offset+object.Range.Length-1, object.Info.Size))
+		c.Writer.Header().Set("Content-Length", strconv.FormatInt(object.Range.Length, 10))
+	} else {
+		c.Writer.Header().Set("Content-Length", strconv.FormatInt(object.Info.Size, 10))
+	}
+	c.Status(status)
 
-	// Stream the file content to the client
-	_, err = io.Copy(c.Writer, result.Body)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to stream file"})
-		return
+	// Stream the file content to the client, the status is already sent if this fails
+	if _, err := io.Copy(c.Writer, object); err != nil {
+		slog.WarnContext(c.Request.Context(), "Failed to stream file", "bucket", bucketName, "file", fileName, "error", err)
+	}
+}
+
+// parseRange reads a single "bytes=start-" or "bytes=start-end" range. Other ranges are
+// ignored and the whole file is sent, as HTTP allows.
+func parseRange(header string) *storage.Range {
+	spec, ok := strings.CutPrefix(header, "bytes=")
+	if !ok || strings.Contains(spec, ",") {
+		return nil
+	}
+	startText, endText, _ := strings.Cut(spec, "-")
+	start, err := strconv.ParseInt(startText, 10, 64)
+	if err != nil || start < 0 {
+		return nil
+	}
+	if endText == "" {
+		return &storage.Range{Offset: start, Length: -1}
+	}
+	end, err := strconv.ParseInt(endText, 10, 64)
+	if err != nil || end < start {
+		return nil
 	}
+	return &storage.Range{Offset: start, Length: end - start + 1}
 }
@@ -0,0 +1,95 @@
+package uploadcontrollers
+
+import (
+	"api-gateway/server"
+	"api-gateway/storage"
+	"api-gateway/utils"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetResumableUpload answers the HEAD request tus clients resume an upload with, the
+// Upload-Offset header tells them where to continue from.
+func GetResumableUpload(c *gin.Context) {
+	if !checkTusResumable(c) {
+		return
+	}
+
+	// Retrieve the server instance from context
+	s, _ := c.Get("server")
+	serverInstance := s.(*server.Server)
+	upload, ok := resumableUpload(c, serverInstance)
+	if !ok {
+		return
+	}
+
+	setResumableUploadHeaders(c, upload)
+	if upload.Metadata != "" {
+		c.Header("Upload-Metadata", upload.Metadata)
+	}
+	c.Header("Cache-Control", "no-store")
+	c.Status(http.StatusOK)
+}
+
+// resumableUpload reads the upload named by the request and checks that the caller
+// started it, responding with an error otherwise. Unfinished uploads that expired are
+// gone, the sweeper removes them and their parts.
+func resumableUpload(c *gin.Context, serverInstance *server.Server) (*storage.ResumableUpload, bool) {
+	// Extract userID from context
+	userID := c.GetString("userID")
+	if userID == "" {
+		utils.RespondWithError(c, http.StatusUnauthorized, utils.CodeUnauthenticated, "userID not found in context")
+		return nil, false
+	}
+
+	name := c.Param("bucket")
+	id := c.Param("id")
+	namespace, bucketName, ok := serverInstance.Namespaces.Resolve(name)
+	if !ok {
+		utils.RespondWithError(c, http.StatusNotFound, utils.CodeNotFound, fmt.Sprintf("Namespace '%s' not found", name))
+		return nil, false
+	}
+	notFound := fmt.Sprintf("Upload '%s' not found in namespace '%s'", id, namespace)
+
+	upload, err := serverInstance.ResumableUploads.Get(c.Request.Context(), bucketName, id)
+	if err := c.Request.Context().Err(); err != nil {
+		// The deadline passed or the client went away
+		utils.RespondWithGRPCError(c, err)
+		return nil, false
+	}
+	switch {
+	case errors.Is(err, storage.ErrNotFound), errors.Is(err, storage.ErrBucketNotFound), errors.Is(err, storage.ErrInvalidKey):
+		utils.RespondWithError(c, http.StatusNotFound, utils.CodeNotFound, notFound)
+		return nil, false
+	case err != nil:
+		utils.RespondWithGRPCError(c, err)
+		return nil, false
+	}
+	if upload.Upload.Owner != userID {
+		utils.RespondWithError(c, http.StatusNotFound, utils.CodeNotFound, notFound)
+		return nil, false
+	}
+	if !upload.Finished && time.Now().After(upload.ExpiresAt) {
+		utils.RespondWithError(c, http.StatusGone, utils.CodeFailedPrecondition, "The upload expired, start a new one")
+		return nil, false
+	}
+	return upload, true
+}
+
+// setResumableUploadHeaders reports the progress of the upload, and the URL of its file
+// once it was registered
+func setResumableUploadHeaders(c *gin.Context, upload *storage.ResumableUpload) {
+	c.Header("Upload-Offset", strconv.FormatInt(upload.Offset, 10))
+	c.Header("Upload-Length", strconv.FormatInt(upload.Length, 10))
+	if !upload.Finished {
+		c.Header("Upload-Expires", upload.ExpiresAt.UTC().Format(http.TimeFormat))
+	}
+	if upload.Recorded {
+		c.Header("X-File-URL", utils.FileURL(upload.Upload.Namespace, upload.Upload.Key))
+	}
+}
@@ -0,0 +1,69 @@
+package uploadcontrollers
+
+import (
+	"api-gateway/metrics"
+	"api-gateway/server"
+	"api-gateway/storage"
+	"api-gateway/utils"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PutBlob stores the body sent to a presigned upload URL of the local and memory
+// storage drivers. Like S3, the Content-Type and Content-Length must be the ones the
+// URL was signed for and the body must match the signed checksum.
+func PutBlob(c *gin.Context) {
+	// Retrieve the server instance from context
+	s, _ := c.Get("server")
+	serverInstance := s.(*server.Server)
+	if serverInstance.StorageURLs == nil {
+		utils.RespondWithError(c, http.StatusNotFound, utils.CodeNotFound, "Not found")
+		return
+	}
+	bucketName := c.Param("bucket")
+	key := strings.TrimPrefix(c.Param("key"), "/")
+	conditions := storage.PutConditions{
+		ContentType: c.GetHeader("Content-Type"),
+		Size:        c.Request.ContentLength,
+	}
+
+	// The signature stands in for authentication
+	if err := serverInstance.StorageURLs.Verify(http.MethodPut, bucketName, key, conditions, c.Request.URL.Query()); err != nil {
+		utils.RespondWithError(c, http.StatusForbidden, utils.CodePermissionDenied, err.Error())
+		return
+	}
+
+	info, err := serverInstance.Storage.Put(c.Request.Context(), bucketName, key, c.Request.Body, conditions.Size, conditions.ContentType)
+	if err := c.Request.Context().Err(); err != nil {
+		// The deadline passed or the client went away
+		utils.RespondWithGRPCError(c, err)
+		return
+	}
+	if errors.Is(err, storage.ErrInvalidKey) {
+		utils.RespondWithError(c, http.StatusBadRequest, utils.CodeInvalidArgument, "Invalid bucket or file name")
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "Failed to store presigned upload", "bucket", bucketName, "key", key, "error", err)
+		utils.RespondWithError(c, http.StatusInternalServerError, utils.CodeInternal, "Failed to upload file to storage")
+		return
+	}
+
+	// S3 checks the checksum before storing the object, it's removed here instead
+	if checksum := c.Query(storage.ChecksumParam); checksum != "" && checksum != info.ChecksumSHA256 {
+		if err := serverInstance.Storage.Delete(c.Request.Context(), bucketName, key); err != nil {
+			slog.WarnContext(c.Request.Context(), "Failed to delete corrupt upload", "bucket", bucketName, "key", key, "error", err)
+		}
+		utils.RespondWithError(c, http.StatusBadRequest, utils.CodeInvalidArgument, "The body doesn't match the signed SHA-256 checksum")
+		return
+	}
+
+	metrics.UploadedBytes.Add(float64(info.Size))
+	c.Header("ETag", strconv.Quote(info.ETag))
+	c.Status(http.StatusOK)
+}
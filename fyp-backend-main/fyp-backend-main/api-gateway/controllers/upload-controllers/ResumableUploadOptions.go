@@ -0,0 +1,72 @@
+package uploadcontrollers
+
+import (
+	"api-gateway/config"
+	"api-gateway/utils"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// The version and extensions of the tus protocol resumable uploads follow, see https://tus.io/protocols/resumable-upload
+const (
+	tusVersion    = "1.0.0"
+	tusExtensions = "creation,creation-with-upload,expiration,termination"
+	// tusContentType is the content type of the chunks of an upload
+	tusContentType = "application/offset+octet-stream"
+)
+
+// ResumableUploadOptions describes the tus protocol spoken by the resumable upload endpoint.
+func ResumableUploadOptions(c *gin.Context) {
+	c.Header("Tus-Resumable", tusVersion)
+	c.Header("Tus-Version", tusVersion)
+	c.Header("Tus-Extension", tusExtensions)
+	c.Header("Tus-Max-Size", strconv.Itoa(config.Current.ResumableMaxSize))
+	c.Status(http.StatusNoContent)
+}
+
+// checkTusResumable sets the Tus-Resumable header every response carries and rejects
+// requests speaking another version of tus
+func checkTusResumable(c *gin.Context) bool {
+	c.Header("Tus-Resumable", tusVersion)
+	if c.GetHeader("Tus-Resumable") != tusVersion {
+		c.Header("Tus-Version", tusVersion)
+		utils.RespondWithError(c, http.StatusPreconditionFailed, utils.CodeFailedPrecondition, "Tus-Resumable must be "+tusVersion)
+		return false
+	}
+	return true
+}
+
+// parseTusMetadata reads the Upload-Metadata header, comma separated keys followed by
+// their base64 value, if any
+func parseTusMetadata(header string) (map[string]string, error) {
+	metadata := make(map[string]string)
+	if strings.TrimSpace(header) == "" {
+		return metadata, nil
+	}
+	for _, pair := range strings.Split(header, ",") {
+		key, encoded, _ := strings.Cut(strings.TrimSpace(pair), " ")
+		if key == "" {
+			return nil, errors.New("Upload-Metadata has an empty key")
+		}
+		if _, ok := metadata[key]; ok {
+			return nil, errors.New("Upload-Metadata repeats the key " + key)
+		}
+		value, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+		if err != nil {
+			return nil, errors.New("Upload-Metadata has an invalid value for " + key)
+		}
+		metadata[key] = string(value)
+	}
+	return metadata, nil
+}
+
+// parseUploadOffset reads an Upload-Offset or Upload-Length header
+func parseUploadOffset(header string) (int64, bool) {
+	value, err := strconv.ParseInt(header, 10, 64)
+	return value, err == nil && value >= 0
+}
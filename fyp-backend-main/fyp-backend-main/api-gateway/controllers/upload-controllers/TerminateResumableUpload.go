@@ -0,0 +1,47 @@
+package uploadcontrollers
+
+import (
+	"api-gateway/server"
+	"api-gateway/utils"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TerminateResumableUpload abandons a tus upload and removes the parts it stored. The
+// file of a finished upload is kept, it's registered like any other.
+func TerminateResumableUpload(c *gin.Context) {
+	if !checkTusResumable(c) {
+		return
+	}
+
+	// Retrieve the server instance from context
+	s, _ := c.Get("server")
+	serverInstance := s.(*server.Server)
+	// Only the owner can lock the upload, a write may finish it before the lock is taken
+	upload, ok := resumableUpload(c, serverInstance)
+	if !ok {
+		return
+	}
+	release, ok := lockResumableUpload(c, upload)
+	if !ok {
+		return
+	}
+	defer release()
+	if upload, ok = resumableUpload(c, serverInstance); !ok {
+		return
+	}
+
+	if err := serverInstance.ResumableUploads.Terminate(c.Request.Context(), upload); err != nil {
+		if err := c.Request.Context().Err(); err != nil {
+			// The deadline passed or the client went away
+			utils.RespondWithGRPCError(c, err)
+			return
+		}
+		slog.ErrorContext(c.Request.Context(), "Failed to terminate upload", "bucket", upload.Upload.Bucket, "id", upload.ID, "error", err)
+		utils.RespondWithError(c, http.StatusInternalServerError, utils.CodeInternal, "Failed to terminate upload")
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,145 @@
+package uploadcontrollers
+
+import (
+	"api-gateway/config"
+	"api-gateway/server"
+	"api-gateway/storage"
+	"api-gateway/utils"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxFileShares keeps upload records small
+const maxFileShares = 100
+
+// updateFileAccessRequest changes who may read a file, every field is optional
+type updateFileAccessRequest struct {
+	Visibility *string  `json:"visibility"`
+	Share      []string `json:"share"`
+	Unshare    []string `json:"unshare"`
+}
+
+// UpdateFileAccess lets the owner of an uploaded file make it public or private and share it
+// with other users. Files uploaded before the registry existed are claimed by the owner of
+// an integration or workflow they were attached to before it, see STORAGE_REGISTRY_SINCE.
+func UpdateFileAccess(c *gin.Context) {
+	// Extract userID from context
+	userID := c.GetString("userID")
+	if userID == "" {
+		utils.RespondWithError(c, http.StatusUnauthorized, utils.CodeUnauthenticated, "userID not found in context")
+		return
+	}
+
+	// Bind the request body
+	var req updateFileAccessRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, utils.CodeInvalidArgument, err.Error())
+		return
+	}
+	if req.Visibility != nil && *req.Visibility != storage.VisibilityPrivate && *req.Visibility != storage.VisibilityPublic {
+		utils.RespondWithError(c, http.StatusBadRequest, utils.CodeInvalidArgument, "Visibility must be private or public")
+		return
+	}
+
+	// Retrieve the server instance from context
+	s, _ := c.Get("server")
+	serverInstance := s.(*server.Server)
+	name := c.Param("bucket")
+	fileName := c.Param("file")
+	namespace, bucketName, ok := serverInstance.Namespaces.Resolve(name)
+	if !ok {
+		utils.RespondWithError(c, http.StatusNotFound, utils.CodeNotFound, fmt.Sprintf("Namespace '%s' not found", name))
+		return
+	}
+	notFound := fmt.Sprintf("File '%s' not found in namespace '%s'", fileName, namespace)
+
+	upload, err := serverInstance.Uploads.Lookup(c.Request.Context(), bucketName, fileName)
+	if errors.Is(err, storage.ErrNotFound) || errors.Is(err, storage.ErrBucketNotFound) {
+		upload, err = claimUpload(c, serverInstance, namespace, bucketName, fileName)
+	}
+	if err := c.Request.Context().Err(); err != nil {
+		// The deadline passed or the client went away
+		utils.RespondWithGRPCError(c, err)
+		return
+	}
+	switch {
+	case errors.Is(err, storage.ErrNotFound), errors.Is(err, storage.ErrBucketNotFound), errors.Is(err, storage.ErrInvalidKey):
+		utils.RespondWithError(c, http.StatusNotFound, utils.CodeNotFound, notFound)
+		return
+	case err != nil:
+		utils.RespondWithGRPCError(c, err)
+		return
+	}
+
+	// Users who can read the file learn that only its owner may change it
+	if upload.Owner != userID {
+		if upload.CanRead(userID) {
+			utils.RespondWithError(c, http.StatusForbidden, utils.CodePermissionDenied, "Only the owner of the file can change who may read it")
+		} else {
+			utils.RespondWithError(c, http.StatusNotFound, utils.CodeNotFound, notFound)
+		}
+		return
+	}
+
+	// Apply the changes, the last update wins
+	if req.Visibility != nil {
+		upload.Visibility = *req.Visibility
+	}
+	for _, user := range req.Share {
+		if user != "" && user != upload.Owner && !slices.Contains(upload.SharedWith, user) {
+			upload.SharedWith = append(upload.SharedWith, user)
+		}
+	}
+	upload.SharedWith = slices.DeleteFunc(upload.SharedWith, func(user string) bool {
+		return slices.Contains(req.Unshare, user)
+	})
+	if len(upload.SharedWith) > maxFileShares {
+		utils.RespondWithError(c, http.StatusBadRequest, utils.CodeInvalidArgument, fmt.Sprintf("A file can be shared with at most %d users", maxFileShares))
+		return
+	}
+	if err := serverInstance.Uploads.Record(c.Request.Context(), *upload); err != nil {
+		utils.RespondWithGRPCError(c, err)
+		return
+	}
+
+	// Return the response
+	c.JSON(http.StatusOK, gin.H{
+		"file": upload,
+	})
+}
+
+// claimUpload creates the record of a file uploaded before the registry existed, owned by
+// the caller when it's attached to one of their integrations or workflows created before
+// the registry. Attachments made since then prove nothing, so they can't claim a file.
+func claimUpload(c *gin.Context, serverInstance *server.Server, namespace, bucketName, fileName string) (*storage.Upload, error) {
+	since, err := config.Current.RegistrySinceTime()
+	if err != nil || since.IsZero() {
+		return nil, storage.ErrNotFound
+	}
+	attachment, err := serverInstance.FileAttachment(utils.OutgoingContext(c), namespace, fileName, "")
+	if err != nil {
+		return nil, err
+	}
+	if !attachment.Owned || !attachment.OwnedSince.Before(since) {
+		return nil, storage.ErrNotFound
+	}
+	info, err := serverInstance.Storage.Head(c.Request.Context(), bucketName, fileName)
+	if err != nil {
+		return nil, err
+	}
+	return &storage.Upload{
+		Namespace:        namespace,
+		Bucket:           bucketName,
+		Key:              fileName,
+		Owner:            c.GetString("userID"),
+		OriginalFilename: fileName,
+		Size:             info.Size,
+		ContentType:      info.ContentType,
+		Visibility:       storage.VisibilityPrivate,
+		CreatedAt:        info.LastModified,
+	}, nil
+}
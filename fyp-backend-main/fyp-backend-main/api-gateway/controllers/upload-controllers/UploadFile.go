@@ -1,48 +1,104 @@
 package uploadcontrollers
 
 import (
+	"api-gateway/config"
+	"api-gateway/metrics"
 	"api-gateway/server"
+	"api-gateway/storage"
 	"api-gateway/utils"
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
 
-// UploadFile handles uploading a document and returns a unique URL
+// multipartOverhead leaves room for the form fields and part headers around the file
+const multipartOverhead = 1 << 20
+
+// UploadFile handles uploading a document to a namespace and returns a unique URL. The file is
+// private to the uploader unless the visibility form field is public. Files are limited to
+// UPLOAD_MAX_SIZE, as presigned uploads are.
 func UploadFile(c *gin.Context) {
 	// Retrieve the server instance from context
 	s, _ := c.Get("server")
 	serverInstance := s.(*server.Server)
 	// Extract query parameters
-	bucketName, _ := c.Params.Get("bucket")
+	name, _ := c.Params.Get("bucket")
 	// Validate query parameters
-	if bucketName == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Missing bucket parameter"})
+	if name == "" {
+		utils.RespondWithError(c, http.StatusBadRequest, utils.CodeInvalidArgument, "Missing namespace parameter")
+		return
+	}
+	// Only the configured namespaces can be uploaded to, older clients name their bucket
+	namespace, bucketName, ok := serverInstance.Namespaces.Resolve(name)
+	if !ok {
+		utils.RespondWithError(c, http.StatusNotFound, utils.CodeNotFound, fmt.Sprintf("Namespace '%s' not found", name))
+		return
+	}
+	// Stop reading the body past the limit, the form is parsed with the first field read
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, int64(config.Current.UploadMaxSize)+multipartOverhead)
+	visibility := c.DefaultPostForm("visibility", storage.VisibilityPrivate)
+	if visibility != storage.VisibilityPrivate && visibility != storage.VisibilityPublic {
+		utils.RespondWithError(c, http.StatusBadRequest, utils.CodeInvalidArgument, "Visibility must be private or public")
 		return
 	}
 	// Retrieve file from the request
 	file, header, err := c.Request.FormFile("file")
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		utils.RespondWithError(c, http.StatusRequestEntityTooLarge, utils.CodeInvalidArgument, fmt.Sprintf("Files can be at most %d bytes", config.Current.UploadMaxSize))
+		return
+	}
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to retrieve file"})
+		utils.RespondWithError(c, http.StatusBadRequest, utils.CodeInvalidArgument, "Failed to retrieve file")
 		return
 	}
 	defer file.Close()
+	if header.Size > int64(config.Current.UploadMaxSize) {
+		utils.RespondWithError(c, http.StatusRequestEntityTooLarge, utils.CodeInvalidArgument, fmt.Sprintf("Files can be at most %d bytes", config.Current.UploadMaxSize))
+		return
+	}
 
 	// Generate a unique file name
 	uniqueID := uuid.New().String()
 	fileName := fmt.Sprintf("%s-%s", uniqueID, header.Filename)
 
-	// Upload the file to S3
-	fileURL, err := utils.UploadToS3(file, fileName, header.Size, bucketName, serverInstance.S3Client)
+	// Upload the file to storage
+	fileURL, err := utils.UploadToStorage(c.Request.Context(), file, storage.Upload{
+		Namespace:        namespace,
+		Bucket:           bucketName,
+		Key:              fileName,
+		Owner:            c.GetString("userID"),
+		OriginalFilename: header.Filename,
+		Size:             header.Size,
+		ContentType:      header.Header.Get("Content-Type"),
+		Visibility:       visibility,
+	}, serverInstance.Storage, serverInstance.Uploads)
+	if err := c.Request.Context().Err(); err != nil {
+		// The deadline passed or the client went away
+		utils.RespondWithGRPCError(c, err)
+		return
+	}
+	if errors.Is(err, storage.ErrBucketNotFound) {
+		slog.ErrorContext(c.Request.Context(), "Bucket of namespace not found", "namespace", namespace, "bucket", bucketName)
+		utils.RespondWithError(c, http.StatusNotFound, utils.CodeNotFound, fmt.Sprintf("Namespace '%s' not found", namespace))
+		return
+	}
+	if errors.Is(err, storage.ErrInvalidKey) {
+		utils.RespondWithError(c, http.StatusBadRequest, utils.CodeInvalidArgument, "Invalid file name")
+		return
+	}
 	if err != nil {
-		log.Println("Error uploading file to S3:", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to upload file to storage"})
+		slog.ErrorContext(c.Request.Context(), "Failed to upload file to storage", "error", err)
+		utils.RespondWithError(c, http.StatusInternalServerError, utils.CodeInternal, "Failed to upload file to storage")
 		return
 	}
 
+	metrics.UploadedBytes.Add(float64(header.Size))
+
 	// Return the unique file URL
 	c.JSON(http.StatusOK, gin.H{
 		"message":  "File uploaded successfully",
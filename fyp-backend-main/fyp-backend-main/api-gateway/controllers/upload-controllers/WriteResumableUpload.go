@@ -0,0 +1,109 @@
+package uploadcontrollers
+
+import (
+	"api-gateway/config"
+	"api-gateway/metrics"
+	"api-gateway/server"
+	"api-gateway/storage"
+	"api-gateway/utils"
+	"errors"
+	"fmt"
+	"log/slog"
+	"mime"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// resumableLockSlack keeps a write locked a little past the deadline of its request, the
+// bytes received are stored after the client goes away
+const resumableLockSlack = time.Minute
+
+// WriteResumableUpload appends the body of a tus PATCH request to the upload, from the
+// offset the request starts at. Once every byte was received the file is registered
+// like an upload sent in one request, and its URL is returned in X-File-URL.
+func WriteResumableUpload(c *gin.Context) {
+	if !checkTusResumable(c) {
+		return
+	}
+	if mediaType, _, _ := mime.ParseMediaType(c.ContentType()); mediaType != tusContentType {
+		utils.RespondWithError(c, http.StatusUnsupportedMediaType, utils.CodeInvalidArgument, "Content-Type must be "+tusContentType)
+		return
+	}
+	offset, ok := parseUploadOffset(c.GetHeader("Upload-Offset"))
+	if !ok {
+		utils.RespondWithError(c, http.StatusBadRequest, utils.CodeInvalidArgument, "Upload-Offset must be a non-negative integer")
+		return
+	}
+
+	// Retrieve the server instance from context
+	s, _ := c.Get("server")
+	serverInstance := s.(*server.Server)
+
+	// Only the owner can lock the upload, then its offset is read again under the lock,
+	// one write at a time
+	upload, ok := resumableUpload(c, serverInstance)
+	if !ok {
+		return
+	}
+	release, ok := lockResumableUpload(c, upload)
+	if !ok {
+		return
+	}
+	defer release()
+	if upload, ok = resumableUpload(c, serverInstance); !ok {
+		return
+	}
+	if !writeResumableUpload(c, serverInstance, upload, offset) {
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// writeResumableUpload writes the request body to the upload and sets its progress
+// headers, responding with an error when it fails
+func writeResumableUpload(c *gin.Context, serverInstance *server.Server, upload *storage.ResumableUpload, offset int64) bool {
+	previous := upload.Offset
+	err := serverInstance.ResumableUploads.Write(c.Request.Context(), upload, offset, c.Request.Body)
+	metrics.UploadedBytes.Add(float64(upload.Offset - previous))
+	if errors.Is(err, storage.ErrOffsetMismatch) {
+		c.Header("Upload-Offset", strconv.FormatInt(upload.Offset, 10))
+		utils.RespondWithError(c, http.StatusConflict, utils.CodeFailedPrecondition, fmt.Sprintf("Upload-Offset must be %d", upload.Offset))
+		return false
+	}
+	if err := c.Request.Context().Err(); err != nil {
+		// The deadline passed or the client went away, what was received is kept
+		utils.RespondWithGRPCError(c, err)
+		return false
+	}
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "Failed to write upload", "bucket", upload.Upload.Bucket, "id", upload.ID, "error", err)
+		utils.RespondWithError(c, http.StatusInternalServerError, utils.CodeInternal, "Failed to write upload")
+		return false
+	}
+	setResumableUploadHeaders(c, upload)
+	return true
+}
+
+// lockResumableUpload keeps other requests and the sweeper from changing the upload until
+// the returned function is called, across gateway instances. It responds with an error
+// when the upload is locked already.
+func lockResumableUpload(c *gin.Context, upload *storage.ResumableUpload) (func(), bool) {
+	ttl := config.Current.ResumableExpiry
+	if deadline, ok := c.Request.Context().Deadline(); ok {
+		ttl = time.Until(deadline) + resumableLockSlack
+	}
+	release, acquired, err := utils.AcquireLock(c.Request.Context(), upload.LockName(), ttl)
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "Failed to lock upload", "id", upload.ID, "error", err)
+		utils.RespondWithError(c, http.StatusServiceUnavailable, utils.CodeUnavailable, "Failed to lock upload")
+		return nil, false
+	}
+	if !acquired {
+		utils.RespondWithError(c, http.StatusLocked, utils.CodeAborted, "The upload is being written by another request")
+		return nil, false
+	}
+	return release, true
+}
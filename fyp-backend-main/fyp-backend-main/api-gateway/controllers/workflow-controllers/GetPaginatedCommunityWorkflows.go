@@ -1,12 +1,12 @@
 package workflowcontrollers
 
 import (
-	"context"
 	"net/http"
 	"strconv"
 
 	workflow_service "api-gateway/proto/generated/github.com/multiagentai/backend/workflow-service"
 	"api-gateway/server"
+	"api-gateway/utils"
 
 	"github.com/gin-gonic/gin"
 )
@@ -16,19 +16,19 @@ func GetPaginatedCommunityWorkflows(c *gin.Context) {
 	limit := c.Query("limit")
 
 	if offset == "" || limit == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Offset and limit are required"})
+		utils.RespondWithError(c, http.StatusBadRequest, utils.CodeInvalidArgument, "Offset and limit are required")
 		return
 	}
 
 	offsetInt, err := strconv.Atoi(offset)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid offset"})
+		utils.RespondWithError(c, http.StatusBadRequest, utils.CodeInvalidArgument, "Invalid offset")
 		return
 	}
 
 	limitInt, err := strconv.Atoi(limit)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit"})
+		utils.RespondWithError(c, http.StatusBadRequest, utils.CodeInvalidArgument, "Invalid limit")
 		return
 	}
 
@@ -43,9 +43,9 @@ func GetPaginatedCommunityWorkflows(c *gin.Context) {
 	}
 
 	// Make the gRPC call to delete the integration
-	res, err := serverInstance.WorkflowService.GetPaginatedCommunityWorkflows(context.Background(), req)
+	res, err := serverInstance.WorkflowService.GetPaginatedCommunityWorkflows(utils.OutgoingContext(c), req)
 	if err != nil {
-		c.JSON(500, gin.H{"error": err.Error()})
+		utils.RespondWithGRPCError(c, err)
 		return
 	}
 	c.JSON(200, res)
@@ -0,0 +1,71 @@
+package workflowcontrollers
+
+import (
+	workflow_service "api-gateway/proto/generated/github.com/multiagentai/backend/workflow-service"
+	"api-gateway/server"
+	"api-gateway/utils"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+func SearchWorkflow(c *gin.Context) {
+	// Extract userID from context
+	if _, exists := c.Get("userID"); !exists {
+		utils.RespondWithError(c, http.StatusUnauthorized, utils.CodeUnauthenticated, "userID not found in context")
+		return
+	}
+
+	// Parse query parameters
+	query := c.Query("query")
+	if query == "" {
+		utils.RespondWithError(c, http.StatusBadRequest, utils.CodeInvalidArgument, "Query parameter 'query' is required")
+		return
+	}
+
+	req := &workflow_service.SearchWorkflowRequest{
+		Query: query,
+	}
+	if projectID := c.Query("projectId"); projectID != "" {
+		req.ProjectId = &projectID
+	}
+
+	// Paging is optional, the workflow service applies defaults
+	if offset := c.Query("offset"); offset != "" {
+		offsetInt, err := strconv.Atoi(offset)
+		if err != nil {
+			utils.RespondWithError(c, http.StatusBadRequest, utils.CodeInvalidArgument, "Invalid offset")
+			return
+		}
+		req.Offset = int32(offsetInt)
+	}
+	if limit := c.Query("limit"); limit != "" {
+		limitInt, err := strconv.Atoi(limit)
+		if err != nil {
+			utils.RespondWithError(c, http.StatusBadRequest, utils.CodeInvalidArgument, "Invalid limit")
+			return
+		}
+		req.Limit = int32(limitInt)
+	}
+
+	// Retrieve the server instance from context
+	s, _ := c.Get("server")
+	serverInstance := s.(*server.Server)
+
+	// Make the call on behalf of the signed in user
+	ctx := utils.OutgoingContext(c)
+
+	// Make the gRPC call
+	res, err := serverInstance.WorkflowService.SearchWorkflow(ctx, req)
+	if err != nil {
+		utils.RespondWithGRPCError(c, err)
+		return
+	}
+
+	// Return the response
+	c.JSON(http.StatusOK, gin.H{
+		"workflows": res.Workflows,
+		"total":     res.Total,
+	})
+}
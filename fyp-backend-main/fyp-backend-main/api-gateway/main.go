@@ -1,29 +1,133 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"log"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"api-gateway/config"
+	"api-gateway/metrics"
 	"api-gateway/routes"
 	"api-gateway/server"
+	"api-gateway/utils"
+	"shared/envconfig"
+	"shared/logging"
+	"shared/platform"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 )
 
 var serverInstance *server.Server
 
 func main() {
+	// Load the configuration
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatal(err)
+	}
+	config.Current = cfg
+	if err := logging.Init(cfg.LogLevel, cfg.LogFormat); err != nil {
+		log.Fatal(err)
+	}
+	slog.Info("Effective configuration", "config", envconfig.Redacted(cfg))
+
+	// Shut down gracefully on SIGINT or SIGTERM
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Export spans, the trace context is passed on to the services
+	shutdownTracing, err := platform.InitTracing(ctx, platform.TracingOptions{
+		Exporter:     cfg.TracingExporter,
+		Sampler:      cfg.TracingSampler,
+		OTLPEndpoint: cfg.OTLPEndpoint,
+		OTLPInsecure: cfg.OTLPInsecure,
+	}, "api-gateway")
+	if err != nil {
+		logging.Fatal("Failed to set up tracing", "error", err)
+	}
+
+	// Serve /metrics on the admin port
+	adminServer := metrics.Serve(cfg.AdminAddr())
+
+	// Load the key backend calls are signed with
+	utils.InternalAuth, err = utils.LoadInternalSigner(cfg.InternalAuthKeyFile)
+	if err != nil {
+		logging.Fatal("Failed to load internal auth key", "error", err)
+	}
+
 	// Init Stubs
 	serverInstance = &server.Server{}
-	serverInstance.InitServer()
+	serverInstance.InitServer(ctx, cfg)
+
+	// Connect to Redis, which holds revoked access tokens
+	if err := utils.ConnectToCache(ctx, cfg); err != nil {
+		logging.Fatal("Failed to connect to Redis", "error", err)
+	}
+
+	// Load the keys access tokens are verified with
+	utils.JWKS = utils.NewJWKSCache(serverInstance.AuthService)
+	utils.APIKeys = utils.NewAPIKeyResolver(serverInstance.AuthService)
+	go utils.APIKeys.WatchRevocations(ctx)
+
+	// Limit the requests of every user, API key and client IP
+	if cfg.RateLimitEnabled {
+		limits, err := cfg.RateLimitMap()
+		if err != nil {
+			logging.Fatal("Invalid RATE_LIMITS", "error", err)
+		}
+		utils.RateLimits = utils.NewRateLimiter(limits)
+	}
+
+	// Remove resumable uploads that were abandoned, and the parts they stored
+	go serverInstance.SweepUploads(ctx, cfg.ResumableSweep, cfg.ResumableExpiry)
+
+	// Start Server, requests are logged by AccessLogMiddleware instead of gin's logger
+	if !strings.EqualFold(cfg.LogLevel, "debug") {
+		gin.SetMode(gin.ReleaseMode)
+	}
+	r := gin.New()
+	r.Use(gin.Recovery())
 
-	// Start Server
-	r := gin.Default()
+	// Only trust X-Forwarded-For from the configured proxies, so c.ClientIP() can't be spoofed
+	if err := r.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+		logging.Fatal("Invalid TRUSTED_PROXIES", "error", err)
+	}
+
+	// Start a span for every request except the probes, continuing the caller's trace
+	r.Use(otelgin.Middleware("api-gateway", otelgin.WithFilter(func(req *http.Request) bool {
+		return req.URL.Path != "/healthz" && req.URL.Path != "/readyz"
+	})))
+
+	// Count requests and their latency by route
+	r.Use(metrics.Middleware())
+
+	// Assign every request an ID used in logs and error responses
+	r.Use(utils.RequestIDMiddleware())
+
+	// Log every request with its request ID, status and latency
+	r.Use(utils.AccessLogMiddleware())
+
+	// Give every request a deadline, shared by the backend calls made for it
+	routeTimeouts, err := cfg.RouteTimeoutMap()
+	if err != nil {
+		logging.Fatal("Invalid ROUTE_TIMEOUTS", "error", err)
+	}
+	r.Use(utils.RequestDeadlineMiddleware(cfg.RequestTimeout, routeTimeouts))
 
 	// CORS Middleware Configuration
 	r.Use(cors.New(cors.Config{
-		AllowOrigins:     []string{"*"},
-		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS", "PATCH"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization"},
-		ExposeHeaders:    []string{"Content-Length"},
+		AllowOrigins:     cfg.CORSAllowedOrigins,
+		AllowMethods:     []string{"GET", "HEAD", "POST", "PUT", "DELETE", "OPTIONS", "PATCH"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization", "X-API-Key", "Range", utils.RequestIDHeader, "traceparent", "tracestate", "Tus-Resumable", "Upload-Length", "Upload-Metadata", "Upload-Offset"},
+		ExposeHeaders:    []string{"Content-Length", "Content-Range", "ETag", utils.RequestIDHeader, "Retry-After", "X-RateLimit-Limit", "X-RateLimit-Remaining", "X-RateLimit-Reset", "Location", "Tus-Resumable", "Tus-Version", "Tus-Extension", "Tus-Max-Size", "Upload-Offset", "Upload-Length", "Upload-Metadata", "Upload-Expires", "X-File-URL"},
 		AllowCredentials: true,
 	}))
 
@@ -41,11 +145,51 @@ func main() {
 	})
 
 	// Register Routes
+	routes.HealthRoutes(r)
 	routes.AuthRoutes(r)
 	routes.UploadRoutes(r)
 	routes.IntegrationRoutes(r)
 	routes.ProjectRoutes(r)
 	routes.WorkflowRoutes(r)
+
+	// Unknown routes also use the error envelope
+	r.NoRoute(func(c *gin.Context) {
+		utils.RespondWithError(c, http.StatusNotFound, utils.CodeNotFound, "route not found")
+	})
+
 	// Start the server
-	r.Run(":8000")
+	srv := &http.Server{
+		Addr:    cfg.ListenAddr(),
+		Handler: r,
+	}
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logging.Fatal("Failed to serve", "error", err)
+		}
+	}()
+
+	// Stop accepting connections and let in-flight requests finish
+	<-ctx.Done()
+	stop()
+	slog.Info("Shutting down")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		slog.Warn("Requests still running after the shutdown timeout", "timeout", cfg.ShutdownTimeout, "error", err)
+	}
+
+	// Close the connections to the services and Redis
+	if err := serverInstance.Close(); err != nil {
+		slog.Error("Failed to close service connections", "error", err)
+	}
+	if err := utils.RDB.Close(); err != nil {
+		slog.Error("Failed to close Redis client", "error", err)
+	}
+	if err := adminServer.Shutdown(shutdownCtx); err != nil {
+		slog.Error("Failed to stop the admin server", "error", err)
+	}
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		slog.Error("Failed to flush spans", "error", err)
+	}
+	slog.Info("Stopped")
 }
@@ -0,0 +1,12 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// CircuitBreakerOpen is 1 while calls to the service fail fast, 0 otherwise
+var CircuitBreakerOpen = promauto.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "grpc_circuit_breaker_open",
+	Help: "Whether calls to the backend service fail fast because it is unavailable.",
+}, []string{"service"})
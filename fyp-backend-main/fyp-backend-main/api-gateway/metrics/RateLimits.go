@@ -0,0 +1,12 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// RateLimitedRequests counts requests rejected by the rate limits, by route group
+var RateLimitedRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "rate_limited_requests_total",
+	Help: "Requests rejected with 429 by the rate limits.",
+}, []string{"group"})
@@ -0,0 +1,19 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// UploadedBytes counts the bytes of files stored through the gateway
+var UploadedBytes = promauto.NewCounter(prometheus.CounterOpts{
+	Name: "upload_bytes_total",
+	Help: "Bytes of files uploaded through the gateway.",
+})
+
+// AbandonedUploads counts the resumable uploads and multipart parts removed because
+// they were never finished
+var AbandonedUploads = promauto.NewCounter(prometheus.CounterOpts{
+	Name: "upload_abandoned_total",
+	Help: "Resumable uploads removed after they expired unfinished.",
+})
@@ -31,6 +31,7 @@ const (
 	WorkflowService_GetWorkflowById_FullMethodName                = "/workflow.WorkflowService/GetWorkflowById"
 	WorkflowService_UpdateWorkflow_FullMethodName                 = "/workflow.WorkflowService/UpdateWorkflow"
 	WorkflowService_GetPaginatedCommunityWorkflows_FullMethodName = "/workflow.WorkflowService/GetPaginatedCommunityWorkflows"
+	WorkflowService_GetFileAttachment_FullMethodName              = "/workflow.WorkflowService/GetFileAttachment"
 )
 
 // WorkflowServiceClient is the client API for WorkflowService service.
@@ -52,6 +53,8 @@ type WorkflowServiceClient interface {
 	GetWorkflowById(ctx context.Context, in *GetWorkflowByIdRequest, opts ...grpc.CallOption) (*GetWorkflowByIdResponse, error)
 	UpdateWorkflow(ctx context.Context, in *UpdateWorkflowRequest, opts ...grpc.CallOption) (*UpdateWorkflowResponse, error)
 	GetPaginatedCommunityWorkflows(ctx context.Context, in *GetPaginatedCommunityWorkflowsRequest, opts ...grpc.CallOption) (*GetPaginatedCommunityWorkflowsResponse, error)
+	// Uploads
+	GetFileAttachment(ctx context.Context, in *GetFileAttachmentRequest, opts ...grpc.CallOption) (*GetFileAttachmentResponse, error)
 }
 
 type workflowServiceClient struct {
@@ -182,6 +185,16 @@ func (c *workflowServiceClient) GetPaginatedCommunityWorkflows(ctx context.Conte
 	return out, nil
 }
 
+func (c *workflowServiceClient) GetFileAttachment(ctx context.Context, in *GetFileAttachmentRequest, opts ...grpc.CallOption) (*GetFileAttachmentResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetFileAttachmentResponse)
+	err := c.cc.Invoke(ctx, WorkflowService_GetFileAttachment_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // WorkflowServiceServer is the server API for WorkflowService service.
 // All implementations must embed UnimplementedWorkflowServiceServer
 // for forward compatibility.
@@ -201,6 +214,8 @@ type WorkflowServiceServer interface {
 	GetWorkflowById(context.Context, *GetWorkflowByIdRequest) (*GetWorkflowByIdResponse, error)
 	UpdateWorkflow(context.Context, *UpdateWorkflowRequest) (*UpdateWorkflowResponse, error)
 	GetPaginatedCommunityWorkflows(context.Context, *GetPaginatedCommunityWorkflowsRequest) (*GetPaginatedCommunityWorkflowsResponse, error)
+	// Uploads
+	GetFileAttachment(context.Context, *GetFileAttachmentRequest) (*GetFileAttachmentResponse, error)
 	mustEmbedUnimplementedWorkflowServiceServer()
 }
 
@@ -247,6 +262,9 @@ func (UnimplementedWorkflowServiceServer) UpdateWorkflow(context.Context, *Updat
 func (UnimplementedWorkflowServiceServer) GetPaginatedCommunityWorkflows(context.Context, *GetPaginatedCommunityWorkflowsRequest) (*GetPaginatedCommunityWorkflowsResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetPaginatedCommunityWorkflows not implemented")
 }
+func (UnimplementedWorkflowServiceServer) GetFileAttachment(context.Context, *GetFileAttachmentRequest) (*GetFileAttachmentResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetFileAttachment not implemented")
+}
 func (UnimplementedWorkflowServiceServer) mustEmbedUnimplementedWorkflowServiceServer() {}
 func (UnimplementedWorkflowServiceServer) testEmbeddedByValue()                         {}
 
@@ -484,6 +502,24 @@ func _WorkflowService_GetPaginatedCommunityWorkflows_Handler(srv interface{}, ct
 	return interceptor(ctx, in, info, handler)
 }
 
+func _WorkflowService_GetFileAttachment_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetFileAttachmentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WorkflowServiceServer).GetFileAttachment(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WorkflowService_GetFileAttachment_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WorkflowServiceServer).GetFileAttachment(ctx, req.(*GetFileAttachmentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // WorkflowService_ServiceDesc is the grpc.ServiceDesc for WorkflowService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -539,6 +575,10 @@ var WorkflowService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetPaginatedCommunityWorkflows",
 			Handler:    _WorkflowService_GetPaginatedCommunityWorkflows_Handler,
 		},
+		{
+			MethodName: "GetFileAttachment",
+			Handler:    _WorkflowService_GetFileAttachment_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "workflow.proto",
@@ -9,14 +9,37 @@ import (
 
 // AuthRoutes defines routes related to auth operations
 func AuthRoutes(r *gin.Engine) {
+	// Public keys for verifying access tokens
+	r.GET("/.well-known/jwks.json", authcontrollers.GetJWKS)
+
+	// Counted per user, API key or client IP
+	limit := utils.RateLimit(utils.RateLimitAuth)
 	authGroup := r.Group("/auth")
 	{
-		authGroup.POST("/login", authcontrollers.Login)
-		authGroup.POST("/register", authcontrollers.Register)
+		authGroup.POST("/login", limit, authcontrollers.Login)
+		authGroup.POST("/register", limit, authcontrollers.Register)
+		authGroup.POST("/refresh", limit, authcontrollers.Refresh)
+		authGroup.POST("/social", limit, authcontrollers.SocialAuth)
+		authGroup.POST("/request-reset", limit, authcontrollers.RequestPasswordReset)
+		authGroup.POST("/confirm-reset", limit, authcontrollers.ConfirmPasswordReset)
+		authGroup.POST("/verify-email", limit, authcontrollers.VerifyEmail)
+		authGroup.POST("/mfa/login", limit, authcontrollers.LoginMFA)
 		// Add more user routes as needed
-		// Protected routes that require authentication
-		authGroup.Use(utils.AuthMiddleware()).GET("/", authcontrollers.GetUser)
-		authGroup.Use(utils.AuthMiddleware()).PATCH("/", authcontrollers.EditUser)
-		authGroup.Use(utils.AuthMiddleware()).DELETE("/", authcontrollers.DeleteUser)
+		// Protected routes that require authentication, the routes above are registered
+		// before the middleware is added and don't run it
+		authGroup.Use(authMiddleware())
+		authGroup.GET("/", limit, utils.RequireScope(utils.ScopeUserRead), authcontrollers.GetUser)
+		authGroup.PATCH("/", limit, utils.RequireSession(), authcontrollers.EditUser)
+		authGroup.DELETE("/", limit, utils.RequireSession(), authcontrollers.DeleteUser)
+		authGroup.POST("/logout", limit, utils.RequireSession(), authcontrollers.Logout)
+		authGroup.POST("/logout-all", limit, utils.RequireSession(), authcontrollers.RevokeAllSessions)
+		authGroup.POST("/resend-verification", limit, utils.RequireSession(), authcontrollers.ResendVerificationEmail)
+		authGroup.POST("/mfa/enable", limit, utils.RequireSession(), authcontrollers.EnableMFA)
+		authGroup.POST("/mfa/verify", limit, utils.RequireSession(), authcontrollers.VerifyMFA)
+		authGroup.POST("/mfa/disable", limit, utils.RequireSession(), authcontrollers.DisableMFA)
+		// API keys can't be used to manage API keys
+		authGroup.POST("/api-keys", limit, utils.RequireSession(), authcontrollers.CreateAPIKey)
+		authGroup.GET("/api-keys", limit, utils.RequireSession(), authcontrollers.ListAPIKeys)
+		authGroup.DELETE("/api-keys/:id", limit, utils.RequireSession(), authcontrollers.RevokeAPIKey)
 	}
 }
@@ -0,0 +1,13 @@
+package routes
+
+import (
+	healthcontrollers "api-gateway/controllers/health-controllers"
+
+	"github.com/gin-gonic/gin"
+)
+
+// HealthRoutes defines the liveness and readiness probes, they don't require authentication
+func HealthRoutes(r *gin.Engine) {
+	r.GET("/healthz", healthcontrollers.Healthz)
+	r.GET("/readyz", healthcontrollers.Readyz)
+}
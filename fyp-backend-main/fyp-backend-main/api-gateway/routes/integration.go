@@ -10,14 +10,17 @@ import (
 
 // IntegrationRoutes defines routes related to integrations operations
 func IntegrationRoutes(r *gin.Engine) {
+	// Counted per user, API key or client IP
+	limit := utils.RateLimit(utils.RateLimitIntegration)
 	integrationGroup := r.Group("/integration")
 	{
 		// Protected routes that require authentication
-		integrationGroup.Use(utils.AuthMiddleware()).POST("/", integrationcontrollers.CreateIntegration)
-		integrationGroup.Use(utils.AuthMiddleware()).DELETE("/:id", integrationcontrollers.DeleteIntegration)
-		integrationGroup.Use(utils.AuthMiddleware()).PATCH("/:id", integrationcontrollers.UpdateIntegration)
-		integrationGroup.Use(utils.AuthMiddleware()).GET("/user", integrationcontrollers.GetUserIntegrations)
-		integrationGroup.Use(utils.AuthMiddleware()).GET("/search", integrationcontrollers.SearchIntegration)
-		integrationGroup.Use(utils.AuthMiddleware()).GET("/community", integrationcontrollers.GetPaginatedCommunityIntegrations)
+		integrationGroup.Use(authMiddleware())
+		integrationGroup.POST("/", limit, utils.RequireScope(utils.ScopeIntegrationWrite), integrationcontrollers.CreateIntegration)
+		integrationGroup.DELETE("/:id", limit, utils.RequireScope(utils.ScopeIntegrationWrite), integrationcontrollers.DeleteIntegration)
+		integrationGroup.PATCH("/:id", limit, utils.RequireScope(utils.ScopeIntegrationWrite), integrationcontrollers.UpdateIntegration)
+		integrationGroup.GET("/user", limit, utils.RequireScope(utils.ScopeIntegrationRead), integrationcontrollers.GetUserIntegrations)
+		integrationGroup.GET("/search", limit, utils.RequireScope(utils.ScopeIntegrationRead), integrationcontrollers.SearchIntegration)
+		integrationGroup.GET("/community", limit, utils.RequireScope(utils.ScopeIntegrationRead), integrationcontrollers.GetPaginatedCommunityIntegrations)
 	}
 }
@@ -0,0 +1,9 @@
+package routes
+
+import (
+	"api-gateway/utils"
+)
+
+// authMiddleware authenticates the protected routes, each group adds it once with Use
+// before registering them. Replaced in tests to count how often it runs.
+var authMiddleware = utils.AuthMiddleware
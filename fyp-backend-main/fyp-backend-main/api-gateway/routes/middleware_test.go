@@ -0,0 +1,79 @@
+package routes
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// publicRoutes are served without authentication
+var publicRoutes = map[string]bool{
+	"GET /healthz":               true,
+	"GET /readyz":                true,
+	"GET /.well-known/jwks.json": true,
+	"POST /auth/login":           true,
+	"POST /auth/register":        true,
+	"POST /auth/refresh":         true,
+	"POST /auth/social":          true,
+	"POST /auth/request-reset":   true,
+	"POST /auth/confirm-reset":   true,
+	"POST /auth/verify-email":    true,
+	"POST /auth/mfa/login":       true,
+	"OPTIONS /docs/:bucket/tus":  true,
+	"GET /blobs/:bucket/*key":    true,
+	"PUT /blobs/:bucket/*key":    true,
+}
+
+func TestAuthMiddlewareRunsOncePerRequest(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	runs := 0
+	defaultAuthMiddleware := authMiddleware
+	authMiddleware = func() gin.HandlerFunc {
+		return func(c *gin.Context) {
+			runs++
+			// Stop before the controllers, they need a server
+			c.AbortWithStatus(http.StatusNoContent)
+		}
+	}
+	t.Cleanup(func() { authMiddleware = defaultAuthMiddleware })
+
+	r := gin.New()
+	// Public controllers panic without a server, that's fine as long as they aren't authenticated
+	r.Use(gin.RecoveryWithWriter(io.Discard))
+	HealthRoutes(r)
+	AuthRoutes(r)
+	UploadRoutes(r)
+	IntegrationRoutes(r)
+	ProjectRoutes(r)
+	WorkflowRoutes(r)
+
+	for _, route := range r.Routes() {
+		name := route.Method + " " + route.Path
+		t.Run(name, func(t *testing.T) {
+			runs = 0
+			r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(route.Method, examplePath(route.Path), nil))
+			want := 1
+			if publicRoutes[name] {
+				want = 0
+			}
+			if runs != want {
+				t.Fatalf("AuthMiddleware ran %d times, want %d", runs, want)
+			}
+		})
+	}
+}
+
+// examplePath fills the parameters of a route pattern
+func examplePath(pattern string) string {
+	segments := strings.Split(pattern, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			segments[i] = "x"
+		}
+	}
+	return strings.Join(segments, "/")
+}
@@ -9,14 +9,17 @@ import (
 
 // ProjectRoutes defines routes related to project operations
 func ProjectRoutes(r *gin.Engine) {
+	// Projects are served by the workflow service and share its limit
+	limit := utils.RateLimit(utils.RateLimitWorkflow)
 	projectGroup := r.Group("/project")
 	{
 		// Protected routes that require authentication
-		projectGroup.Use(utils.AuthMiddleware()).POST("/", projectcontrollers.CreateProject)
-		projectGroup.Use(utils.AuthMiddleware()).DELETE("/:id", projectcontrollers.DeleteProject)
-		projectGroup.Use(utils.AuthMiddleware()).PATCH("/:id", projectcontrollers.UpdateProject)
-		projectGroup.Use(utils.AuthMiddleware()).GET("/:id", projectcontrollers.GetProjectById)
-		projectGroup.Use(utils.AuthMiddleware()).GET("/user", projectcontrollers.GetUserProjects)
+		projectGroup.Use(authMiddleware())
+		projectGroup.POST("/", limit, utils.RequireScope(utils.ScopeProjectWrite), projectcontrollers.CreateProject)
+		projectGroup.DELETE("/:id", limit, utils.RequireScope(utils.ScopeProjectWrite), projectcontrollers.DeleteProject)
+		projectGroup.PATCH("/:id", limit, utils.RequireScope(utils.ScopeProjectWrite), projectcontrollers.UpdateProject)
+		projectGroup.GET("/:id", limit, utils.RequireScope(utils.ScopeProjectRead), projectcontrollers.GetProjectById)
+		projectGroup.GET("/user", limit, utils.RequireScope(utils.ScopeProjectRead), projectcontrollers.GetUserProjects)
 
 	}
 }
@@ -0,0 +1,63 @@
+package routes
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"api-gateway/config"
+	auth_service "api-gateway/proto/generated/github.com/multiagentai/backend/auth-service"
+	"api-gateway/utils"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc"
+)
+
+// apiKeyAuthClient resolves every API key to the same key of user-1
+type apiKeyAuthClient struct {
+	auth_service.AuthServiceClient
+	lookups int
+}
+
+func (a *apiKeyAuthClient) ResolveAPIKey(ctx context.Context, in *auth_service.ResolveAPIKeyRequest, opts ...grpc.CallOption) (*auth_service.ResolveAPIKeyResponse, error) {
+	a.lookups++
+	return &auth_service.ResolveAPIKeyResponse{UserId: "user-1", KeyId: "key-1", Scopes: []string{utils.ScopeWorkflowRead}}, nil
+}
+
+func TestAPIKeyRequestUsesOneRateLimitToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	client := &apiKeyAuthClient{}
+	defaultAPIKeys, defaultRateLimits := utils.APIKeys, utils.RateLimits
+	utils.APIKeys = utils.NewAPIKeyResolver(client)
+	// Redis isn't connected, so the requests are counted in process
+	utils.RateLimits = utils.NewRateLimiter(map[string]config.RateLimit{
+		utils.RateLimitWorkflow: {Limit: 10, Window: time.Minute},
+		utils.RateLimitAPIKey:   {Limit: 10, Window: time.Minute},
+	})
+	t.Cleanup(func() { utils.APIKeys, utils.RateLimits = defaultAPIKeys, defaultRateLimits })
+
+	r := gin.New()
+	// The controller panics without a server, the middleware before it has run by then
+	r.Use(gin.RecoveryWithWriter(io.Discard))
+	WorkflowRoutes(r)
+
+	// The route registered last would run every middleware added before it
+	req := httptest.NewRequest(http.MethodGet, "/workflow/search", nil)
+	req.Header.Set("X-API-Key", "pat_secret")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	if client.lookups != 1 {
+		t.Fatalf("API key resolved %d times, want 1", client.lookups)
+	}
+	// Counting one more request shows how many the request used
+	ctx := context.Background()
+	if got := utils.RateLimits.Allow(ctx, utils.RateLimitWorkflow, "key:key-1").Remaining; got != 8 {
+		t.Fatalf("workflow requests remaining = %d, want 8", got)
+	}
+	if got := utils.RateLimits.Allow(ctx, utils.RateLimitAPIKey, "ip:192.0.2.1").Remaining; got != 8 {
+		t.Fatalf("API key lookups remaining = %d, want 8", got)
+	}
+}
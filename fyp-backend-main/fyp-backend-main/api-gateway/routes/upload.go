@@ -9,10 +9,36 @@ import (
 
 // UploadRoutes defines routes related to auth operations
 func UploadRoutes(r *gin.Engine) {
+	// Counted per user, API key or client IP
+	limit := utils.RateLimit(utils.RateLimitUpload)
+	// :bucket names a namespace, or its bucket in URLs handed out before namespaces
 	uploadGroup := r.Group("/docs")
 	{
 		// Protected routes that require authentication
-		uploadGroup.Use(utils.AuthMiddleware()).POST("/:bucket", uploadcontrollers.UploadFile)
-		uploadGroup.Use(utils.AuthMiddleware()).GET("/:bucket/:file", uploadcontrollers.GetFile)
+		uploadGroup.Use(authMiddleware())
+		uploadGroup.POST("/:bucket", limit, utils.RequireScope(utils.ScopeDocsWrite), uploadcontrollers.UploadFile)
+		uploadGroup.GET("/:bucket/:file", limit, utils.RequireScope(utils.ScopeDocsRead), uploadcontrollers.GetFile)
+		uploadGroup.PATCH("/:bucket/:file", limit, utils.RequireScope(utils.ScopeDocsWrite), uploadcontrollers.UpdateFileAccess)
+		uploadGroup.GET("/:bucket/:file/download-url", limit, utils.RequireScope(utils.ScopeDocsRead), uploadcontrollers.GetDownloadURL)
+
+		// Presigned uploads straight to storage, completed once the file is there
+		uploadGroup.POST("/:bucket/uploads", limit, utils.RequireScope(utils.ScopeDocsWrite), uploadcontrollers.CreateUpload)
+		uploadGroup.POST("/:bucket/uploads/:file/complete", limit, utils.RequireScope(utils.ScopeDocsWrite), uploadcontrollers.CompleteUpload)
+
+		// Resumable uploads following the tus protocol, stored in parts
+		uploadGroup.POST("/:bucket/tus", limit, utils.RequireScope(utils.ScopeDocsWrite), uploadcontrollers.CreateResumableUpload)
+		uploadGroup.HEAD("/:bucket/tus/:id", limit, utils.RequireScope(utils.ScopeDocsWrite), uploadcontrollers.GetResumableUpload)
+		uploadGroup.PATCH("/:bucket/tus/:id", limit, utils.RequireScope(utils.ScopeDocsWrite), uploadcontrollers.WriteResumableUpload)
+		uploadGroup.DELETE("/:bucket/tus/:id", limit, utils.RequireScope(utils.ScopeDocsWrite), uploadcontrollers.TerminateResumableUpload)
+	}
+
+	// tus clients discover the protocol without credentials
+	r.OPTIONS("/docs/:bucket/tus", uploadcontrollers.ResumableUploadOptions)
+
+	// Presigned URLs of the local and memory storage drivers, authorized by their signature
+	blobGroup := r.Group("/blobs")
+	{
+		blobGroup.GET("/:bucket/*key", limit, uploadcontrollers.GetBlob)
+		blobGroup.PUT("/:bucket/*key", limit, uploadcontrollers.PutBlob)
 	}
 }
@@ -9,14 +9,18 @@ import (
 
 // WorkflowRoutes defines routes related to workflow operations
 func WorkflowRoutes(r *gin.Engine) {
+	// Counted per user, API key or client IP
+	limit := utils.RateLimit(utils.RateLimitWorkflow)
 	workflowGroup := r.Group("/workflow")
 	{
 		// Protected routes that require authentication
-		workflowGroup.Use(utils.AuthMiddleware()).POST("/", workflowcontrollers.CreateWorkflow)
-		workflowGroup.Use(utils.AuthMiddleware()).GET("/:id", workflowcontrollers.GetWorkflowById)
-		workflowGroup.Use(utils.AuthMiddleware()).DELETE("/:id", workflowcontrollers.DeleteWorkflow)
-		workflowGroup.Use(utils.AuthMiddleware()).PATCH("/:id", workflowcontrollers.UpdateWorkflow)
-		workflowGroup.Use(utils.AuthMiddleware()).GET("/user", workflowcontrollers.GetUserWorkflows)
-		workflowGroup.Use(utils.AuthMiddleware()).GET("/community", workflowcontrollers.GetPaginatedCommunityWorkflows)
+		workflowGroup.Use(authMiddleware())
+		workflowGroup.POST("/", limit, utils.RequireScope(utils.ScopeWorkflowWrite), workflowcontrollers.CreateWorkflow)
+		workflowGroup.GET("/:id", limit, utils.RequireScope(utils.ScopeWorkflowRead), workflowcontrollers.GetWorkflowById)
+		workflowGroup.DELETE("/:id", limit, utils.RequireScope(utils.ScopeWorkflowWrite), workflowcontrollers.DeleteWorkflow)
+		workflowGroup.PATCH("/:id", limit, utils.RequireScope(utils.ScopeWorkflowWrite), workflowcontrollers.UpdateWorkflow)
+		workflowGroup.GET("/user", limit, utils.RequireScope(utils.ScopeWorkflowRead), workflowcontrollers.GetUserWorkflows)
+		workflowGroup.GET("/community", limit, utils.RequireScope(utils.ScopeWorkflowRead), workflowcontrollers.GetPaginatedCommunityWorkflows)
+		workflowGroup.GET("/search", limit, utils.RequireScope(utils.ScopeWorkflowRead), workflowcontrollers.SearchWorkflow)
 	}
 }
@@ -0,0 +1,57 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"path"
+
+	"api-gateway/storage"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// CheckAttachment makes sure the caller may attach the file fileURL names to an integration
+// or workflow of the namespace, as attachments make a file readable. A file in the registry
+// can only be attached by its owner, and one uploaded before the registry only by a user
+// who had attached it already. URLs naming no uploaded file are left alone.
+func (s *Server) CheckAttachment(ctx context.Context, namespace, userID, fileURL string) error {
+	u, err := url.Parse(fileURL)
+	if fileURL == "" || err != nil {
+		return nil
+	}
+	key := path.Base(u.Path)
+	_, bucketName, ok := s.Namespaces.Resolve(namespace)
+	if key == "/" || key == "." || !ok {
+		return nil
+	}
+
+	upload, err := s.Uploads.Lookup(ctx, bucketName, key)
+	switch {
+	case err == nil:
+		if upload.Owner != userID {
+			return status.Error(codes.PermissionDenied, "only the user who uploaded a file can attach it")
+		}
+		return nil
+	case errors.Is(err, storage.ErrInvalidKey), errors.Is(err, storage.ErrBucketNotFound):
+		return nil
+	case !errors.Is(err, storage.ErrNotFound):
+		return err
+	}
+
+	// Files uploaded before the registry have no owner
+	if _, err := s.Storage.Head(ctx, bucketName, key); errors.Is(err, storage.ErrNotFound) || errors.Is(err, storage.ErrInvalidKey) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	attachment, err := s.FileAttachment(ctx, namespace, key, "")
+	if err != nil {
+		return err
+	}
+	if !attachment.Owned {
+		return status.Error(codes.PermissionDenied, "only the user who uploaded a file can attach it")
+	}
+	return nil
+}
@@ -0,0 +1,12 @@
+package server
+
+import "errors"
+
+// Close closes the connections to the backend services
+func (s *Server) Close() error {
+	var errs []error
+	for _, conn := range s.conns {
+		errs = append(errs, conn.Close())
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,47 @@
+package server
+
+import (
+	"context"
+	"time"
+
+	integration_service "api-gateway/proto/generated/github.com/multiagentai/backend/integration-service"
+	workflow_service "api-gateway/proto/generated/github.com/multiagentai/backend/workflow-service"
+	"api-gateway/storage"
+)
+
+// FileAttachment describes the integrations or workflows an uploaded file is attached to.
+type FileAttachment struct {
+	// Public is set when a public one is, created by the owner passed to FileAttachment if any
+	Public bool
+	// Owned is set when one of the caller's is, the oldest created at OwnedSince
+	Owned      bool
+	OwnedSince time.Time
+}
+
+// FileAttachment asks the service behind the namespace what the file is attached to. ctx
+// carries the signed in user, and owner limits Public to what they created. Assets
+// aren't attached to anything.
+func (s *Server) FileAttachment(ctx context.Context, namespace, fileName, owner string) (FileAttachment, error) {
+	var attachment FileAttachment
+	switch namespace {
+	case storage.NamespaceIntegrations:
+		res, err := s.IntegrationService.GetFileAttachment(ctx, &integration_service.GetFileAttachmentRequest{FileName: fileName, Owner: owner})
+		if err != nil {
+			return attachment, err
+		}
+		attachment = FileAttachment{Public: res.Public, Owned: res.Owned}
+		if res.OwnedSince != nil {
+			attachment.OwnedSince = res.OwnedSince.AsTime()
+		}
+	case storage.NamespaceWorkflows:
+		res, err := s.WorkflowService.GetFileAttachment(ctx, &workflow_service.GetFileAttachmentRequest{FileName: fileName, Owner: owner})
+		if err != nil {
+			return attachment, err
+		}
+		attachment = FileAttachment{Public: res.Public, Owned: res.Owned}
+		if res.OwnedSince != nil {
+			attachment.OwnedSince = res.OwnedSince.AsTime()
+		}
+	}
+	return attachment, nil
+}
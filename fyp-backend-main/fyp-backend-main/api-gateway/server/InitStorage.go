@@ -2,43 +2,87 @@ package server
 
 import (
 	"context"
-	"log"
-	"os"
+	"log/slog"
+	"strings"
+	"time"
 
-	"github.com/aws/aws-sdk-go-v2/aws"
-	"github.com/aws/aws-sdk-go-v2/config"
-	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"api-gateway/config"
+	"api-gateway/storage"
+	"api-gateway/utils"
+	"shared/logging"
 )
 
-func (s *Server) InitStorage() {
-	// Load AWS configuration
-	cfg, err := config.LoadDefaultConfig(context.TODO(),
-		config.WithRegion(os.Getenv("AWS_REGION")),
-		config.WithCredentialsProvider(aws.NewCredentialsCache(aws.CredentialsProviderFunc(func(ctx context.Context) (aws.Credentials, error) {
-			return aws.Credentials{
-				AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
-				SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
-			}, nil
-		}))),
-	)
-	if err != nil {
-		log.Fatalf("Failed to load AWS configuration: %v", err)
+// InitStorage creates the store uploaded files are kept in, picked by STORAGE_DRIVER.
+// Connecting to S3 may take up to STARTUP_MAX_WAIT.
+func (s *Server) InitStorage(ctx context.Context, c *config.Config) {
+	ctx, cancel := context.WithTimeout(ctx, c.StartupMaxWait)
+	defer cancel()
+
+	// The local and memory drivers hand out URLs served by the gateway itself
+	var urls *storage.URLSigner
+	if c.StorageDriver != "s3" {
+		var err error
+		urls, err = storage.NewURLSigner(strings.TrimSuffix(c.GatewayAddress, "/")+"/blobs", c.StorageSigningKey)
+		if err != nil {
+			logging.Fatal("Failed to create the storage URL signer", "error", err)
+		}
 	}
 
-	// Create S3 client
-	s3Client := s3.NewFromConfig(cfg)
+	// Only the buckets of the namespaces can be reached through /docs
+	buckets, err := c.StorageNamespaceMap()
+	if err != nil {
+		logging.Fatal("Failed to read the storage namespaces", "error", err)
+	}
+	namespaces, err := storage.NewNamespaces(buckets)
+	if err != nil {
+		logging.Fatal("Failed to read the storage namespaces", "error", err)
+	}
 
-	// Check if the connection works by listing the buckets
-	result, err := s3Client.ListBuckets(context.TODO(), &s3.ListBucketsInput{})
+	store, err := storage.NewBlobStore(ctx, storage.Options{
+		Driver: c.StorageDriver,
+		S3: storage.S3Config{
+			Region:          c.AWSRegion,
+			AccessKeyID:     c.AWSAccessKeyID,
+			SecretAccessKey: c.AWSSecretAccessKey,
+			Endpoint:        c.S3Endpoint,
+			ForcePathStyle:  c.S3ForcePathStyle,
+		},
+		LocalDir: c.StorageLocalDir,
+		URLs:     urls,
+	})
 	if err != nil {
-		log.Fatalf("Failed to list buckets: %v", err)
+		logging.Fatal("Failed to create the file storage", "driver", c.StorageDriver, "error", err)
 	}
 
-	log.Println("Connected to AWS S3 successfully! Buckets found:")
-	for _, bucket := range result.Buckets {
-		log.Printf(" - %s created on %s\n", *bucket.Name, bucket.CreationDate)
+	// Check if the connection works by listing the buckets, /readyz keeps reporting it after startup
+	if s3Store, ok := store.(*storage.S3Store); ok {
+		buckets, err := s3Store.Buckets(ctx)
+		if err != nil {
+			slog.Warn("Failed to list buckets", "error", err)
+		} else {
+			slog.Info("Connected to AWS S3 successfully!", "buckets", buckets)
+		}
+	} else {
+		slog.Info("Keeping uploaded files without S3", "driver", c.StorageDriver)
 	}
 
-	// Assign the S3 client to the global variable
-	s.S3Client = s3Client
+	s.Storage = store
+	s.StorageURLs = urls
+	s.Namespaces = namespaces
+	s.Uploads = storage.NewRegistry(store)
+
+	// Resumable uploads register their files like uploads sent in one request
+	resumable, err := storage.NewResumableUploads(store, int64(c.ResumablePartSize))
+	if err != nil {
+		logging.Fatal("Failed to create the resumable uploads", "error", err)
+	}
+	resumable.OnFinish = func(ctx context.Context, upload storage.Upload) error {
+		_, err := utils.RecordUpload(ctx, s.Uploads, upload)
+		return err
+	}
+	// The sweeper holds the lock of the requests writing an upload while removing it
+	resumable.Lock = func(ctx context.Context, u *storage.ResumableUpload) (func(), bool, error) {
+		return utils.AcquireLock(ctx, u.LockName(), time.Minute)
+	}
+	s.ResumableUploads = resumable
 }
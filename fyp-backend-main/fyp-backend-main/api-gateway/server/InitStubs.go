@@ -1,37 +1,48 @@
 package server
 
 import (
+	"context"
+
+	"api-gateway/config"
 	auth_service "api-gateway/proto/generated/github.com/multiagentai/backend/auth-service"
 	integration_service "api-gateway/proto/generated/github.com/multiagentai/backend/integration-service"
 	workflow_service "api-gateway/proto/generated/github.com/multiagentai/backend/workflow-service"
 	"api-gateway/server/stubs"
-	"log"
+	"shared/logging"
+
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 )
 
 // InitStubs initializes the gRPC stubs for the services
-func (s *Server) InitServer() {
+func (s *Server) InitServer(ctx context.Context, cfg *config.Config) {
 	//AuthService
-	auth, err := stubs.AuthConnection()
+	auth, err := stubs.AuthConnection(cfg)
 	if err != nil {
-		log.Fatal(err)
+		logging.Fatal("Failed to create auth service client", "error", err)
 	}
 	authService := auth_service.NewAuthServiceClient(auth)
 	s.AuthService = authService
+	s.AuthHealth = healthpb.NewHealthClient(auth)
+	s.conns = append(s.conns, auth)
 	// IntegrationService
-	integration, err := stubs.IntegrationConnection()
+	integration, err := stubs.IntegrationConnection(cfg)
 	if err != nil {
-		log.Fatal(err)
+		logging.Fatal("Failed to create integration service client", "error", err)
 	}
 	integrationService := integration_service.NewIntegrationServiceClient(integration)
 	s.IntegrationService = integrationService
+	s.IntegrationHealth = healthpb.NewHealthClient(integration)
+	s.conns = append(s.conns, integration)
 
 	//Workflow Service
-	workflow, err := stubs.WorkflowConnection()
+	workflow, err := stubs.WorkflowConnection(cfg)
 	if err != nil {
-		log.Fatal(err)
+		logging.Fatal("Failed to create workflow service client", "error", err)
 	}
 	workflowService := workflow_service.NewWorkflowServiceClient(workflow)
 	s.WorkflowService = workflowService
+	s.WorkflowHealth = healthpb.NewHealthClient(workflow)
+	s.conns = append(s.conns, workflow)
 	//MinioClient
-	s.InitStorage()
+	s.InitStorage(ctx, cfg)
 }
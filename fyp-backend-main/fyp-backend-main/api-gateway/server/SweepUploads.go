@@ -0,0 +1,66 @@
+package server
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"api-gateway/metrics"
+	"api-gateway/utils"
+)
+
+// pendingSweepDelay leaves CompleteUpload time to record an upload it accepted just
+// before the upload expired
+const pendingSweepDelay = time.Minute
+
+// sweepLockName is the lock held by the instance sweeping uploads
+const sweepLockName = "sweep:uploads"
+
+// SweepUploads removes the expired resumable uploads of every namespace and the parts
+// they left, every interval until ctx is done. Multipart uploads no resumable upload
+// refers to are aborted once they're older than expiry. Presigned uploads that weren't
+// completed in time are removed with their files. One gateway instance sweeps at a time.
+func (s *Server) SweepUploads(ctx context.Context, interval, expiry time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		s.sweepUploads(ctx, interval, expiry)
+	}
+}
+
+// sweepUploads sweeps every namespace unless another instance is sweeping, its lock
+// expires by the next tick if it stops half way
+func (s *Server) sweepUploads(ctx context.Context, interval, expiry time.Duration) {
+	release, acquired, err := utils.AcquireLock(ctx, sweepLockName, interval)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to lock upload sweep", "error", err)
+		return
+	}
+	if !acquired {
+		return
+	}
+	defer release()
+
+	for namespace, bucket := range s.Namespaces.Buckets() {
+		removed, err := s.ResumableUploads.Sweep(ctx, bucket, time.Now(), expiry)
+		s.logSweep(ctx, namespace, removed, err)
+		removed, err = s.Uploads.SweepPending(ctx, bucket, time.Now().Add(-pendingSweepDelay))
+		s.logSweep(ctx, namespace, removed, err)
+	}
+}
+
+// logSweep counts and logs what a sweep of the namespace removed
+func (s *Server) logSweep(ctx context.Context, namespace string, removed int, err error) {
+	if removed > 0 {
+		metrics.AbandonedUploads.Add(float64(removed))
+		slog.InfoContext(ctx, "Removed abandoned uploads", "namespace", namespace, "count", removed)
+	}
+	if err != nil && ctx.Err() == nil {
+		slog.ErrorContext(ctx, "Failed to remove abandoned uploads", "namespace", namespace, "error", err)
+	}
+}
@@ -4,13 +4,31 @@ import (
 	auth_service "api-gateway/proto/generated/github.com/multiagentai/backend/auth-service"
 	integration_service "api-gateway/proto/generated/github.com/multiagentai/backend/integration-service"
 	workflow_service "api-gateway/proto/generated/github.com/multiagentai/backend/workflow-service"
+	"api-gateway/storage"
 
-	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"google.golang.org/grpc"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 )
 
 type Server struct {
 	AuthService        auth_service.AuthServiceClient               //AuthStub
 	IntegrationService integration_service.IntegrationServiceClient //IntegrationStub
 	WorkflowService    workflow_service.WorkflowServiceClient       //WorkflowStub
-	S3Client           *s3.Client
+	Storage            storage.BlobStore
+
+	// Signs the URLs served under /blobs, for the local and memory storage drivers
+	StorageURLs *storage.URLSigner
+	// Buckets files are uploaded to, and who uploaded them
+	Namespaces *storage.Namespaces
+	Uploads    *storage.Registry
+	// Files uploaded in chunks through the tus endpoint
+	ResumableUploads *storage.ResumableUploads
+
+	// Health clients on the same connections as the stubs, used by /readyz
+	AuthHealth        healthpb.HealthClient
+	IntegrationHealth healthpb.HealthClient
+	WorkflowHealth    healthpb.HealthClient
+
+	// conns are closed on shutdown
+	conns []*grpc.ClientConn
 }
@@ -1,12 +1,36 @@
 package stubs
 
 import (
+	"api-gateway/config"
+	auth_service "api-gateway/proto/generated/github.com/multiagentai/backend/auth-service"
+	"api-gateway/utils"
+
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc/filters"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/keepalive"
 )
 
-func AuthConnection() (*grpc.ClientConn, error) {
-	conn, err := grpc.NewClient("auth-service:50000", grpc.WithTransportCredentials(insecure.NewCredentials()), grpc.WithBlock())
+// AuthConnection creates the connection lazily, so the gateway starts while the
+// service is down and /readyz reports it instead.
+func AuthConnection(cfg *config.Config) (*grpc.ClientConn, error) {
+	creds, err := utils.ClientCredentials(cfg)
+	if err != nil {
+		return nil, err
+	}
+	breaker := utils.NewCircuitBreaker("auth-service", cfg.GRPCBreakerFailures, cfg.GRPCBreakerCooldown)
+	conn, err := grpc.NewClient(cfg.AuthServiceAddr,
+		creds,
+		// Spread calls over the replicas and retry idempotent ones
+		grpc.WithDefaultServiceConfig(ServiceConfig(&auth_service.AuthService_ServiceDesc, cfg.GRPCRetryMaxAttempts)),
+		// Notice connections to replicas that went away without closing them
+		grpc.WithKeepaliveParams(keepalive.ClientParameters{Time: cfg.GRPCKeepaliveTime, Timeout: cfg.GRPCKeepaliveTimeout, PermitWithoutStream: true}),
+		// Trace every call except readiness checks and send the trace context in the metadata
+		grpc.WithStatsHandler(otelgrpc.NewClientHandler(otelgrpc.WithFilter(filters.Not(filters.HealthCheck())))),
+		// Fail fast while the service is unavailable, every call carries an assertion of who it is made for
+		grpc.WithChainUnaryInterceptor(breaker.UnaryClientInterceptor(), utils.InternalAuthUnaryInterceptor("auth-service")),
+		grpc.WithChainStreamInterceptor(utils.InternalAuthStreamInterceptor("auth-service")),
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -1,12 +1,36 @@
 package stubs
 
 import (
+	"api-gateway/config"
+	integration_service "api-gateway/proto/generated/github.com/multiagentai/backend/integration-service"
+	"api-gateway/utils"
+
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc/filters"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/keepalive"
 )
 
-func IntegrationConnection() (*grpc.ClientConn, error) {
-	conn, err := grpc.NewClient("integration-service:50051", grpc.WithTransportCredentials(insecure.NewCredentials()), grpc.WithBlock())
+// IntegrationConnection creates the connection lazily, so the gateway starts while the
+// service is down and /readyz reports it instead.
+func IntegrationConnection(cfg *config.Config) (*grpc.ClientConn, error) {
+	creds, err := utils.ClientCredentials(cfg)
+	if err != nil {
+		return nil, err
+	}
+	breaker := utils.NewCircuitBreaker("integration-service", cfg.GRPCBreakerFailures, cfg.GRPCBreakerCooldown)
+	conn, err := grpc.NewClient(cfg.IntegrationServiceAddr,
+		creds,
+		// Spread calls over the replicas and retry idempotent ones
+		grpc.WithDefaultServiceConfig(ServiceConfig(&integration_service.IntegrationService_ServiceDesc, cfg.GRPCRetryMaxAttempts)),
+		// Notice connections to replicas that went away without closing them
+		grpc.WithKeepaliveParams(keepalive.ClientParameters{Time: cfg.GRPCKeepaliveTime, Timeout: cfg.GRPCKeepaliveTimeout, PermitWithoutStream: true}),
+		// Trace every call except readiness checks and send the trace context in the metadata
+		grpc.WithStatsHandler(otelgrpc.NewClientHandler(otelgrpc.WithFilter(filters.Not(filters.HealthCheck())))),
+		// Fail fast while the service is unavailable, every call carries an assertion of who it is made for
+		grpc.WithChainUnaryInterceptor(breaker.UnaryClientInterceptor(), utils.InternalAuthUnaryInterceptor("integration-service")),
+		grpc.WithChainStreamInterceptor(utils.InternalAuthStreamInterceptor("integration-service")),
+	)
 	if err != nil {
 		return nil, err
 	}
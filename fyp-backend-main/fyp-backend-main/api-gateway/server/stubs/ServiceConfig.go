@@ -0,0 +1,78 @@
+package stubs
+
+import (
+	"encoding/json"
+	"strings"
+
+	"google.golang.org/grpc"
+	// Registers the client side health checking used by healthCheckConfig
+	_ "google.golang.org/grpc/health"
+)
+
+// Prefixes of the methods that only read and can be retried safely
+var idempotentPrefixes = []string{"Get", "List", "Search"}
+
+type serviceConfig struct {
+	LoadBalancingConfig []map[string]struct{} `json:"loadBalancingConfig"`
+	HealthCheckConfig   healthCheckConfig     `json:"healthCheckConfig"`
+	MethodConfig        []methodConfig        `json:"methodConfig,omitempty"`
+}
+
+type healthCheckConfig struct {
+	ServiceName string `json:"serviceName"`
+}
+
+type methodConfig struct {
+	Name        []methodName `json:"name"`
+	RetryPolicy retryPolicy  `json:"retryPolicy"`
+}
+
+type methodName struct {
+	Service string `json:"service"`
+	Method  string `json:"method"`
+}
+
+type retryPolicy struct {
+	MaxAttempts          int      `json:"maxAttempts"`
+	InitialBackoff       string   `json:"initialBackoff"`
+	MaxBackoff           string   `json:"maxBackoff"`
+	BackoffMultiplier    float64  `json:"backoffMultiplier"`
+	RetryableStatusCodes []string `json:"retryableStatusCodes"`
+}
+
+// ServiceConfig returns the gRPC service config of a backend. Calls are balanced round
+// robin over every address the service name resolves to, skipping replicas whose health
+// service doesn't report the service as serving. The idempotent methods of the service
+// are retried on UNAVAILABLE up to maxAttempts times, within the deadline of the call.
+func ServiceConfig(desc *grpc.ServiceDesc, maxAttempts int) string {
+	sc := serviceConfig{
+		LoadBalancingConfig: []map[string]struct{}{{"round_robin": {}}},
+		HealthCheckConfig:   healthCheckConfig{ServiceName: desc.ServiceName},
+	}
+
+	var names []methodName
+	for _, method := range desc.Methods {
+		for _, prefix := range idempotentPrefixes {
+			if strings.HasPrefix(method.MethodName, prefix) {
+				names = append(names, methodName{Service: desc.ServiceName, Method: method.MethodName})
+				break
+			}
+		}
+	}
+	if maxAttempts > 1 && len(names) > 0 {
+		sc.MethodConfig = []methodConfig{{
+			Name: names,
+			RetryPolicy: retryPolicy{
+				MaxAttempts:          maxAttempts,
+				InitialBackoff:       "0.1s",
+				MaxBackoff:           "1s",
+				BackoffMultiplier:    2,
+				RetryableStatusCodes: []string{"UNAVAILABLE"},
+			},
+		}}
+	}
+
+	// The config only holds strings and numbers, marshaling can't fail
+	out, _ := json.Marshal(sc)
+	return string(out)
+}
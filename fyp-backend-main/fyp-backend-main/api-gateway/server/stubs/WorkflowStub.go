@@ -1,12 +1,36 @@
 package stubs
 
 import (
+	"api-gateway/config"
+	workflow_service "api-gateway/proto/generated/github.com/multiagentai/backend/workflow-service"
+	"api-gateway/utils"
+
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc/filters"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/keepalive"
 )
 
-func WorkflowConnection() (*grpc.ClientConn, error) {
-	conn, err := grpc.NewClient("workflow-service:50002", grpc.WithTransportCredentials(insecure.NewCredentials()), grpc.WithBlock())
+// WorkflowConnection creates the connection lazily, so the gateway starts while the
+// service is down and /readyz reports it instead.
+func WorkflowConnection(cfg *config.Config) (*grpc.ClientConn, error) {
+	creds, err := utils.ClientCredentials(cfg)
+	if err != nil {
+		return nil, err
+	}
+	breaker := utils.NewCircuitBreaker("workflow-service", cfg.GRPCBreakerFailures, cfg.GRPCBreakerCooldown)
+	conn, err := grpc.NewClient(cfg.WorkflowServiceAddr,
+		creds,
+		// Spread calls over the replicas and retry idempotent ones
+		grpc.WithDefaultServiceConfig(ServiceConfig(&workflow_service.WorkflowService_ServiceDesc, cfg.GRPCRetryMaxAttempts)),
+		// Notice connections to replicas that went away without closing them
+		grpc.WithKeepaliveParams(keepalive.ClientParameters{Time: cfg.GRPCKeepaliveTime, Timeout: cfg.GRPCKeepaliveTimeout, PermitWithoutStream: true}),
+		// Trace every call except readiness checks and send the trace context in the metadata
+		grpc.WithStatsHandler(otelgrpc.NewClientHandler(otelgrpc.WithFilter(filters.Not(filters.HealthCheck())))),
+		// Fail fast while the service is unavailable, every call carries an assertion of who it is made for
+		grpc.WithChainUnaryInterceptor(breaker.UnaryClientInterceptor(), utils.InternalAuthUnaryInterceptor("workflow-service")),
+		grpc.WithChainStreamInterceptor(utils.InternalAuthStreamInterceptor("workflow-service")),
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,178 @@
+package storage
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Errors returned by every BlobStore, so callers don't depend on the backend
+var (
+	ErrNotFound       = errors.New("object not found")
+	ErrBucketNotFound = errors.New("bucket not found")
+	ErrInvalidRange   = errors.New("range not satisfiable")
+	ErrInvalidKey     = errors.New("invalid bucket or key")
+)
+
+// ObjectInfo describes a stored object.
+type ObjectInfo struct {
+	Bucket      string
+	Key         string
+	Size        int64
+	ContentType string
+	ETag        string
+	// ChecksumSHA256 is the base64 SHA-256 of the object, empty when the store doesn't know it
+	ChecksumSHA256 string
+	LastModified   time.Time
+}
+
+// Range selects part of an object, Length -1 reads to the end.
+type Range struct {
+	Offset int64
+	Length int64
+}
+
+// Object is the content of an object, or of the requested range of it. Close must be called.
+type Object struct {
+	io.ReadCloser
+	// Info describes the whole object, Size is the size of the full object
+	Info ObjectInfo
+	// Range is the part of the object being read, nil when it's read in full
+	Range *Range
+}
+
+// PutConditions scope a presigned upload URL, uploads that don't match them are rejected.
+type PutConditions struct {
+	ContentType string
+	// Size is the exact length of the body
+	Size int64
+	// ChecksumSHA256 is the base64 SHA-256 of the body
+	ChecksumSHA256 string
+}
+
+// PresignedPut is an upload URL and the headers the PUT request must send with it.
+type PresignedPut struct {
+	URL    string
+	Header http.Header
+}
+
+// MinPartSize is the smallest part of a multipart upload but the last, as S3 requires
+const MinPartSize = 5 << 20
+
+// Part is a stored part of a multipart upload.
+type Part struct {
+	Number int32  `json:"number"`
+	ETag   string `json:"etag"`
+	Size   int64  `json:"size"`
+}
+
+// MultipartUpload is a multipart upload that was neither completed nor aborted.
+type MultipartUpload struct {
+	Key       string
+	UploadID  string
+	Initiated time.Time
+}
+
+// BlobStore keeps uploaded files, objects are addressed by bucket and key.
+type BlobStore interface {
+	// Put stores the object, replacing any object with the same key. size is -1 when unknown.
+	Put(ctx context.Context, bucket, key string, body io.Reader, size int64, contentType string) (ObjectInfo, error)
+	// Get reads the object, or the range of it when rng isn't nil
+	Get(ctx context.Context, bucket, key string, rng *Range) (*Object, error)
+	Head(ctx context.Context, bucket, key string) (ObjectInfo, error)
+	// Delete removes the object, deleting a missing object isn't an error
+	Delete(ctx context.Context, bucket, key string) error
+	// List returns the objects of the bucket whose key starts with prefix
+	List(ctx context.Context, bucket, prefix string) ([]ObjectInfo, error)
+	// PresignGet returns a URL the object can be downloaded from without credentials until it expires
+	PresignGet(ctx context.Context, bucket, key string, expires time.Duration) (string, error)
+	// PresignPut returns a URL the object can be uploaded to until it expires, if the upload meets the conditions
+	PresignPut(ctx context.Context, bucket, key string, conditions PutConditions, expires time.Duration) (PresignedPut, error)
+	// CreateMultipart starts an object uploaded in parts, every part but the last must be at
+	// least MinPartSize. The parts are kept until the upload is completed or aborted.
+	CreateMultipart(ctx context.Context, bucket, key, contentType string) (string, error)
+	// UploadPart stores a part of the upload, parts are numbered from 1
+	UploadPart(ctx context.Context, bucket, key, uploadID string, number int32, body io.Reader, size int64) (Part, error)
+	// CompleteMultipart joins the parts into the object
+	CompleteMultipart(ctx context.Context, bucket, key, uploadID string, parts []Part) (ObjectInfo, error)
+	// AbortMultipart removes the parts, aborting a missing upload isn't an error
+	AbortMultipart(ctx context.Context, bucket, key, uploadID string) error
+	// ListMultipart returns the multipart uploads of the bucket that are still open
+	ListMultipart(ctx context.Context, bucket string) ([]MultipartUpload, error)
+	// Ping checks that the store can be reached, for /readyz
+	Ping(ctx context.Context) error
+}
+
+// Options configures the store picked by NewBlobStore.
+type Options struct {
+	Driver   string
+	S3       S3Config
+	LocalDir string
+	// URLs signs the URLs the local and memory stores hand out, they're served by the gateway
+	URLs *URLSigner
+}
+
+// NewBlobStore picks the store by driver:
+//
+//	s3      AWS S3 or an S3 compatible service such as MinIO (default)
+//	local   files in a directory, for running the stack offline
+//	memory  kept in memory and lost on restart, for local development and tests
+func NewBlobStore(ctx context.Context, options Options) (BlobStore, error) {
+	switch options.Driver {
+	case "", "s3":
+		return NewS3Store(ctx, options.S3)
+	case "local":
+		return NewLocalStore(options.LocalDir, options.URLs)
+	case "memory":
+		return NewMemoryStore(options.URLs), nil
+	default:
+		return nil, fmt.Errorf("unknown STORAGE_DRIVER %q", options.Driver)
+	}
+}
+
+// Bucket names as accepted by S3
+var validBucket = regexp.MustCompile(`^[a-z0-9][a-z0-9.-]{1,61}[a-z0-9]$`)
+
+// validateKey rejects names that could escape the bucket of the local store or that
+// S3 would store under a different key
+func validateKey(bucket, key string) error {
+	if !validBucket.MatchString(bucket) || strings.Contains(bucket, "..") {
+		return fmt.Errorf("%w: bucket %q", ErrInvalidKey, bucket)
+	}
+	if key == "" || len(key) > 1024 || strings.ContainsRune(key, 0) || path.Clean("/"+key) != "/"+key {
+		return fmt.Errorf("%w: key %q", ErrInvalidKey, key)
+	}
+	return nil
+}
+
+// validateRange checks rng against the size of the object and resolves a Length of -1
+func validateRange(rng *Range, size int64) (*Range, error) {
+	if rng == nil {
+		return nil, nil
+	}
+	if rng.Offset < 0 || rng.Offset >= size || rng.Length == 0 || rng.Length < -1 {
+		return nil, ErrInvalidRange
+	}
+	length := rng.Length
+	if length == -1 || rng.Offset+length > size {
+		length = size - rng.Offset
+	}
+	return &Range{Offset: rng.Offset, Length: length}, nil
+}
+
+// randomID returns the ID of a multipart upload of the local and memory stores
+func randomID() (string, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(id), nil
+}
@@ -0,0 +1,387 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// The stores every driver but S3 is tested against, S3 needs a live service
+var conformanceStores = []struct {
+	name string
+	open func(t *testing.T, urls *URLSigner) BlobStore
+}{
+	{"memory", func(t *testing.T, urls *URLSigner) BlobStore {
+		return NewMemoryStore(urls)
+	}},
+	{"local", func(t *testing.T, urls *URLSigner) BlobStore {
+		store, err := NewLocalStore(t.TempDir(), urls)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return store
+	}},
+}
+
+const conformanceBucket = "flomny-test"
+
+func TestBlobStoreConformance(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T, store BlobStore, urls *URLSigner)
+	}{
+		{"put and get", testPutGet},
+		{"range", testRange},
+		{"missing objects", testMissing},
+		{"invalid keys", testInvalidKeys},
+		{"list", testList},
+		{"delete", testDelete},
+		{"presigned URLs", testPresign},
+		{"multipart", testMultipart},
+		{"abort multipart", testAbortMultipart},
+	}
+	for _, store := range conformanceStores {
+		t.Run(store.name, func(t *testing.T) {
+			for _, tt := range tests {
+				t.Run(tt.name, func(t *testing.T) {
+					urls, err := NewURLSigner("http://gateway/blobs", testSigningKey)
+					if err != nil {
+						t.Fatal(err)
+					}
+					tt.run(t, store.open(t, urls), urls)
+				})
+			}
+		})
+	}
+}
+
+func testPutGet(t *testing.T, store BlobStore, _ *URLSigner) {
+	ctx := context.Background()
+	data := []byte("hello, world")
+	info := put(t, store, "report.txt", data, "text/plain")
+	checksum := sha256.Sum256(data)
+	if info.Bucket != conformanceBucket || info.Key != "report.txt" || info.Size != int64(len(data)) || info.ContentType != "text/plain" {
+		t.Fatalf("Put() = %+v", info)
+	}
+	if info.ETag == "" || info.ChecksumSHA256 != base64.StdEncoding.EncodeToString(checksum[:]) {
+		t.Fatalf("Put() ETag = %q, checksum = %q", info.ETag, info.ChecksumSHA256)
+	}
+
+	head, err := store.Head(ctx, conformanceBucket, "report.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if head.Size != info.Size || head.ContentType != info.ContentType || head.ETag != info.ETag || head.ChecksumSHA256 != info.ChecksumSHA256 {
+		t.Fatalf("Head() = %+v, want %+v", head, info)
+	}
+	if got := get(t, store, "report.txt", nil); !bytes.Equal(got, data) {
+		t.Fatalf("Get() = %q, want %q", got, data)
+	}
+
+	// Putting the key again replaces the object
+	put(t, store, "report.txt", []byte("replaced"), "text/markdown")
+	if got := get(t, store, "report.txt", nil); string(got) != "replaced" {
+		t.Fatalf("Get() after replacing = %q", got)
+	}
+
+	// A size that doesn't match the body is rejected
+	if _, err := store.Put(ctx, conformanceBucket, "short.txt", strings.NewReader("abc"), 4, "text/plain"); err == nil {
+		t.Fatal("Put() with a wrong size succeeded")
+	}
+	// An unknown size is read from the body
+	info, err = store.Put(ctx, conformanceBucket, "unknown.txt", strings.NewReader("abcd"), -1, "text/plain")
+	if err != nil || info.Size != 4 {
+		t.Fatalf("Put() with an unknown size = %+v, %v", info, err)
+	}
+}
+
+func testRange(t *testing.T, store BlobStore, _ *URLSigner) {
+	put(t, store, "digits", []byte("0123456789"), "text/plain")
+	tests := []struct {
+		rng  Range
+		want string
+	}{
+		{Range{Offset: 0, Length: 1}, "0"},
+		{Range{Offset: 3, Length: 4}, "3456"},
+		{Range{Offset: 7, Length: -1}, "789"},
+		{Range{Offset: 8, Length: 100}, "89"},
+	}
+	for _, tt := range tests {
+		object, err := store.Get(context.Background(), conformanceBucket, "digits", &tt.rng)
+		if err != nil {
+			t.Fatalf("Get(%+v) = %v", tt.rng, err)
+		}
+		got, err := io.ReadAll(object)
+		object.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != tt.want || object.Info.Size != 10 || object.Range == nil || object.Range.Length != int64(len(tt.want)) {
+			t.Fatalf("Get(%+v) = %q, info %+v, range %+v, want %q", tt.rng, got, object.Info, object.Range, tt.want)
+		}
+	}
+	if _, err := store.Get(context.Background(), conformanceBucket, "digits", &Range{Offset: 10, Length: 1}); !errors.Is(err, ErrInvalidRange) {
+		t.Fatalf("Get() past the end = %v, want ErrInvalidRange", err)
+	}
+}
+
+func testMissing(t *testing.T, store BlobStore, _ *URLSigner) {
+	ctx := context.Background()
+	put(t, store, "present", []byte("x"), "text/plain")
+	if _, err := store.Get(ctx, conformanceBucket, "absent", nil); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Get() of a missing object = %v, want ErrNotFound", err)
+	}
+	if _, err := store.Head(ctx, conformanceBucket, "absent"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Head() of a missing object = %v, want ErrNotFound", err)
+	}
+	if _, err := store.Get(ctx, "flomny-missing", "present", nil); !errors.Is(err, ErrBucketNotFound) {
+		t.Fatalf("Get() in a missing bucket = %v, want ErrBucketNotFound", err)
+	}
+	if _, err := store.List(ctx, "flomny-missing", ""); !errors.Is(err, ErrBucketNotFound) {
+		t.Fatalf("List() of a missing bucket = %v, want ErrBucketNotFound", err)
+	}
+}
+
+func testInvalidKeys(t *testing.T, store BlobStore, _ *URLSigner) {
+	ctx := context.Background()
+	for _, key := range []string{"../escape", "dir/../../escape", "/absolute", ""} {
+		if _, err := store.Put(ctx, conformanceBucket, key, strings.NewReader("x"), 1, "text/plain"); !errors.Is(err, ErrInvalidKey) {
+			t.Fatalf("Put(%q) = %v, want ErrInvalidKey", key, err)
+		}
+		if _, err := store.Get(ctx, conformanceBucket, key, nil); !errors.Is(err, ErrInvalidKey) {
+			t.Fatalf("Get(%q) = %v, want ErrInvalidKey", key, err)
+		}
+		if err := store.Delete(ctx, conformanceBucket, key); !errors.Is(err, ErrInvalidKey) {
+			t.Fatalf("Delete(%q) = %v, want ErrInvalidKey", key, err)
+		}
+	}
+	if _, err := store.Put(ctx, "../bucket", "file", strings.NewReader("x"), 1, "text/plain"); !errors.Is(err, ErrInvalidKey) {
+		t.Fatalf("Put() to an invalid bucket = %v, want ErrInvalidKey", err)
+	}
+}
+
+func testList(t *testing.T, store BlobStore, _ *URLSigner) {
+	for _, key := range []string{"b-file", "_registry/b.json", "a-file", "_registry/a.json"} {
+		put(t, store, key, []byte(key), "application/octet-stream")
+	}
+	tests := []struct {
+		prefix string
+		want   []string
+	}{
+		{"", []string{"_registry/a.json", "_registry/b.json", "a-file", "b-file"}},
+		{"_registry/", []string{"_registry/a.json", "_registry/b.json"}},
+		{"a-", []string{"a-file"}},
+		{"none", nil},
+	}
+	for _, tt := range tests {
+		objects, err := store.List(context.Background(), conformanceBucket, tt.prefix)
+		if err != nil {
+			t.Fatalf("List(%q) = %v", tt.prefix, err)
+		}
+		var keys []string
+		for _, object := range objects {
+			keys = append(keys, object.Key)
+			if object.Size != int64(len(object.Key)) {
+				t.Fatalf("List(%q) size of %s = %d", tt.prefix, object.Key, object.Size)
+			}
+		}
+		if strings.Join(keys, ",") != strings.Join(tt.want, ",") {
+			t.Fatalf("List(%q) = %v, want %v", tt.prefix, keys, tt.want)
+		}
+	}
+}
+
+func testDelete(t *testing.T, store BlobStore, _ *URLSigner) {
+	ctx := context.Background()
+	put(t, store, "keep", []byte("x"), "text/plain")
+	put(t, store, "dir/remove", []byte("x"), "text/plain")
+	if err := store.Delete(ctx, conformanceBucket, "dir/remove"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Head(ctx, conformanceBucket, "dir/remove"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Head() after Delete() = %v, want ErrNotFound", err)
+	}
+	// Deleting a missing object isn't an error
+	if err := store.Delete(ctx, conformanceBucket, "dir/remove"); err != nil {
+		t.Fatalf("Delete() of a missing object = %v", err)
+	}
+	if _, err := store.Head(ctx, conformanceBucket, "keep"); err != nil {
+		t.Fatalf("Head() of another object = %v", err)
+	}
+}
+
+func testPresign(t *testing.T, store BlobStore, urls *URLSigner) {
+	ctx := context.Background()
+	put(t, store, "shared file.pdf", []byte("x"), "application/pdf")
+
+	download, err := store.PresignGet(ctx, conformanceBucket, "shared file.pdf", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bucket, key, query := parseSignedURL(t, download)
+	if bucket != conformanceBucket || key != "shared file.pdf" {
+		t.Fatalf("PresignGet() URL names %s/%s", bucket, key)
+	}
+	if err := urls.Verify(http.MethodGet, bucket, key, PutConditions{}, query); err != nil {
+		t.Fatalf("Verify() of the download URL = %v", err)
+	}
+	if err := urls.Verify(http.MethodPut, bucket, key, PutConditions{}, query); !errors.Is(err, ErrInvalidSignature) {
+		t.Fatalf("Verify() of the download URL for an upload = %v, want ErrInvalidSignature", err)
+	}
+
+	conditions := PutConditions{ContentType: "application/pdf", Size: 1, ChecksumSHA256: "LUjjnMCfvaXRQQRudR0Q5BI6q4ilzQ0X3ljH+bG1p6A="}
+	upload, err := store.PresignPut(ctx, conformanceBucket, "new.pdf", conditions, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if upload.Header.Get("Content-Type") != conditions.ContentType {
+		t.Fatalf("PresignPut() headers = %v", upload.Header)
+	}
+	bucket, key, query = parseSignedURL(t, upload.URL)
+	if err := urls.Verify(http.MethodPut, bucket, key, PutConditions{ContentType: conditions.ContentType, Size: conditions.Size}, query); err != nil {
+		t.Fatalf("Verify() of the upload URL = %v", err)
+	}
+	if err := urls.Verify(http.MethodPut, bucket, key, PutConditions{ContentType: conditions.ContentType, Size: 2}, query); !errors.Is(err, ErrInvalidSignature) {
+		t.Fatalf("Verify() of the upload URL with another size = %v, want ErrInvalidSignature", err)
+	}
+
+	if _, err := store.PresignGet(ctx, conformanceBucket, "../escape", time.Minute); !errors.Is(err, ErrInvalidKey) {
+		t.Fatalf("PresignGet() of an invalid key = %v, want ErrInvalidKey", err)
+	}
+}
+
+func testMultipart(t *testing.T, store BlobStore, _ *URLSigner) {
+	ctx := context.Background()
+	put(t, store, "seed", []byte("x"), "text/plain")
+	id, err := store.CreateMultipart(ctx, conformanceBucket, "joined.bin", "application/zip")
+	if err != nil {
+		t.Fatal(err)
+	}
+	uploads, err := store.ListMultipart(ctx, conformanceBucket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(uploads) != 1 || uploads[0].UploadID != id || uploads[0].Key != "joined.bin" || uploads[0].Initiated.IsZero() {
+		t.Fatalf("ListMultipart() = %+v", uploads)
+	}
+
+	first := bytes.Repeat([]byte("a"), MinPartSize)
+	second := []byte("tail")
+	var parts []Part
+	for i, data := range [][]byte{first, second} {
+		part, err := store.UploadPart(ctx, conformanceBucket, "joined.bin", id, int32(i+1), bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			t.Fatalf("UploadPart(%d) = %v", i+1, err)
+		}
+		if part.Number != int32(i+1) || part.Size != int64(len(data)) || part.ETag == "" {
+			t.Fatalf("UploadPart(%d) = %+v", i+1, part)
+		}
+		parts = append(parts, part)
+	}
+	if _, err := store.Head(ctx, conformanceBucket, "joined.bin"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Head() before completing = %v, want ErrNotFound", err)
+	}
+
+	info, err := store.CompleteMultipart(ctx, conformanceBucket, "joined.bin", id, parts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := append(append([]byte{}, first...), second...)
+	if info.Size != int64(len(want)) || info.ContentType != "application/zip" {
+		t.Fatalf("CompleteMultipart() = %+v", info)
+	}
+	if got := get(t, store, "joined.bin", nil); !bytes.Equal(got, want) {
+		t.Fatalf("Get() of the joined object has %d bytes, want %d", len(got), len(want))
+	}
+	uploads, err = store.ListMultipart(ctx, conformanceBucket)
+	if err != nil || len(uploads) != 0 {
+		t.Fatalf("ListMultipart() after completing = %+v, %v", uploads, err)
+	}
+
+	// The upload is gone once completed
+	if _, err := store.CompleteMultipart(ctx, conformanceBucket, "joined.bin", id, parts); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("CompleteMultipart() again = %v, want ErrNotFound", err)
+	}
+	if _, err := store.UploadPart(ctx, conformanceBucket, "joined.bin", id, 3, strings.NewReader("x"), 1); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("UploadPart() to a completed upload = %v, want ErrNotFound", err)
+	}
+}
+
+func testAbortMultipart(t *testing.T, store BlobStore, _ *URLSigner) {
+	ctx := context.Background()
+	put(t, store, "seed", []byte("x"), "text/plain")
+	id, err := store.CreateMultipart(ctx, conformanceBucket, "aborted.bin", "application/zip")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.UploadPart(ctx, conformanceBucket, "aborted.bin", id, 1, strings.NewReader("part"), 4); err != nil {
+		t.Fatal(err)
+	}
+	// Parts of an upload are only accepted for its key
+	if _, err := store.UploadPart(ctx, conformanceBucket, "other.bin", id, 2, strings.NewReader("part"), 4); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("UploadPart() for another key = %v, want ErrNotFound", err)
+	}
+	if err := store.AbortMultipart(ctx, conformanceBucket, "aborted.bin", id); err != nil {
+		t.Fatal(err)
+	}
+	// Aborting a missing upload isn't an error
+	if err := store.AbortMultipart(ctx, conformanceBucket, "aborted.bin", id); err != nil {
+		t.Fatalf("AbortMultipart() again = %v", err)
+	}
+	uploads, err := store.ListMultipart(ctx, conformanceBucket)
+	if err != nil || len(uploads) != 0 {
+		t.Fatalf("ListMultipart() after aborting = %+v, %v", uploads, err)
+	}
+	if _, err := store.CompleteMultipart(ctx, conformanceBucket, "aborted.bin", id, []Part{{Number: 1}}); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("CompleteMultipart() after aborting = %v, want ErrNotFound", err)
+	}
+	if _, err := store.Head(ctx, conformanceBucket, "aborted.bin"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Head() after aborting = %v, want ErrNotFound", err)
+	}
+}
+
+func put(t *testing.T, store BlobStore, key string, data []byte, contentType string) ObjectInfo {
+	t.Helper()
+	info, err := store.Put(context.Background(), conformanceBucket, key, bytes.NewReader(data), int64(len(data)), contentType)
+	if err != nil {
+		t.Fatalf("Put(%q) = %v", key, err)
+	}
+	return info
+}
+
+func get(t *testing.T, store BlobStore, key string, rng *Range) []byte {
+	t.Helper()
+	object, err := store.Get(context.Background(), conformanceBucket, key, rng)
+	if err != nil {
+		t.Fatalf("Get(%q) = %v", key, err)
+	}
+	defer object.Close()
+	data, err := io.ReadAll(object)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+// parseSignedURL splits a URL served under /blobs into its bucket, key and query
+func parseSignedURL(t *testing.T, signed string) (string, string, url.Values) {
+	t.Helper()
+	u, err := url.Parse(signed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path, ok := strings.CutPrefix(u.Path, "/blobs/")
+	if !ok {
+		t.Fatalf("signed URL %q isn't served under /blobs", signed)
+	}
+	bucket, key, _ := strings.Cut(path, "/")
+	return bucket, key, u.Query()
+}
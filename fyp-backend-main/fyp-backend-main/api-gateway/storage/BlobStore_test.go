@@ -0,0 +1,78 @@
+package storage
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestValidateKey(t *testing.T) {
+	tests := []struct {
+		name   string
+		bucket string
+		key    string
+		valid  bool
+	}{
+		{"plain", "flomny-integrations", "0b9a-report.pdf", true},
+		{"nested key", "flomny-assets", "_registry/file.json", true},
+		{"dotted bucket", "my.bucket", "file", true},
+		{"short bucket", "ab", "file", false},
+		{"upper case bucket", "Bucket", "file", false},
+		{"bucket starting with a dash", "-bucket", "file", false},
+		{"bucket with dots in a row", "my..bucket", "file", false},
+		{"bucket with a slash", "my/bucket", "file", false},
+		{"empty key", "bucket", "", false},
+		{"long key", "bucket", strings.Repeat("k", 1025), false},
+		{"longest key", "bucket", strings.Repeat("k", 1024), true},
+		{"key with NUL", "bucket", "file\x00.txt", false},
+		{"parent segment", "bucket", "../other/file", false},
+		{"inner parent segment", "bucket", "dir/../../file", false},
+		{"current segment", "bucket", "./file", false},
+		{"leading slash", "bucket", "/file", false},
+		{"trailing slash", "bucket", "dir/", false},
+		{"double slash", "bucket", "dir//file", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateKey(tt.bucket, tt.key)
+			if tt.valid && err != nil {
+				t.Fatalf("validateKey(%q, %q) = %v, want nil", tt.bucket, tt.key, err)
+			}
+			if !tt.valid && !errors.Is(err, ErrInvalidKey) {
+				t.Fatalf("validateKey(%q, %q) = %v, want ErrInvalidKey", tt.bucket, tt.key, err)
+			}
+		})
+	}
+}
+
+func TestValidateRange(t *testing.T) {
+	tests := []struct {
+		name string
+		rng  *Range
+		size int64
+		want *Range
+		err  error
+	}{
+		{"whole object", nil, 10, nil, nil},
+		{"to the end", &Range{Offset: 4, Length: -1}, 10, &Range{Offset: 4, Length: 6}, nil},
+		{"inside", &Range{Offset: 2, Length: 3}, 10, &Range{Offset: 2, Length: 3}, nil},
+		{"past the end is cut", &Range{Offset: 8, Length: 5}, 10, &Range{Offset: 8, Length: 2}, nil},
+		{"last byte", &Range{Offset: 9, Length: 1}, 10, &Range{Offset: 9, Length: 1}, nil},
+		{"offset at the end", &Range{Offset: 10, Length: 1}, 10, nil, ErrInvalidRange},
+		{"negative offset", &Range{Offset: -1, Length: 1}, 10, nil, ErrInvalidRange},
+		{"empty", &Range{Offset: 0, Length: 0}, 10, nil, ErrInvalidRange},
+		{"negative length", &Range{Offset: 0, Length: -2}, 10, nil, ErrInvalidRange},
+		{"empty object", &Range{Offset: 0, Length: -1}, 0, nil, ErrInvalidRange},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := validateRange(tt.rng, tt.size)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("validateRange(%+v, %d) error = %v, want %v", tt.rng, tt.size, err, tt.err)
+			}
+			if (got == nil) != (tt.want == nil) || got != nil && *got != *tt.want {
+				t.Fatalf("validateRange(%+v, %d) = %+v, want %+v", tt.rng, tt.size, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,420 @@
+package storage
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Directories of a bucket, data mirrors the keys and meta holds a JSON file per object.
+// multipart holds a directory per open multipart upload, with its parts and upload.json.
+const (
+	localDataDir      = "data"
+	localMetaDir      = "meta"
+	localTempDir      = "tmp"
+	localMultipartDir = "multipart"
+)
+
+// IDs of the multipart uploads of the local store
+var validUploadID = regexp.MustCompile(`^[0-9a-f]{32}$`)
+
+// LocalStore keeps objects as files under a directory, one directory per bucket.
+// Buckets are created by the first object stored in them. As on a filesystem, a key
+// can't name an object and be the prefix of another, e.g. "a" and "a/b".
+type LocalStore struct {
+	root string
+	urls *URLSigner
+}
+
+// NewLocalStore returns a store keeping objects under dir, creating it if needed.
+// Its presigned URLs are signed by urls.
+func NewLocalStore(dir string, urls *URLSigner) (*LocalStore, error) {
+	root, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(root, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create storage directory: %w", err)
+	}
+	return &LocalStore{root: root, urls: urls}, nil
+}
+
+// Put writes the object to a temporary file and moves it in place, so readers never see
+// a partial object.
+func (l *LocalStore) Put(ctx context.Context, bucket, key string, body io.Reader, size int64, contentType string) (ObjectInfo, error) {
+	if err := validateKey(bucket, key); err != nil {
+		return ObjectInfo{}, err
+	}
+	tempDir := filepath.Join(l.root, bucket, localTempDir)
+	if err := os.MkdirAll(tempDir, 0o750); err != nil {
+		return ObjectInfo{}, err
+	}
+	temp, err := os.CreateTemp(tempDir, "put-*")
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+	defer os.Remove(temp.Name())
+	defer temp.Close()
+
+	hash, checksum := md5.New(), sha256.New()
+	written, err := io.Copy(io.MultiWriter(temp, hash, checksum), contextReader{ctx, body})
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+	if size >= 0 && written != size {
+		return ObjectInfo{}, fmt.Errorf("expected %d bytes, got %d", size, written)
+	}
+	if err := temp.Close(); err != nil {
+		return ObjectInfo{}, err
+	}
+
+	info := ObjectInfo{
+		Bucket:         bucket,
+		Key:            key,
+		Size:           written,
+		ContentType:    contentType,
+		ETag:           hex.EncodeToString(hash.Sum(nil)),
+		ChecksumSHA256: base64.StdEncoding.EncodeToString(checksum.Sum(nil)),
+		LastModified:   time.Now().UTC(),
+	}
+	meta, err := json.Marshal(info)
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+	dataPath, metaPath := l.paths(bucket, key)
+	for _, dir := range []string{filepath.Dir(dataPath), filepath.Dir(metaPath)} {
+		if err := os.MkdirAll(dir, 0o750); err != nil {
+			return ObjectInfo{}, err
+		}
+	}
+	if err := os.WriteFile(metaPath, meta, 0o640); err != nil {
+		return ObjectInfo{}, err
+	}
+	if err := os.Rename(temp.Name(), dataPath); err != nil {
+		return ObjectInfo{}, err
+	}
+	return info, nil
+}
+
+// Get opens the object, seeking to the start of the range.
+func (l *LocalStore) Get(ctx context.Context, bucket, key string, rng *Range) (*Object, error) {
+	info, err := l.Head(ctx, bucket, key)
+	if err != nil {
+		return nil, err
+	}
+	rng, err = validateRange(rng, info.Size)
+	if err != nil {
+		return nil, err
+	}
+	dataPath, _ := l.paths(bucket, key)
+	file, err := os.Open(dataPath)
+	if err != nil {
+		return nil, l.mapError(bucket, err)
+	}
+	if rng == nil {
+		return &Object{ReadCloser: file, Info: info}, nil
+	}
+	if _, err := file.Seek(rng.Offset, io.SeekStart); err != nil {
+		file.Close()
+		return nil, err
+	}
+	body := struct {
+		io.Reader
+		io.Closer
+	}{io.LimitReader(file, rng.Length), file}
+	return &Object{ReadCloser: body, Info: info, Range: rng}, nil
+}
+
+// Head reads the metadata of the object.
+func (l *LocalStore) Head(ctx context.Context, bucket, key string) (ObjectInfo, error) {
+	if err := validateKey(bucket, key); err != nil {
+		return ObjectInfo{}, err
+	}
+	_, metaPath := l.paths(bucket, key)
+	meta, err := os.ReadFile(metaPath)
+	if err != nil {
+		return ObjectInfo{}, l.mapError(bucket, err)
+	}
+	var info ObjectInfo
+	if err := json.Unmarshal(meta, &info); err != nil {
+		return ObjectInfo{}, fmt.Errorf("corrupt metadata of %s/%s: %w", bucket, key, err)
+	}
+	return info, nil
+}
+
+// Delete removes the object and its metadata.
+func (l *LocalStore) Delete(ctx context.Context, bucket, key string) error {
+	if err := validateKey(bucket, key); err != nil {
+		return err
+	}
+	dataPath, metaPath := l.paths(bucket, key)
+	for _, p := range []string{metaPath, dataPath} {
+		if err := os.Remove(p); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+	return nil
+}
+
+// List walks the metadata of the bucket, objects are sorted by key.
+func (l *LocalStore) List(ctx context.Context, bucket, prefix string) ([]ObjectInfo, error) {
+	if !validBucket.MatchString(bucket) {
+		return nil, fmt.Errorf("%w: bucket %q", ErrInvalidKey, bucket)
+	}
+	metaRoot := filepath.Join(l.root, bucket, localMetaDir)
+	var infos []ObjectInfo
+	err := filepath.WalkDir(metaRoot, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(metaRoot, strings.TrimSuffix(p, ".json"))
+		if err != nil {
+			return err
+		}
+		key := filepath.ToSlash(rel)
+		if !strings.HasPrefix(key, prefix) {
+			return nil
+		}
+		info, err := l.Head(ctx, bucket, key)
+		if err != nil {
+			return err
+		}
+		infos = append(infos, info)
+		return nil
+	})
+	if err != nil {
+		// A bucket whose objects were all deleted has no metadata left
+		if err = l.mapError(bucket, err); errors.Is(err, ErrNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Key < infos[j].Key })
+	return infos, nil
+}
+
+// PresignGet returns a gateway URL serving the object.
+func (l *LocalStore) PresignGet(ctx context.Context, bucket, key string, expires time.Duration) (string, error) {
+	if err := validateKey(bucket, key); err != nil {
+		return "", err
+	}
+	return l.urls.Sign(http.MethodGet, bucket, key, PutConditions{}, time.Now().Add(expires)), nil
+}
+
+// PresignPut returns a gateway URL the object can be uploaded to.
+func (l *LocalStore) PresignPut(ctx context.Context, bucket, key string, conditions PutConditions, expires time.Duration) (PresignedPut, error) {
+	if err := validateKey(bucket, key); err != nil {
+		return PresignedPut{}, err
+	}
+	return PresignedPut{
+		URL:    l.urls.Sign(http.MethodPut, bucket, key, conditions, time.Now().Add(expires)),
+		Header: http.Header{"Content-Type": {conditions.ContentType}},
+	}, nil
+}
+
+// CreateMultipart creates the directory keeping the parts of the upload.
+func (l *LocalStore) CreateMultipart(ctx context.Context, bucket, key, contentType string) (string, error) {
+	if err := validateKey(bucket, key); err != nil {
+		return "", err
+	}
+	id, err := randomID()
+	if err != nil {
+		return "", err
+	}
+	dir := filepath.Join(l.root, bucket, localMultipartDir, id)
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return "", err
+	}
+	meta, err := json.Marshal(localMultipart{Key: key, ContentType: contentType, Initiated: time.Now().UTC()})
+	if err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(filepath.Join(dir, "upload.json"), meta, 0o640); err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+// UploadPart writes the part to a file named after its number.
+func (l *LocalStore) UploadPart(ctx context.Context, bucket, key, uploadID string, number int32, body io.Reader, size int64) (Part, error) {
+	_, dir, err := l.multipartUpload(bucket, key, uploadID)
+	if err != nil {
+		return Part{}, err
+	}
+	temp, err := os.CreateTemp(dir, "part-*")
+	if err != nil {
+		return Part{}, err
+	}
+	defer os.Remove(temp.Name())
+	defer temp.Close()
+
+	hash := md5.New()
+	written, err := io.Copy(io.MultiWriter(temp, hash), contextReader{ctx, body})
+	if err != nil {
+		return Part{}, err
+	}
+	if written != size {
+		return Part{}, fmt.Errorf("expected %d bytes, got %d", size, written)
+	}
+	if err := temp.Close(); err != nil {
+		return Part{}, err
+	}
+	if err := os.Rename(temp.Name(), filepath.Join(dir, strconv.Itoa(int(number)))); err != nil {
+		return Part{}, err
+	}
+	return Part{Number: number, ETag: hex.EncodeToString(hash.Sum(nil)), Size: size}, nil
+}
+
+// CompleteMultipart stores the parts as the object and removes the upload.
+func (l *LocalStore) CompleteMultipart(ctx context.Context, bucket, key, uploadID string, parts []Part) (ObjectInfo, error) {
+	upload, dir, err := l.multipartUpload(bucket, key, uploadID)
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+	readers := make([]io.Reader, 0, len(parts))
+	var size int64
+	for _, part := range parts {
+		file, err := os.Open(filepath.Join(dir, strconv.Itoa(int(part.Number))))
+		if err != nil {
+			return ObjectInfo{}, l.mapError(bucket, err)
+		}
+		defer file.Close()
+		readers = append(readers, file)
+		size += part.Size
+	}
+	info, err := l.Put(ctx, bucket, key, io.MultiReader(readers...), size, upload.ContentType)
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+	if err := os.RemoveAll(dir); err != nil {
+		return ObjectInfo{}, err
+	}
+	return info, nil
+}
+
+// AbortMultipart removes the directory of the upload.
+func (l *LocalStore) AbortMultipart(ctx context.Context, bucket, key, uploadID string) error {
+	_, dir, err := l.multipartUpload(bucket, key, uploadID)
+	if errors.Is(err, ErrNotFound) || errors.Is(err, ErrBucketNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return os.RemoveAll(dir)
+}
+
+// ListMultipart reads the uploads in the multipart directory of the bucket.
+func (l *LocalStore) ListMultipart(ctx context.Context, bucket string) ([]MultipartUpload, error) {
+	if !validBucket.MatchString(bucket) {
+		return nil, fmt.Errorf("%w: bucket %q", ErrInvalidKey, bucket)
+	}
+	entries, err := os.ReadDir(filepath.Join(l.root, bucket, localMultipartDir))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var uploads []MultipartUpload
+	for _, entry := range entries {
+		if !entry.IsDir() || !validUploadID.MatchString(entry.Name()) {
+			continue
+		}
+		meta, err := os.ReadFile(filepath.Join(l.root, bucket, localMultipartDir, entry.Name(), "upload.json"))
+		if err != nil {
+			// Created but not described yet, or being removed
+			continue
+		}
+		var upload localMultipart
+		if err := json.Unmarshal(meta, &upload); err != nil {
+			return nil, fmt.Errorf("corrupt multipart upload %s: %w", entry.Name(), err)
+		}
+		uploads = append(uploads, MultipartUpload{Key: upload.Key, UploadID: entry.Name(), Initiated: upload.Initiated})
+	}
+	return uploads, nil
+}
+
+// Ping checks that the directory is still there.
+func (l *LocalStore) Ping(ctx context.Context) error {
+	_, err := os.Stat(l.root)
+	return err
+}
+
+// paths returns where the data and the metadata of the object are kept
+func (l *LocalStore) paths(bucket, key string) (string, string) {
+	key = filepath.FromSlash(key)
+	return filepath.Join(l.root, bucket, localDataDir, key), filepath.Join(l.root, bucket, localMetaDir, key+".json")
+}
+
+// mapError turns a missing file into ErrNotFound, or ErrBucketNotFound when the bucket is missing
+func (l *LocalStore) mapError(bucket string, err error) error {
+	if !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	if _, statErr := os.Stat(filepath.Join(l.root, bucket)); errors.Is(statErr, fs.ErrNotExist) {
+		return ErrBucketNotFound
+	}
+	return ErrNotFound
+}
+
+// localMultipart is the upload.json of a multipart upload
+type localMultipart struct {
+	Key         string
+	ContentType string
+	Initiated   time.Time
+}
+
+// multipartUpload reads the upload, checking it was started for the key
+func (l *LocalStore) multipartUpload(bucket, key, uploadID string) (localMultipart, string, error) {
+	if err := validateKey(bucket, key); err != nil {
+		return localMultipart{}, "", err
+	}
+	if !validUploadID.MatchString(uploadID) {
+		return localMultipart{}, "", ErrNotFound
+	}
+	dir := filepath.Join(l.root, bucket, localMultipartDir, uploadID)
+	meta, err := os.ReadFile(filepath.Join(dir, "upload.json"))
+	if err != nil {
+		return localMultipart{}, "", l.mapError(bucket, err)
+	}
+	var upload localMultipart
+	if err := json.Unmarshal(meta, &upload); err != nil {
+		return localMultipart{}, "", fmt.Errorf("corrupt multipart upload %s: %w", uploadID, err)
+	}
+	if upload.Key != key {
+		return localMultipart{}, "", ErrNotFound
+	}
+	return upload, dir, nil
+}
+
+// contextReader stops reading once the context is done
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (c contextReader) Read(p []byte) (int, error) {
+	if err := c.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return c.r.Read(p)
+}
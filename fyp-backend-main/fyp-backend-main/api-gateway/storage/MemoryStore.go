@@ -0,0 +1,253 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MemoryStore keeps objects in memory, they are lost on restart. Buckets are created
+// by the first object stored in them.
+type MemoryStore struct {
+	urls *URLSigner
+
+	mu        sync.RWMutex
+	buckets   map[string]map[string]memoryObject
+	multipart map[string]*memoryMultipart
+}
+
+type memoryObject struct {
+	info ObjectInfo
+	data []byte
+}
+
+type memoryMultipart struct {
+	upload      MultipartUpload
+	bucket      string
+	contentType string
+	parts       map[int32][]byte
+}
+
+// NewMemoryStore returns an empty in-memory store whose presigned URLs are signed by urls.
+func NewMemoryStore(urls *URLSigner) *MemoryStore {
+	return &MemoryStore{urls: urls, buckets: make(map[string]map[string]memoryObject), multipart: make(map[string]*memoryMultipart)}
+}
+
+// Put stores the object.
+func (m *MemoryStore) Put(ctx context.Context, bucket, key string, body io.Reader, size int64, contentType string) (ObjectInfo, error) {
+	if err := validateKey(bucket, key); err != nil {
+		return ObjectInfo{}, err
+	}
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+	if size >= 0 && int64(len(data)) != size {
+		return ObjectInfo{}, fmt.Errorf("expected %d bytes, got %d", size, len(data))
+	}
+	sum := md5.Sum(data)
+	checksum := sha256.Sum256(data)
+	info := ObjectInfo{
+		Bucket:         bucket,
+		Key:            key,
+		Size:           int64(len(data)),
+		ContentType:    contentType,
+		ETag:           hex.EncodeToString(sum[:]),
+		ChecksumSHA256: base64.StdEncoding.EncodeToString(checksum[:]),
+		LastModified:   time.Now().UTC(),
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.buckets[bucket] == nil {
+		m.buckets[bucket] = make(map[string]memoryObject)
+	}
+	m.buckets[bucket][key] = memoryObject{info: info, data: data}
+	return info, nil
+}
+
+// Get reads the object or a range of it.
+func (m *MemoryStore) Get(ctx context.Context, bucket, key string, rng *Range) (*Object, error) {
+	object, err := m.lookup(bucket, key)
+	if err != nil {
+		return nil, err
+	}
+	rng, err = validateRange(rng, object.info.Size)
+	if err != nil {
+		return nil, err
+	}
+	data := object.data
+	if rng != nil {
+		data = data[rng.Offset : rng.Offset+rng.Length]
+	}
+	return &Object{ReadCloser: io.NopCloser(bytes.NewReader(data)), Info: object.info, Range: rng}, nil
+}
+
+// Head describes the object.
+func (m *MemoryStore) Head(ctx context.Context, bucket, key string) (ObjectInfo, error) {
+	object, err := m.lookup(bucket, key)
+	return object.info, err
+}
+
+// Delete removes the object.
+func (m *MemoryStore) Delete(ctx context.Context, bucket, key string) error {
+	if err := validateKey(bucket, key); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.buckets[bucket], key)
+	return nil
+}
+
+// List returns the objects whose key starts with prefix, sorted by key.
+func (m *MemoryStore) List(ctx context.Context, bucket, prefix string) ([]ObjectInfo, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	objects, ok := m.buckets[bucket]
+	if !ok {
+		return nil, ErrBucketNotFound
+	}
+	var infos []ObjectInfo
+	for key, object := range objects {
+		if strings.HasPrefix(key, prefix) {
+			infos = append(infos, object.info)
+		}
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Key < infos[j].Key })
+	return infos, nil
+}
+
+// PresignGet returns a gateway URL serving the object.
+func (m *MemoryStore) PresignGet(ctx context.Context, bucket, key string, expires time.Duration) (string, error) {
+	if err := validateKey(bucket, key); err != nil {
+		return "", err
+	}
+	return m.urls.Sign(http.MethodGet, bucket, key, PutConditions{}, time.Now().Add(expires)), nil
+}
+
+// PresignPut returns a gateway URL the object can be uploaded to.
+func (m *MemoryStore) PresignPut(ctx context.Context, bucket, key string, conditions PutConditions, expires time.Duration) (PresignedPut, error) {
+	if err := validateKey(bucket, key); err != nil {
+		return PresignedPut{}, err
+	}
+	return PresignedPut{
+		URL:    m.urls.Sign(http.MethodPut, bucket, key, conditions, time.Now().Add(expires)),
+		Header: http.Header{"Content-Type": {conditions.ContentType}},
+	}, nil
+}
+
+// CreateMultipart starts an upload kept in memory until it's completed or aborted.
+func (m *MemoryStore) CreateMultipart(ctx context.Context, bucket, key, contentType string) (string, error) {
+	if err := validateKey(bucket, key); err != nil {
+		return "", err
+	}
+	id, err := randomID()
+	if err != nil {
+		return "", err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.multipart[id] = &memoryMultipart{
+		upload:      MultipartUpload{Key: key, UploadID: id, Initiated: time.Now().UTC()},
+		bucket:      bucket,
+		contentType: contentType,
+		parts:       make(map[int32][]byte),
+	}
+	return id, nil
+}
+
+// UploadPart stores a part, replacing any part with the same number.
+func (m *MemoryStore) UploadPart(ctx context.Context, bucket, key, uploadID string, number int32, body io.Reader, size int64) (Part, error) {
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return Part{}, err
+	}
+	if int64(len(data)) != size {
+		return Part{}, fmt.Errorf("expected %d bytes, got %d", size, len(data))
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	upload, ok := m.multipart[uploadID]
+	if !ok || upload.bucket != bucket || upload.upload.Key != key {
+		return Part{}, ErrNotFound
+	}
+	upload.parts[number] = data
+	sum := md5.Sum(data)
+	return Part{Number: number, ETag: hex.EncodeToString(sum[:]), Size: size}, nil
+}
+
+// CompleteMultipart joins the parts into the object.
+func (m *MemoryStore) CompleteMultipart(ctx context.Context, bucket, key, uploadID string, parts []Part) (ObjectInfo, error) {
+	m.mu.Lock()
+	upload, ok := m.multipart[uploadID]
+	if !ok || upload.bucket != bucket || upload.upload.Key != key {
+		m.mu.Unlock()
+		return ObjectInfo{}, ErrNotFound
+	}
+	var data []byte
+	for _, part := range parts {
+		partData, ok := upload.parts[part.Number]
+		if !ok {
+			m.mu.Unlock()
+			return ObjectInfo{}, fmt.Errorf("%w: part %d", ErrNotFound, part.Number)
+		}
+		data = append(data, partData...)
+	}
+	delete(m.multipart, uploadID)
+	m.mu.Unlock()
+	return m.Put(ctx, bucket, key, bytes.NewReader(data), int64(len(data)), upload.contentType)
+}
+
+// AbortMultipart drops the parts.
+func (m *MemoryStore) AbortMultipart(ctx context.Context, bucket, key, uploadID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.multipart, uploadID)
+	return nil
+}
+
+// ListMultipart returns the open uploads of the bucket.
+func (m *MemoryStore) ListMultipart(ctx context.Context, bucket string) ([]MultipartUpload, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var uploads []MultipartUpload
+	for _, upload := range m.multipart {
+		if upload.bucket == bucket {
+			uploads = append(uploads, upload.upload)
+		}
+	}
+	return uploads, nil
+}
+
+// Ping always succeeds.
+func (m *MemoryStore) Ping(ctx context.Context) error {
+	return nil
+}
+
+func (m *MemoryStore) lookup(bucket, key string) (memoryObject, error) {
+	if err := validateKey(bucket, key); err != nil {
+		return memoryObject{}, err
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	objects, ok := m.buckets[bucket]
+	if !ok {
+		return memoryObject{}, ErrBucketNotFound
+	}
+	object, ok := objects[key]
+	if !ok {
+		return memoryObject{}, ErrNotFound
+	}
+	return object, nil
+}
@@ -0,0 +1,59 @@
+package storage
+
+import (
+	"fmt"
+	"maps"
+	"strings"
+)
+
+// Namespaces clients upload to, each kept in its own bucket
+const (
+	NamespaceIntegrations = "integrations"
+	NamespaceWorkflows    = "workflows"
+	NamespaceAssets       = "assets"
+)
+
+// Namespaces maps the namespaces files are uploaded to onto the buckets keeping them.
+// Clients never name buckets, so only the configured buckets can be reached.
+type Namespaces struct {
+	buckets    map[string]string
+	namespaces map[string]string
+}
+
+// NewNamespaces checks that buckets maps known namespaces onto distinct, valid bucket names.
+func NewNamespaces(buckets map[string]string) (*Namespaces, error) {
+	n := &Namespaces{buckets: make(map[string]string), namespaces: make(map[string]string)}
+	for namespace, bucket := range buckets {
+		switch namespace {
+		case NamespaceIntegrations, NamespaceWorkflows, NamespaceAssets:
+		default:
+			return nil, fmt.Errorf("unknown namespace %q, expected %s, %s or %s", namespace, NamespaceIntegrations, NamespaceWorkflows, NamespaceAssets)
+		}
+		if !validBucket.MatchString(bucket) || strings.Contains(bucket, "..") {
+			return nil, fmt.Errorf("namespace %q has an invalid bucket name %q", namespace, bucket)
+		}
+		if other, ok := n.namespaces[bucket]; ok {
+			return nil, fmt.Errorf("namespaces %q and %q share the bucket %q", other, namespace, bucket)
+		}
+		n.buckets[namespace] = bucket
+		n.namespaces[bucket] = namespace
+	}
+	return n, nil
+}
+
+// Resolve returns the namespace and bucket named by name. File URLs handed out before
+// namespaces existed name the bucket, so a configured bucket name resolves too.
+func (n *Namespaces) Resolve(name string) (namespace, bucket string, ok bool) {
+	if bucket, ok := n.buckets[name]; ok {
+		return name, bucket, true
+	}
+	if namespace, ok := n.namespaces[name]; ok {
+		return namespace, name, true
+	}
+	return "", "", false
+}
+
+// Buckets returns the bucket of each namespace, keyed by namespace.
+func (n *Namespaces) Buckets() map[string]string {
+	return maps.Clone(n.buckets)
+}
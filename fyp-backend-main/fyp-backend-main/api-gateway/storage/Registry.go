@@ -0,0 +1,182 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Visibility of an uploaded file
+const (
+	// VisibilityPrivate files are served to their owner and the users they're shared with
+	VisibilityPrivate = "private"
+	// VisibilityPublic files are served to every signed in user
+	VisibilityPublic = "public"
+)
+
+// Status of an upload
+const (
+	// UploadPending uploads were handed a presigned URL and haven't been completed yet
+	UploadPending = "pending"
+	// UploadComplete uploads were stored and verified, records without a status are complete too
+	UploadComplete = "complete"
+)
+
+// registryPrefix keeps the records apart from the files, whose keys are a single
+// segment starting with a UUID
+const registryPrefix = "_registry/"
+
+// pendingPrefix holds an empty marker per pending upload, so sweeping them doesn't read
+// the record of every upload ever made
+const pendingPrefix = "_pending/"
+
+// Upload is the registry record of an uploaded file.
+type Upload struct {
+	Namespace        string    `json:"namespace"`
+	Bucket           string    `json:"bucket"`
+	Key              string    `json:"key"`
+	Owner            string    `json:"owner"`
+	OriginalFilename string    `json:"original_filename"`
+	Size             int64     `json:"size"`
+	ContentType      string    `json:"content_type"`
+	ETag             string    `json:"etag,omitempty"`
+	ChecksumSHA256   string    `json:"checksum_sha256,omitempty"`
+	Visibility       string    `json:"visibility"`
+	SharedWith       []string  `json:"shared_with,omitempty"`
+	Status           string    `json:"status,omitempty"`
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+	// ExpiresAt is when a pending upload is abandoned
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// CanRead reports whether the upload is served to userID on its own account, files the
+// record doesn't cover may still be attached to a public integration or workflow. Only
+// the owner can read a pending upload, its content wasn't verified yet.
+func (u *Upload) CanRead(userID string) bool {
+	if u.Owner == userID {
+		return true
+	}
+	return u.Status != UploadPending && (u.Visibility == VisibilityPublic || slices.Contains(u.SharedWith, userID))
+}
+
+// Registry records who uploaded each file and who may read it. A record is a JSON
+// object in the bucket of the file, so it's kept by every storage driver and goes
+// wherever the bucket goes.
+type Registry struct {
+	store BlobStore
+}
+
+// NewRegistry returns a registry keeping its records in store.
+func NewRegistry(store BlobStore) *Registry {
+	return &Registry{store: store}
+}
+
+// Record saves the record of an upload, replacing any previous one. Pending uploads are
+// marked before their record is saved, the mark is removed once the record isn't pending.
+func (r *Registry) Record(ctx context.Context, upload Upload) error {
+	if upload.Visibility != VisibilityPrivate && upload.Visibility != VisibilityPublic {
+		return fmt.Errorf("invalid visibility %q", upload.Visibility)
+	}
+	upload.UpdatedAt = time.Now().UTC()
+	data, err := json.Marshal(upload)
+	if err != nil {
+		return err
+	}
+	pending := upload.Status == UploadPending
+	if pending {
+		if _, err := r.store.Put(ctx, upload.Bucket, pendingPrefix+upload.Key, bytes.NewReader(nil), 0, "application/octet-stream"); err != nil {
+			return err
+		}
+	}
+	_, err = r.store.Put(ctx, upload.Bucket, registryPrefix+upload.Key+".json", bytes.NewReader(data), int64(len(data)), "application/json")
+	if err != nil || pending {
+		return err
+	}
+	// A mark left behind is removed by the next sweep
+	if err := r.store.Delete(ctx, upload.Bucket, pendingPrefix+upload.Key); err != nil && !errors.Is(err, ErrNotFound) {
+		slog.WarnContext(ctx, "Failed to unmark completed upload", "bucket", upload.Bucket, "key", upload.Key, "error", err)
+	}
+	return nil
+}
+
+// Lookup reads the record of the file, ErrNotFound when it was uploaded before the registry existed.
+func (r *Registry) Lookup(ctx context.Context, bucket, key string) (*Upload, error) {
+	object, err := r.store.Get(ctx, bucket, registryPrefix+key+".json", nil)
+	if err != nil {
+		return nil, err
+	}
+	defer object.Close()
+	data, err := io.ReadAll(object)
+	if err != nil {
+		return nil, err
+	}
+	var upload Upload
+	if err := json.Unmarshal(data, &upload); err != nil {
+		return nil, fmt.Errorf("corrupt upload record of %s/%s: %w", bucket, key, err)
+	}
+	return &upload, nil
+}
+
+// Delete removes the record of the file.
+func (r *Registry) Delete(ctx context.Context, bucket, key string) error {
+	if err := r.store.Delete(ctx, bucket, registryPrefix+key+".json"); err != nil {
+		return err
+	}
+	if err := r.store.Delete(ctx, bucket, pendingPrefix+key); err != nil && !errors.Is(err, ErrNotFound) {
+		return err
+	}
+	return nil
+}
+
+// SweepPending removes the pending uploads that expired before the given time, their files
+// included, and returns how many it removed. Only the marked uploads are read, marks of
+// uploads that aren't pending anymore are removed.
+func (r *Registry) SweepPending(ctx context.Context, bucket string, before time.Time) (int, error) {
+	objects, err := r.store.List(ctx, bucket, pendingPrefix)
+	if errors.Is(err, ErrBucketNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	removed := 0
+	for _, object := range objects {
+		key := strings.TrimPrefix(object.Key, pendingPrefix)
+		upload, err := r.Lookup(ctx, bucket, key)
+		if err != nil && !errors.Is(err, ErrNotFound) {
+			return removed, err
+		}
+		if err != nil && object.LastModified.After(before) {
+			// The record of a new upload is saved after its mark
+			continue
+		}
+		if err != nil || upload.Status != UploadPending {
+			// Completed or removed since it was marked
+			if err := r.store.Delete(ctx, bucket, object.Key); err != nil && !errors.Is(err, ErrNotFound) {
+				return removed, fmt.Errorf("error unmarking upload %s: %w", key, err)
+			}
+			continue
+		}
+		if upload.ExpiresAt == nil || !upload.ExpiresAt.Before(before) {
+			continue
+		}
+		// The file goes first, so a failure leaves the record to retry with
+		if err := r.store.Delete(ctx, bucket, key); err != nil && !errors.Is(err, ErrNotFound) {
+			return removed, fmt.Errorf("error removing file of upload %s: %w", key, err)
+		}
+		if err := r.Delete(ctx, bucket, key); err != nil && !errors.Is(err, ErrNotFound) {
+			return removed, fmt.Errorf("error removing record of upload %s: %w", key, err)
+		}
+		removed++
+	}
+	return removed, nil
+}
@@ -0,0 +1,87 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestRegistrySweepPending(t *testing.T) {
+	ctx := context.Background()
+	urls, err := NewURLSigner("http://gateway/blobs", testSigningKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := NewMemoryStore(urls)
+	registry := NewRegistry(store)
+	now := time.Now()
+
+	record := func(key, status string, expiresAt time.Time) {
+		t.Helper()
+		put(t, store, key, []byte("data"), "text/plain")
+		err := registry.Record(ctx, Upload{Bucket: conformanceBucket, Key: key, Visibility: VisibilityPrivate, Status: status, ExpiresAt: &expiresAt})
+		if err != nil {
+			t.Fatalf("Record(%q) = %v", key, err)
+		}
+	}
+	record("expired.bin", UploadPending, now.Add(-time.Hour))
+	record("waiting.bin", UploadPending, now.Add(time.Hour))
+	record("completed.bin", UploadPending, now.Add(-time.Hour))
+	record("completed.bin", UploadComplete, now.Add(-time.Hour))
+	record("old.bin", "", now.Add(-time.Hour))
+	// A mark whose record is still being saved
+	if _, err := store.Put(ctx, conformanceBucket, pendingPrefix+"new.bin", bytes.NewReader(nil), 0, "application/octet-stream"); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := marks(t, store), []string{"expired.bin", "new.bin", "waiting.bin"}; !slices.Equal(got, want) {
+		t.Fatalf("marks before sweeping = %v, want %v", got, want)
+	}
+	removed, err := registry.SweepPending(ctx, conformanceBucket, now.Add(-time.Minute))
+	if err != nil {
+		t.Fatalf("SweepPending() = %v", err)
+	}
+	if removed != 1 {
+		t.Fatalf("SweepPending() removed %d uploads, want 1", removed)
+	}
+	if _, err := store.Head(ctx, conformanceBucket, "expired.bin"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Head() of the expired upload = %v, want ErrNotFound", err)
+	}
+	if _, err := registry.Lookup(ctx, conformanceBucket, "expired.bin"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Lookup() of the expired upload = %v, want ErrNotFound", err)
+	}
+	for _, key := range []string{"waiting.bin", "completed.bin", "old.bin"} {
+		if _, err := registry.Lookup(ctx, conformanceBucket, key); err != nil {
+			t.Fatalf("Lookup(%q) = %v, want the record kept", key, err)
+		}
+	}
+	if got, want := marks(t, store), []string{"new.bin", "waiting.bin"}; !slices.Equal(got, want) {
+		t.Fatalf("marks after sweeping = %v, want %v", got, want)
+	}
+
+	// Once older than the sweep, a mark without a record is dropped
+	if _, err := registry.SweepPending(ctx, conformanceBucket, now.Add(time.Minute)); err != nil {
+		t.Fatalf("SweepPending() = %v", err)
+	}
+	if got, want := marks(t, store), []string{"waiting.bin"}; !slices.Equal(got, want) {
+		t.Fatalf("marks after sweeping later = %v, want %v", got, want)
+	}
+}
+
+// marks lists the keys of the uploads marked pending, sorted
+func marks(t *testing.T, store BlobStore) []string {
+	t.Helper()
+	objects, err := store.List(context.Background(), conformanceBucket, pendingPrefix)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := []string{}
+	for _, object := range objects {
+		keys = append(keys, object.Key[len(pendingPrefix):])
+	}
+	slices.Sort(keys)
+	return keys
+}
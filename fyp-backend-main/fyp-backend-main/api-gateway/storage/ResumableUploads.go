@@ -0,0 +1,415 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"strings"
+	"time"
+)
+
+// resumablePrefix keeps the state of resumable uploads and their pending bytes apart
+// from the files, like the registry records
+const resumablePrefix = "_resumable/"
+
+// ErrOffsetMismatch is returned when a write doesn't start where the upload stopped.
+var ErrOffsetMismatch = errors.New("offset doesn't match the upload")
+
+// ResumableUpload is the state of a file uploaded in chunks.
+type ResumableUpload struct {
+	ID string `json:"id"`
+	// Upload is recorded in the registry once every byte was received
+	Upload Upload `json:"upload"`
+	Length int64  `json:"length"`
+	Offset int64  `json:"offset"`
+	// Metadata is sent back to the client as it was received
+	Metadata    string `json:"metadata,omitempty"`
+	MultipartID string `json:"multipart_id"`
+	Parts       []Part `json:"parts,omitempty"`
+	// PendingSize bytes received after the last part wait in an object until they fill a part
+	PendingSize int64 `json:"pending_size,omitempty"`
+	// Hash is the SHA-256 state after Offset bytes
+	Hash []byte `json:"hash"`
+	// Finished uploads were joined into the file, Recorded ones were registered by OnFinish too
+	Finished  bool      `json:"finished,omitempty"`
+	Recorded  bool      `json:"recorded,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// LockName names the lock held by whoever changes the upload
+func (u *ResumableUpload) LockName() string {
+	return "tus:" + u.Upload.Bucket + ":" + u.ID
+}
+
+// ResumableUploads keeps files uploaded in chunks that may be resumed after a failed
+// request, as the tus protocol does. Chunks are gathered into the parts of a multipart
+// upload, so a write buffers at most one part whatever the size of the file. The state
+// of an upload is a JSON object in the bucket of the file, like the registry records.
+type ResumableUploads struct {
+	store    BlobStore
+	partSize int64
+	// OnFinish registers the file once every byte was stored, with its size, ETag and
+	// checksum set. If it fails the upload stays unrecorded and the next write retries it.
+	OnFinish func(ctx context.Context, upload Upload) error
+	// Lock takes the lock writes of the upload hold, so Sweep doesn't remove an upload
+	// while it's written. Sweep skips the uploads it can't lock, and locks none if nil.
+	Lock func(ctx context.Context, u *ResumableUpload) (release func(), acquired bool, err error)
+}
+
+// NewResumableUploads returns resumable uploads stored in parts of partSize bytes.
+func NewResumableUploads(store BlobStore, partSize int64) (*ResumableUploads, error) {
+	if partSize < MinPartSize {
+		return nil, fmt.Errorf("part size must be at least %d bytes", MinPartSize)
+	}
+	return &ResumableUploads{store: store, partSize: partSize}, nil
+}
+
+// Create starts an upload of length bytes of the file described by upload.
+func (r *ResumableUploads) Create(ctx context.Context, id string, upload Upload, length int64, metadata string, expiresAt time.Time) (*ResumableUpload, error) {
+	if strings.Contains(id, "/") {
+		return nil, ErrInvalidKey
+	}
+	multipartID, err := r.store.CreateMultipart(ctx, upload.Bucket, upload.Key, upload.ContentType)
+	if err != nil {
+		return nil, err
+	}
+	state, err := sha256.New().(encoding.BinaryMarshaler).MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	u := &ResumableUpload{
+		ID:          id,
+		Upload:      upload,
+		Length:      length,
+		Metadata:    metadata,
+		MultipartID: multipartID,
+		Hash:        state,
+		CreatedAt:   time.Now().UTC(),
+		ExpiresAt:   expiresAt,
+	}
+	if err := r.save(ctx, u); err != nil {
+		if abortErr := r.store.AbortMultipart(context.WithoutCancel(ctx), upload.Bucket, upload.Key, multipartID); abortErr != nil {
+			return nil, errors.Join(err, abortErr)
+		}
+		return nil, err
+	}
+	return u, nil
+}
+
+// Get reads the state of an upload, ErrNotFound when it doesn't exist.
+func (r *ResumableUploads) Get(ctx context.Context, bucket, id string) (*ResumableUpload, error) {
+	if strings.Contains(id, "/") {
+		return nil, ErrNotFound
+	}
+	object, err := r.store.Get(ctx, bucket, stateKey(id), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer object.Close()
+	data, err := io.ReadAll(object)
+	if err != nil {
+		return nil, err
+	}
+	var u ResumableUpload
+	if err := json.Unmarshal(data, &u); err != nil {
+		return nil, fmt.Errorf("corrupt resumable upload %s/%s: %w", bucket, id, err)
+	}
+	return &u, nil
+}
+
+// Write appends body to the upload from offset, up to its length. What was received is
+// kept when body fails, so the client resumes from the offset of the upload. Once every
+// byte was received the file is stored and OnFinish is called.
+func (r *ResumableUploads) Write(ctx context.Context, u *ResumableUpload, offset int64, body io.Reader) error {
+	if offset != u.Offset {
+		return ErrOffsetMismatch
+	}
+	if u.Finished || u.Offset == u.Length {
+		return r.finish(ctx, u)
+	}
+
+	// Store what was received even when the client goes away mid-request
+	storeCtx := context.WithoutCancel(ctx)
+	bucket := u.Upload.Bucket
+	digest := sha256.New()
+	if err := digest.(encoding.BinaryUnmarshaler).UnmarshalBinary(u.Hash); err != nil {
+		return fmt.Errorf("corrupt resumable upload %s/%s: %w", bucket, u.ID, err)
+	}
+
+	// Continue the part the previous write left pending
+	stored := u.Offset - u.PendingSize
+	buf := make([]byte, 0, min(r.partSize, u.Length-stored))
+	if u.PendingSize > 0 {
+		object, err := r.store.Get(storeCtx, bucket, pendingKey(u.ID), nil)
+		if err != nil {
+			return fmt.Errorf("error reading pending bytes: %w", err)
+		}
+		buf, err = readFull(buf, object, u.PendingSize)
+		object.Close()
+		if err != nil {
+			return fmt.Errorf("error reading pending bytes: %w", err)
+		}
+	}
+
+	body = io.LimitReader(body, u.Length-u.Offset)
+	received := false
+	var readErr error
+	for readErr == nil {
+		var n int
+		n, readErr = io.ReadFull(body, buf[len(buf):cap(buf)])
+		digest.Write(buf[len(buf) : len(buf)+n])
+		buf = buf[:len(buf)+n]
+		received = received || n > 0
+		if len(buf) == 0 || (len(buf) < cap(buf) && stored+int64(len(buf)) < u.Length) {
+			continue
+		}
+
+		// Store the full part, or the last one
+		part, err := r.store.UploadPart(storeCtx, bucket, u.Upload.Key, u.MultipartID, int32(len(u.Parts)+1), bytes.NewReader(buf), int64(len(buf)))
+		if err != nil {
+			return fmt.Errorf("error uploading part: %w", err)
+		}
+		stored += part.Size
+		u.Parts = append(u.Parts, part)
+		u.Offset, u.PendingSize = stored, 0
+		if err := r.saveHash(storeCtx, u, digest); err != nil {
+			return err
+		}
+		received = false
+		if stored == u.Length {
+			break
+		}
+		buf = buf[:0:min(r.partSize, u.Length-stored)]
+	}
+
+	// Keep the bytes short of a part until the next write
+	if received {
+		if _, err := r.store.Put(storeCtx, bucket, pendingKey(u.ID), bytes.NewReader(buf), int64(len(buf)), "application/octet-stream"); err != nil {
+			return fmt.Errorf("error storing pending bytes: %w", err)
+		}
+		u.Offset, u.PendingSize = stored+int64(len(buf)), int64(len(buf))
+		if err := r.saveHash(storeCtx, u, digest); err != nil {
+			return err
+		}
+	}
+	if readErr != nil && readErr != io.EOF && readErr != io.ErrUnexpectedEOF {
+		return readErr
+	}
+	if u.Offset == u.Length {
+		return r.finish(ctx, u)
+	}
+	return nil
+}
+
+// finish joins the parts into the file and registers it, both steps are skipped when
+// they were done already
+func (r *ResumableUploads) finish(ctx context.Context, u *ResumableUpload) error {
+	ctx = context.WithoutCancel(ctx)
+	bucket, key := u.Upload.Bucket, u.Upload.Key
+	if !u.Finished {
+		digest := sha256.New()
+		if err := digest.(encoding.BinaryUnmarshaler).UnmarshalBinary(u.Hash); err != nil {
+			return fmt.Errorf("corrupt resumable upload %s/%s: %w", bucket, u.ID, err)
+		}
+
+		var info ObjectInfo
+		var err error
+		if len(u.Parts) == 0 {
+			// An empty file has no part
+			if err := r.store.AbortMultipart(ctx, bucket, key, u.MultipartID); err != nil {
+				return fmt.Errorf("error aborting multipart upload: %w", err)
+			}
+			info, err = r.store.Put(ctx, bucket, key, bytes.NewReader(nil), 0, u.Upload.ContentType)
+		} else {
+			info, err = r.store.CompleteMultipart(ctx, bucket, key, u.MultipartID, u.Parts)
+			if errors.Is(err, ErrNotFound) {
+				// Completed by a write that failed to save the state afterwards
+				info, err = r.store.Head(ctx, bucket, key)
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("error completing upload: %w", err)
+		}
+		if info.Size != u.Length {
+			return fmt.Errorf("completed upload %s/%s has %d bytes instead of %d", bucket, u.ID, info.Size, u.Length)
+		}
+
+		u.Finished = true
+		u.Parts = nil
+		u.Upload.Size = info.Size
+		u.Upload.ETag = info.ETag
+		u.Upload.ChecksumSHA256 = base64.StdEncoding.EncodeToString(digest.Sum(nil))
+		if err := r.save(ctx, u); err != nil {
+			return err
+		}
+		if u.PendingSize > 0 {
+			if err := r.store.Delete(ctx, bucket, pendingKey(u.ID)); err != nil && !errors.Is(err, ErrNotFound) {
+				return fmt.Errorf("error deleting pending bytes: %w", err)
+			}
+		}
+	}
+
+	if !u.Recorded && r.OnFinish != nil {
+		if err := r.OnFinish(ctx, u.Upload); err != nil {
+			return fmt.Errorf("error registering upload: %w", err)
+		}
+		u.Recorded = true
+		return r.save(ctx, u)
+	}
+	return nil
+}
+
+// Terminate removes an upload and the parts it stored. The file of a finished upload
+// is kept, it was registered like any other.
+func (r *ResumableUploads) Terminate(ctx context.Context, u *ResumableUpload) error {
+	bucket := u.Upload.Bucket
+	if !u.Finished {
+		if err := r.store.AbortMultipart(ctx, bucket, u.Upload.Key, u.MultipartID); err != nil {
+			return err
+		}
+	}
+	if err := r.store.Delete(ctx, bucket, pendingKey(u.ID)); err != nil && !errors.Is(err, ErrNotFound) {
+		return err
+	}
+	if err := r.store.Delete(ctx, bucket, stateKey(u.ID)); err != nil && !errors.Is(err, ErrNotFound) {
+		return err
+	}
+	return nil
+}
+
+// Sweep removes the uploads of the bucket that expired, with the parts of the unfinished
+// ones, and aborts the multipart uploads older than orphanAge no upload refers to, which
+// are left by a gateway stopping between creating an upload and saving its state. It
+// returns the number of unfinished uploads removed.
+func (r *ResumableUploads) Sweep(ctx context.Context, bucket string, now time.Time, orphanAge time.Duration) (int, error) {
+	objects, err := r.store.List(ctx, bucket, resumablePrefix)
+	if errors.Is(err, ErrBucketNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	removed := 0
+	live := make(map[string]bool)
+	for _, object := range objects {
+		id, ok := strings.CutSuffix(strings.TrimPrefix(object.Key, resumablePrefix), ".json")
+		if !ok {
+			continue
+		}
+		u, err := r.Get(ctx, bucket, id)
+		if errors.Is(err, ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return removed, err
+		}
+		if now.Before(u.ExpiresAt) {
+			live[u.MultipartID] = true
+			continue
+		}
+		swept, err := r.sweepUpload(ctx, u)
+		if err != nil {
+			return removed, err
+		}
+		if swept == nil {
+			// A request is still writing it
+			live[u.MultipartID] = true
+			continue
+		}
+		if !swept.Finished {
+			removed++
+		}
+	}
+
+	uploads, err := r.store.ListMultipart(ctx, bucket)
+	if err != nil {
+		return removed, err
+	}
+	for _, upload := range uploads {
+		if live[upload.UploadID] || now.Sub(upload.Initiated) < orphanAge {
+			continue
+		}
+		if err := r.store.AbortMultipart(ctx, bucket, upload.Key, upload.UploadID); err != nil {
+			return removed, fmt.Errorf("error aborting orphaned upload of %s: %w", upload.Key, err)
+		}
+		removed++
+	}
+	return removed, nil
+}
+
+// sweepUpload removes an expired upload under its lock and returns its last state, or
+// nil when it's locked by a request or was removed already
+func (r *ResumableUploads) sweepUpload(ctx context.Context, u *ResumableUpload) (*ResumableUpload, error) {
+	if r.Lock != nil {
+		release, acquired, err := r.Lock(ctx, u)
+		if err != nil || !acquired {
+			return nil, err
+		}
+		defer release()
+
+		// A write may have finished the upload before the lock was taken
+		u, err = r.Get(ctx, u.Upload.Bucket, u.ID)
+		if errors.Is(err, ErrNotFound) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := r.Terminate(ctx, u); err != nil {
+		return nil, fmt.Errorf("error removing upload %s: %w", u.ID, err)
+	}
+	if u.Finished && !u.Recorded {
+		// Nobody could read the file of an upload that was never registered
+		if err := r.store.Delete(ctx, u.Upload.Bucket, u.Upload.Key); err != nil && !errors.Is(err, ErrNotFound) {
+			return nil, fmt.Errorf("error removing file of upload %s: %w", u.ID, err)
+		}
+	}
+	return u, nil
+}
+
+// save writes the state of the upload
+func (r *ResumableUploads) save(ctx context.Context, u *ResumableUpload) error {
+	data, err := json.Marshal(u)
+	if err != nil {
+		return err
+	}
+	if _, err := r.store.Put(ctx, u.Upload.Bucket, stateKey(u.ID), bytes.NewReader(data), int64(len(data)), "application/json"); err != nil {
+		return fmt.Errorf("error saving upload state: %w", err)
+	}
+	return nil
+}
+
+// saveHash writes the state of the upload with the hash of the bytes received so far
+func (r *ResumableUploads) saveHash(ctx context.Context, u *ResumableUpload, digest hash.Hash) error {
+	state, err := digest.(encoding.BinaryMarshaler).MarshalBinary()
+	if err != nil {
+		return err
+	}
+	u.Hash = state
+	return r.save(ctx, u)
+}
+
+// readFull appends exactly size bytes of reader to buf
+func readFull(buf []byte, reader io.Reader, size int64) ([]byte, error) {
+	n, err := io.ReadFull(reader, buf[len(buf):len(buf)+int(size)])
+	return buf[:len(buf)+n], err
+}
+
+func stateKey(id string) string {
+	return resumablePrefix + id + ".json"
+}
+
+func pendingKey(id string) string {
+	return resumablePrefix + id + ".pending"
+}
@@ -0,0 +1,375 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/aws/aws-sdk-go-v2/otelaws"
+)
+
+// S3Config holds the credentials and endpoint of the S3 store.
+type S3Config struct {
+	Region          string
+	AccessKeyID     string
+	SecretAccessKey string
+	// Endpoint replaces the AWS endpoint, e.g. http://minio:9000
+	Endpoint string
+	// ForcePathStyle addresses buckets in the path instead of the host name, as MinIO needs
+	ForcePathStyle bool
+}
+
+// S3Store keeps objects in AWS S3 or an S3 compatible service.
+type S3Store struct {
+	client  *s3.Client
+	presign *s3.PresignClient
+}
+
+// NewS3Store creates the S3 client. Credentials fall back to the default AWS chain
+// (environment, shared config, instance role) when no access key is configured.
+func NewS3Store(ctx context.Context, config S3Config) (*S3Store, error) {
+	if config.Region == "" {
+		return nil, errors.New("S3 region is required")
+	}
+	options := []func(*awsconfig.LoadOptions) error{awsconfig.WithRegion(config.Region)}
+	if config.AccessKeyID != "" {
+		options = append(options, awsconfig.WithCredentialsProvider(aws.NewCredentialsCache(aws.CredentialsProviderFunc(func(ctx context.Context) (aws.Credentials, error) {
+			return aws.Credentials{
+				AccessKeyID:     config.AccessKeyID,
+				SecretAccessKey: config.SecretAccessKey,
+			}, nil
+		}))))
+	}
+	cfg, err := awsconfig.LoadDefaultConfig(ctx, options...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS configuration: %w", err)
+	}
+
+	// Record S3 calls as spans
+	otelaws.AppendMiddlewares(&cfg.APIOptions)
+
+	client := s3.NewFromConfig(cfg, func(o *s3.Options) {
+		if config.Endpoint != "" {
+			o.BaseEndpoint = aws.String(config.Endpoint)
+		}
+		o.UsePathStyle = config.ForcePathStyle
+	})
+	return &S3Store{client: client, presign: s3.NewPresignClient(client)}, nil
+}
+
+// Put uploads the object.
+func (s *S3Store) Put(ctx context.Context, bucket, key string, body io.Reader, size int64, contentType string) (ObjectInfo, error) {
+	if err := validateKey(bucket, key); err != nil {
+		return ObjectInfo{}, err
+	}
+	input := &s3.PutObjectInput{
+		Bucket:      aws.String(bucket),
+		Key:         aws.String(key),
+		Body:        body,
+		ContentType: aws.String(contentType),
+	}
+	if size >= 0 {
+		input.ContentLength = aws.Int64(size)
+	}
+	result, err := s.client.PutObject(ctx, input)
+	if err != nil {
+		return ObjectInfo{}, mapS3Error(err)
+	}
+	// The response doesn't say how much was stored
+	if size < 0 {
+		return s.Head(ctx, bucket, key)
+	}
+	return ObjectInfo{
+		Bucket:         bucket,
+		Key:            key,
+		Size:           size,
+		ContentType:    contentType,
+		ETag:           strings.Trim(aws.ToString(result.ETag), `"`),
+		ChecksumSHA256: aws.ToString(result.ChecksumSHA256),
+		LastModified:   time.Now().UTC(),
+	}, nil
+}
+
+// Get downloads the object or a range of it.
+func (s *S3Store) Get(ctx context.Context, bucket, key string, rng *Range) (*Object, error) {
+	if err := validateKey(bucket, key); err != nil {
+		return nil, err
+	}
+	if rng != nil && (rng.Offset < 0 || rng.Length == 0 || rng.Length < -1) {
+		return nil, ErrInvalidRange
+	}
+	input := &s3.GetObjectInput{Bucket: aws.String(bucket), Key: aws.String(key)}
+	if rng != nil {
+		header := fmt.Sprintf("bytes=%d-", rng.Offset)
+		if rng.Length > 0 {
+			header += strconv.FormatInt(rng.Offset+rng.Length-1, 10)
+		}
+		input.Range = aws.String(header)
+	}
+	result, err := s.client.GetObject(ctx, input)
+	if err != nil {
+		return nil, mapS3Error(err)
+	}
+
+	info := ObjectInfo{
+		Bucket:       bucket,
+		Key:          key,
+		Size:         aws.ToInt64(result.ContentLength),
+		ContentType:  aws.ToString(result.ContentType),
+		ETag:         strings.Trim(aws.ToString(result.ETag), `"`),
+		LastModified: aws.ToTime(result.LastModified),
+	}
+	object := &Object{ReadCloser: result.Body, Info: info}
+	// A range response carries the size of the whole object in Content-Range
+	if result.ContentRange != nil {
+		var start, end, total int64
+		if _, err := fmt.Sscanf(*result.ContentRange, "bytes %d-%d/%d", &start, &end, &total); err == nil {
+			object.Info.Size = total
+			object.Range = &Range{Offset: start, Length: end - start + 1}
+		}
+	}
+	return object, nil
+}
+
+// Head describes the object, with the SHA-256 checksum S3 verified when it was uploaded with one.
+func (s *S3Store) Head(ctx context.Context, bucket, key string) (ObjectInfo, error) {
+	if err := validateKey(bucket, key); err != nil {
+		return ObjectInfo{}, err
+	}
+	result, err := s.client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket:       aws.String(bucket),
+		Key:          aws.String(key),
+		ChecksumMode: types.ChecksumModeEnabled,
+	})
+	if err != nil {
+		return ObjectInfo{}, mapS3Error(err)
+	}
+	return ObjectInfo{
+		Bucket:         bucket,
+		Key:            key,
+		Size:           aws.ToInt64(result.ContentLength),
+		ContentType:    aws.ToString(result.ContentType),
+		ETag:           strings.Trim(aws.ToString(result.ETag), `"`),
+		ChecksumSHA256: aws.ToString(result.ChecksumSHA256),
+		LastModified:   aws.ToTime(result.LastModified),
+	}, nil
+}
+
+// Delete removes the object.
+func (s *S3Store) Delete(ctx context.Context, bucket, key string) error {
+	if err := validateKey(bucket, key); err != nil {
+		return err
+	}
+	_, err := s.client.DeleteObject(ctx, &s3.DeleteObjectInput{Bucket: aws.String(bucket), Key: aws.String(key)})
+	return mapS3Error(err)
+}
+
+// List pages through the objects whose key starts with prefix, S3 returns them sorted by key.
+func (s *S3Store) List(ctx context.Context, bucket, prefix string) ([]ObjectInfo, error) {
+	var infos []ObjectInfo
+	paginator := s3.NewListObjectsV2Paginator(s.client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(bucket),
+		Prefix: aws.String(prefix),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, mapS3Error(err)
+		}
+		for _, object := range page.Contents {
+			infos = append(infos, ObjectInfo{
+				Bucket:       bucket,
+				Key:          aws.ToString(object.Key),
+				Size:         aws.ToInt64(object.Size),
+				ETag:         strings.Trim(aws.ToString(object.ETag), `"`),
+				LastModified: aws.ToTime(object.LastModified),
+			})
+		}
+	}
+	return infos, nil
+}
+
+// PresignGet returns a presigned S3 URL downloading the object.
+func (s *S3Store) PresignGet(ctx context.Context, bucket, key string, expires time.Duration) (string, error) {
+	if err := validateKey(bucket, key); err != nil {
+		return "", err
+	}
+	request, err := s.presign.PresignGetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	}, s3.WithPresignExpires(expires))
+	if err != nil {
+		return "", err
+	}
+	return request.URL, nil
+}
+
+// PresignPut returns a presigned S3 URL uploading the object. The content type and length
+// are signed headers and S3 rejects bodies whose SHA-256 doesn't match the signed checksum.
+func (s *S3Store) PresignPut(ctx context.Context, bucket, key string, conditions PutConditions, expires time.Duration) (PresignedPut, error) {
+	if err := validateKey(bucket, key); err != nil {
+		return PresignedPut{}, err
+	}
+	input := &s3.PutObjectInput{
+		Bucket:        aws.String(bucket),
+		Key:           aws.String(key),
+		ContentType:   aws.String(conditions.ContentType),
+		ContentLength: aws.Int64(conditions.Size),
+	}
+	if conditions.ChecksumSHA256 != "" {
+		input.ChecksumSHA256 = aws.String(conditions.ChecksumSHA256)
+	}
+	request, err := s.presign.PresignPutObject(ctx, input, s3.WithPresignExpires(expires))
+	if err != nil {
+		return PresignedPut{}, err
+	}
+	// Clients set Host and Content-Length themselves, browsers refuse to
+	header := request.SignedHeader.Clone()
+	header.Del("Host")
+	header.Del("Content-Length")
+	return PresignedPut{URL: request.URL, Header: header}, nil
+}
+
+// CreateMultipart starts an S3 multipart upload.
+func (s *S3Store) CreateMultipart(ctx context.Context, bucket, key, contentType string) (string, error) {
+	if err := validateKey(bucket, key); err != nil {
+		return "", err
+	}
+	result, err := s.client.CreateMultipartUpload(ctx, &s3.CreateMultipartUploadInput{
+		Bucket:      aws.String(bucket),
+		Key:         aws.String(key),
+		ContentType: aws.String(contentType),
+	})
+	if err != nil {
+		return "", mapS3Error(err)
+	}
+	return aws.ToString(result.UploadId), nil
+}
+
+// UploadPart uploads a part, body should be seekable for S3 to sign it.
+func (s *S3Store) UploadPart(ctx context.Context, bucket, key, uploadID string, number int32, body io.Reader, size int64) (Part, error) {
+	if err := validateKey(bucket, key); err != nil {
+		return Part{}, err
+	}
+	result, err := s.client.UploadPart(ctx, &s3.UploadPartInput{
+		Bucket:        aws.String(bucket),
+		Key:           aws.String(key),
+		UploadId:      aws.String(uploadID),
+		PartNumber:    aws.Int32(number),
+		Body:          body,
+		ContentLength: aws.Int64(size),
+	})
+	if err != nil {
+		return Part{}, mapS3Error(err)
+	}
+	return Part{Number: number, ETag: strings.Trim(aws.ToString(result.ETag), `"`), Size: size}, nil
+}
+
+// CompleteMultipart asks S3 to join the parts.
+func (s *S3Store) CompleteMultipart(ctx context.Context, bucket, key, uploadID string, parts []Part) (ObjectInfo, error) {
+	if err := validateKey(bucket, key); err != nil {
+		return ObjectInfo{}, err
+	}
+	completed := make([]types.CompletedPart, 0, len(parts))
+	for _, part := range parts {
+		completed = append(completed, types.CompletedPart{
+			ETag:       aws.String(strconv.Quote(part.ETag)),
+			PartNumber: aws.Int32(part.Number),
+		})
+	}
+	_, err := s.client.CompleteMultipartUpload(ctx, &s3.CompleteMultipartUploadInput{
+		Bucket:          aws.String(bucket),
+		Key:             aws.String(key),
+		UploadId:        aws.String(uploadID),
+		MultipartUpload: &types.CompletedMultipartUpload{Parts: completed},
+	})
+	if err != nil {
+		return ObjectInfo{}, mapS3Error(err)
+	}
+	return s.Head(ctx, bucket, key)
+}
+
+// AbortMultipart aborts the upload, S3 then deletes its parts.
+func (s *S3Store) AbortMultipart(ctx context.Context, bucket, key, uploadID string) error {
+	if err := validateKey(bucket, key); err != nil {
+		return err
+	}
+	_, err := s.client.AbortMultipartUpload(ctx, &s3.AbortMultipartUploadInput{
+		Bucket:   aws.String(bucket),
+		Key:      aws.String(key),
+		UploadId: aws.String(uploadID),
+	})
+	if err = mapS3Error(err); errors.Is(err, ErrNotFound) {
+		return nil
+	}
+	return err
+}
+
+// ListMultipart pages through the open multipart uploads of the bucket.
+func (s *S3Store) ListMultipart(ctx context.Context, bucket string) ([]MultipartUpload, error) {
+	var uploads []MultipartUpload
+	input := &s3.ListMultipartUploadsInput{Bucket: aws.String(bucket)}
+	for {
+		result, err := s.client.ListMultipartUploads(ctx, input)
+		if err != nil {
+			return nil, mapS3Error(err)
+		}
+		for _, upload := range result.Uploads {
+			uploads = append(uploads, MultipartUpload{
+				Key:       aws.ToString(upload.Key),
+				UploadID:  aws.ToString(upload.UploadId),
+				Initiated: aws.ToTime(upload.Initiated),
+			})
+		}
+		if !aws.ToBool(result.IsTruncated) {
+			return uploads, nil
+		}
+		input.KeyMarker = result.NextKeyMarker
+		input.UploadIdMarker = result.NextUploadIdMarker
+	}
+}
+
+// Ping lists the buckets, which checks the endpoint and the credentials.
+func (s *S3Store) Ping(ctx context.Context) error {
+	_, err := s.client.ListBuckets(ctx, &s3.ListBucketsInput{})
+	return err
+}
+
+// Buckets returns the names of the buckets the credentials can see.
+func (s *S3Store) Buckets(ctx context.Context) ([]string, error) {
+	result, err := s.client.ListBuckets(ctx, &s3.ListBucketsInput{})
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(result.Buckets))
+	for _, bucket := range result.Buckets {
+		names = append(names, aws.ToString(bucket.Name))
+	}
+	return names, nil
+}
+
+// mapS3Error turns S3's error codes into the errors of BlobStore
+func mapS3Error(err error) error {
+	var apiErr smithy.APIError
+	if err == nil || !errors.As(err, &apiErr) {
+		return err
+	}
+	switch apiErr.ErrorCode() {
+	case "NoSuchKey", "NotFound", "NoSuchUpload":
+		return fmt.Errorf("%w: %v", ErrNotFound, err)
+	case "NoSuchBucket":
+		return fmt.Errorf("%w: %v", ErrBucketNotFound, err)
+	case "InvalidRange":
+		return fmt.Errorf("%w: %v", ErrInvalidRange, err)
+	}
+	return err
+}
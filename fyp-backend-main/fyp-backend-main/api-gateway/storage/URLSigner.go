@@ -0,0 +1,94 @@
+package storage
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Query parameters of signed URLs
+const (
+	expiresParam   = "X-Expires"
+	signatureParam = "X-Signature"
+	// ChecksumParam carries the SHA-256 an upload URL was signed for, the body must match it
+	ChecksumParam = "X-Checksum-Sha256"
+)
+
+// ErrInvalidSignature is returned for signed URLs that were altered or expired.
+var ErrInvalidSignature = errors.New("invalid or expired signature")
+
+// URLSigner signs the URLs handed out by the local and memory stores, which the
+// gateway serves under baseURL. A URL is only valid for the method and object it
+// was signed for and, for uploads, the content type, size and checksum.
+type URLSigner struct {
+	baseURL string
+	key     []byte
+}
+
+// NewURLSigner returns a signer for URLs under baseURL. Without a key a random one
+// is generated, URLs signed before a restart are then rejected.
+func NewURLSigner(baseURL, key string) (*URLSigner, error) {
+	secret := []byte(key)
+	if key == "" {
+		secret = make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			return nil, err
+		}
+	}
+	return &URLSigner{baseURL: strings.TrimSuffix(baseURL, "/"), key: secret}, nil
+}
+
+// Sign returns the URL of the object, valid for the method until expires. Downloads
+// pass empty conditions.
+func (s *URLSigner) Sign(method, bucket, key string, conditions PutConditions, expires time.Time) string {
+	segments := strings.Split(key, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	query := url.Values{}
+	query.Set(expiresParam, strconv.FormatInt(expires.Unix(), 10))
+	if conditions.ChecksumSHA256 != "" {
+		query.Set(ChecksumParam, conditions.ChecksumSHA256)
+	}
+	query.Set(signatureParam, s.signature(method, bucket, key, conditions, expires.Unix()))
+	return s.baseURL + "/" + url.PathEscape(bucket) + "/" + strings.Join(segments, "/") + "?" + query.Encode()
+}
+
+// Verify checks the query of a signed URL against the request made with it. The
+// checksum is read from the query, the body must still be checked against it.
+func (s *URLSigner) Verify(method, bucket, key string, conditions PutConditions, query url.Values) error {
+	expires, err := strconv.ParseInt(query.Get(expiresParam), 10, 64)
+	if err != nil || time.Now().Unix() > expires {
+		return ErrInvalidSignature
+	}
+	signature, err := hex.DecodeString(query.Get(signatureParam))
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	conditions.ChecksumSHA256 = query.Get(ChecksumParam)
+	expected, _ := hex.DecodeString(s.signature(method, bucket, key, conditions, expires))
+	if !hmac.Equal(signature, expected) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+func (s *URLSigner) signature(method, bucket, key string, conditions PutConditions, expires int64) string {
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write([]byte(strings.Join([]string{
+		method,
+		bucket,
+		key,
+		conditions.ContentType,
+		strconv.FormatInt(conditions.Size, 10),
+		conditions.ChecksumSHA256,
+		strconv.FormatInt(expires, 10),
+	}, "\n")))
+	return hex.EncodeToString(mac.Sum(nil))
+}
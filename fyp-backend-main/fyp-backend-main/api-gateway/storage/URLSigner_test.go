@@ -0,0 +1,148 @@
+package storage
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testSigningKey = "0123456789abcdef0123456789abcdef"
+
+func TestURLSignerVerify(t *testing.T) {
+	signer, err := NewURLSigner("http://gateway/blobs/", testSigningKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := NewURLSigner("http://gateway/blobs", "another key of at least 32 chars")
+	if err != nil {
+		t.Fatal(err)
+	}
+	conditions := PutConditions{ContentType: "application/pdf", Size: 42, ChecksumSHA256: "n4bQgYhMfWWaL+qgxVrQFaO/TxsrC4Is0V1sFbDwCgg="}
+	expires := time.Now().Add(time.Minute)
+
+	tests := []struct {
+		name       string
+		signer     *URLSigner
+		method     string
+		bucket     string
+		key        string
+		conditions PutConditions
+		// tamper changes the query of the signed URL
+		tamper func(query url.Values)
+		valid  bool
+	}{
+		{"as signed", signer, http.MethodPut, "bucket", "dir/report 1.pdf", conditions, nil, true},
+		{"other method", signer, http.MethodGet, "bucket", "dir/report 1.pdf", conditions, nil, false},
+		{"other bucket", signer, http.MethodPut, "bucket2", "dir/report 1.pdf", conditions, nil, false},
+		{"other key", signer, http.MethodPut, "bucket", "dir/report 2.pdf", conditions, nil, false},
+		{"other content type", signer, http.MethodPut, "bucket", "dir/report 1.pdf", PutConditions{ContentType: "text/html", Size: 42}, nil, false},
+		{"other size", signer, http.MethodPut, "bucket", "dir/report 1.pdf", PutConditions{ContentType: "application/pdf", Size: 43}, nil, false},
+		{"other signing key", other, http.MethodPut, "bucket", "dir/report 1.pdf", conditions, nil, false},
+		{"checksum changed", signer, http.MethodPut, "bucket", "dir/report 1.pdf", conditions, func(query url.Values) {
+			query.Set(ChecksumParam, "47DEQpj8HBSa+/TImW+5JCeuQeRkm5NMpJWZG3hSuFU=")
+		}, false},
+		{"checksum removed", signer, http.MethodPut, "bucket", "dir/report 1.pdf", conditions, func(query url.Values) {
+			query.Del(ChecksumParam)
+		}, false},
+		{"expiry extended", signer, http.MethodPut, "bucket", "dir/report 1.pdf", conditions, func(query url.Values) {
+			query.Set(expiresParam, strconv.FormatInt(expires.Add(time.Hour).Unix(), 10))
+		}, false},
+		{"expiry missing", signer, http.MethodPut, "bucket", "dir/report 1.pdf", conditions, func(query url.Values) {
+			query.Del(expiresParam)
+		}, false},
+		{"signature changed", signer, http.MethodPut, "bucket", "dir/report 1.pdf", conditions, func(query url.Values) {
+			signature := []byte(query.Get(signatureParam))
+			signature[0] ^= 1
+			query.Set(signatureParam, string(signature))
+		}, false},
+		{"signature truncated", signer, http.MethodPut, "bucket", "dir/report 1.pdf", conditions, func(query url.Values) {
+			query.Set(signatureParam, query.Get(signatureParam)[:32])
+		}, false},
+		{"signature not hex", signer, http.MethodPut, "bucket", "dir/report 1.pdf", conditions, func(query url.Values) {
+			query.Set(signatureParam, "not hex")
+		}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query := signedQuery(t, signer, http.MethodPut, "bucket", "dir/report 1.pdf", conditions, expires)
+			if tt.tamper != nil {
+				tt.tamper(query)
+			}
+			// The checksum comes from the query, as when the gateway serves the URL
+			err := tt.signer.Verify(tt.method, tt.bucket, tt.key, PutConditions{ContentType: tt.conditions.ContentType, Size: tt.conditions.Size}, query)
+			if tt.valid && err != nil {
+				t.Fatalf("Verify() = %v, want nil", err)
+			}
+			if !tt.valid && !errors.Is(err, ErrInvalidSignature) {
+				t.Fatalf("Verify() = %v, want ErrInvalidSignature", err)
+			}
+		})
+	}
+}
+
+func TestURLSignerExpiry(t *testing.T) {
+	signer, err := NewURLSigner("http://gateway/blobs", testSigningKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name    string
+		expires time.Time
+		valid   bool
+	}{
+		{"valid for a minute", time.Now().Add(time.Minute), true},
+		{"expired a second ago", time.Now().Add(-time.Second), false},
+		{"expired an hour ago", time.Now().Add(-time.Hour), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query := signedQuery(t, signer, http.MethodGet, "bucket", "file", PutConditions{}, tt.expires)
+			err := signer.Verify(http.MethodGet, "bucket", "file", PutConditions{}, query)
+			if tt.valid && err != nil {
+				t.Fatalf("Verify() = %v, want nil", err)
+			}
+			if !tt.valid && !errors.Is(err, ErrInvalidSignature) {
+				t.Fatalf("Verify() = %v, want ErrInvalidSignature", err)
+			}
+		})
+	}
+}
+
+func TestURLSignerRandomKey(t *testing.T) {
+	// Without a key each signer makes up its own, so their URLs don't verify elsewhere
+	first, err := NewURLSigner("http://gateway/blobs", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := NewURLSigner("http://gateway/blobs", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	query := signedQuery(t, first, http.MethodGet, "bucket", "file", PutConditions{}, time.Now().Add(time.Minute))
+	if err := first.Verify(http.MethodGet, "bucket", "file", PutConditions{}, query); err != nil {
+		t.Fatalf("Verify() by the signer = %v, want nil", err)
+	}
+	if err := second.Verify(http.MethodGet, "bucket", "file", PutConditions{}, query); !errors.Is(err, ErrInvalidSignature) {
+		t.Fatalf("Verify() by another signer = %v, want ErrInvalidSignature", err)
+	}
+}
+
+// signedQuery signs the URL and checks it points at the object under the base URL
+func signedQuery(t *testing.T, signer *URLSigner, method, bucket, key string, conditions PutConditions, expires time.Time) url.Values {
+	t.Helper()
+	signed, err := url.Parse(signer.Sign(method, bucket, key, conditions, expires))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "/blobs/" + bucket + "/" + key; signed.Path != want {
+		t.Fatalf("signed URL path = %q, want %q", signed.Path, want)
+	}
+	if strings.Contains(signed.RawQuery, testSigningKey) {
+		t.Fatal("signed URL contains the signing key")
+	}
+	return signed.Query()
+}
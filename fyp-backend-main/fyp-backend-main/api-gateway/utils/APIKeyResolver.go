@@ -0,0 +1,147 @@
+package utils
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"log/slog"
+	"sync"
+	"time"
+
+	auth_service "api-gateway/proto/generated/github.com/multiagentai/backend/auth-service"
+)
+
+// APIKeyPrefix starts every API key issued by the auth service.
+const APIKeyPrefix = "pat_"
+
+// apiKeyCacheTTL bounds how long a revoked key keeps working at a gateway instance that
+// missed the revocation
+const apiKeyCacheTTL = 30 * time.Second
+
+// Redis channel the IDs of revoked keys are published on, so every instance forgets them
+const apiKeyRevokedChannel = "gateway:apikey:revoked"
+
+// APIKeyPrincipal is what an API key resolves to.
+type APIKeyPrincipal struct {
+	UserID string
+	Scopes []string
+	KeyID  string
+}
+
+type cachedPrincipal struct {
+	principal *APIKeyPrincipal
+	expiresAt time.Time
+}
+
+// APIKeyResolver resolves API keys through the auth service, caching successful lookups briefly.
+type APIKeyResolver struct {
+	client auth_service.AuthServiceClient
+	mu     sync.Mutex
+	cache  map[string]cachedPrincipal
+}
+
+// APIKeys is the resolver used by AuthMiddleware, initialized in main.
+var APIKeys *APIKeyResolver
+
+// NewAPIKeyResolver returns a resolver backed by the auth service.
+func NewAPIKeyResolver(client auth_service.AuthServiceClient) *APIKeyResolver {
+	return &APIKeyResolver{client: client, cache: map[string]cachedPrincipal{}}
+}
+
+// Resolve returns the user and scopes of an API key. Errors are gRPC status errors from the auth service.
+func (r *APIKeyResolver) Resolve(ctx context.Context, key string) (*APIKeyPrincipal, error) {
+	cacheKey := apiKeyCacheKey(key)
+	if principal, ok := r.cached(cacheKey); ok {
+		return principal, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	res, err := r.client.ResolveAPIKey(ctx, &auth_service.ResolveAPIKeyRequest{Key: key})
+	if err != nil {
+		return nil, err
+	}
+	principal := &APIKeyPrincipal{UserID: res.GetUserId(), Scopes: res.GetScopes(), KeyID: res.GetKeyId()}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	// Drop expired entries now and then so the cache doesn't grow without bound
+	if len(r.cache) >= 1000 {
+		now := time.Now()
+		for k, v := range r.cache {
+			if now.After(v.expiresAt) {
+				delete(r.cache, k)
+			}
+		}
+	}
+	r.cache[cacheKey] = cachedPrincipal{principal: principal, expiresAt: time.Now().Add(apiKeyCacheTTL)}
+	return principal, nil
+}
+
+// Cached reports whether the key was resolved recently, so Resolve won't call the auth service.
+func (r *APIKeyResolver) Cached(key string) bool {
+	_, ok := r.cached(apiKeyCacheKey(key))
+	return ok
+}
+
+// Forget drops cached lookups of a key, used after it is revoked. Other gateway instances
+// are told over Redis, those missing the message forget the key when their cache expires.
+func (r *APIKeyResolver) Forget(ctx context.Context, keyID string) {
+	r.forget(keyID)
+	if RDB == nil {
+		return
+	}
+	if err := RDB.Publish(ctx, apiKeyRevokedChannel, keyID).Err(); err != nil {
+		slog.WarnContext(ctx, "Failed to publish revoked API key", "key_id", keyID, "error", err)
+	}
+}
+
+// WatchRevocations forgets the keys revoked at other gateway instances until ctx is done.
+func (r *APIKeyResolver) WatchRevocations(ctx context.Context) {
+	if RDB == nil {
+		return
+	}
+	pubsub := RDB.Subscribe(ctx, apiKeyRevokedChannel)
+	defer pubsub.Close()
+	// The channel is kept open across reconnections to Redis
+	messages := pubsub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case message, ok := <-messages:
+			if !ok {
+				return
+			}
+			r.forget(message.Payload)
+		}
+	}
+}
+
+// cached returns the principal of a recent lookup
+func (r *APIKeyResolver) cached(cacheKey string) (*APIKeyPrincipal, bool) {
+	r.mu.Lock()
+	cached, ok := r.cache[cacheKey]
+	r.mu.Unlock()
+	if !ok || !time.Now().Before(cached.expiresAt) {
+		return nil, false
+	}
+	return cached.principal, true
+}
+
+// forget drops the cached lookups of a key at this instance
+func (r *APIKeyResolver) forget(keyID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for k, v := range r.cache {
+		if v.principal.KeyID == keyID {
+			delete(r.cache, k)
+		}
+	}
+}
+
+// apiKeyCacheKey hashes the key, so keys aren't kept in memory
+func apiKeyCacheKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,57 @@
+package utils
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Redis keys of the locks held by gateway instances
+const lockKeyPrefix = "gateway:lock:"
+
+// releaseLockScript deletes the lock only if it's still held with the token, so a lock
+// that expired and was taken by another instance is left alone
+var releaseLockScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// AcquireLock takes the named lock shared by every gateway instance for at most ttl and
+// returns the function releasing it, or false when another request holds it. The locks
+// are kept in Redis, which the gateway doesn't start without.
+func AcquireLock(ctx context.Context, name string, ttl time.Duration) (func(), bool, error) {
+	token := make([]byte, 16)
+	if _, err := rand.Read(token); err != nil {
+		return nil, false, err
+	}
+	value := hex.EncodeToString(token)
+	if RDB == nil {
+		return nil, false, fmt.Errorf("failed to acquire lock %s: Redis is not connected", name)
+	}
+
+	key := lockKeyPrefix + name
+	acquired, err := RDB.SetNX(ctx, key, value, ttl).Result()
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to acquire lock %s: %w", name, err)
+	}
+	if !acquired {
+		return nil, false, nil
+	}
+
+	release := func() {
+		// Release even when the request was cancelled
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), time.Second)
+		defer cancel()
+		if err := releaseLockScript.Run(ctx, RDB, []string{key}, value).Err(); err != nil {
+			slog.WarnContext(ctx, "Failed to release lock", "lock", name, "error", err)
+		}
+	}
+	return release, true, nil
+}
@@ -1,44 +1,92 @@
 package utils
 
 import (
+	"log/slog"
 	"net/http"
 	"strings"
 
+	"shared/logging"
+
 	"github.com/gin-gonic/gin"
 )
 
-// AuthMiddleware verifies the JWT token in the Authorization header.
+// AuthMiddleware verifies the JWT token in the Authorization header, or an API key
+// sent as "X-API-Key: pat_..." or "Authorization: Bearer pat_...".
 func AuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
+		// API key in its own header
+		if apiKey := c.GetHeader("X-API-Key"); apiKey != "" {
+			authenticateAPIKey(c, apiKey)
+			return
+		}
+
 		// Get the Authorization header
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header is required"})
-			c.Abort()
+			RespondWithError(c, http.StatusUnauthorized, CodeUnauthenticated, "Authorization header is required")
 			return
 		}
 
 		// Check if the Authorization header contains "Bearer <token>"
 		parts := strings.Split(authHeader, " ")
 		if len(parts) != 2 || parts[0] != "Bearer" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid Authorization header format"})
-			c.Abort()
+			RespondWithError(c, http.StatusUnauthorized, CodeUnauthenticated, "Invalid Authorization header format")
 			return
 		}
 
 		// Extract the token from the header
 		tokenString := parts[1]
+		if strings.HasPrefix(tokenString, APIKeyPrefix) {
+			authenticateAPIKey(c, tokenString)
+			return
+		}
 
 		// Verify the token using ValidateToken
-		userID, err := ValidateToken(tokenString)
+		claims, err := ValidateToken(tokenString)
 		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
-			c.Abort()
+			RespondWithError(c, http.StatusUnauthorized, CodeUnauthenticated, err.Error())
+			return
+		}
+
+		// Reject tokens revoked by a logout
+		if IsTokenRevoked(c.Request.Context(), &claims.RegisteredClaims) {
+			RespondWithError(c, http.StatusUnauthorized, CodeUnauthenticated, "token has been revoked")
 			return
 		}
 
-		// Pass the user ID to the next handler
-		c.Set("userID", userID)
+		// Pass the user ID and the token to the next handler
+		c.Set("userID", claims.Subject)
+		c.Set("authMethod", AuthMethodSession)
+		c.Set("accessToken", tokenString)
+		c.Set("authTime", claims.AuthTime)
+		c.Request = c.Request.WithContext(logging.With(c.Request.Context(), slog.String("user_id", claims.Subject)))
 		c.Next()
 	}
 }
+
+// authenticateAPIKey resolves an API key to its user and scopes
+func authenticateAPIKey(c *gin.Context, apiKey string) {
+	if APIKeys == nil || !strings.HasPrefix(apiKey, APIKeyPrefix) {
+		RespondWithError(c, http.StatusUnauthorized, CodeUnauthenticated, "invalid API key")
+		return
+	}
+
+	// Guessed keys are never cached, so every guess is counted against the client IP
+	if !APIKeys.Cached(apiKey) && !allowRequest(c, RateLimitAPIKey, "ip:"+c.ClientIP()) {
+		return
+	}
+
+	principal, err := APIKeys.Resolve(c.Request.Context(), apiKey)
+	if err != nil {
+		RespondWithGRPCError(c, err)
+		return
+	}
+
+	// Pass the user ID and the granted scopes to the next handler
+	c.Set("userID", principal.UserID)
+	c.Set("authMethod", AuthMethodAPIKey)
+	c.Set("scopes", principal.Scopes)
+	c.Set("apiKeyID", principal.KeyID)
+	c.Request = c.Request.WithContext(logging.With(c.Request.Context(), slog.String("user_id", principal.UserID)))
+	c.Next()
+}
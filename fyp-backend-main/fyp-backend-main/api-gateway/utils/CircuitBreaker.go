@@ -0,0 +1,103 @@
+package utils
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"api-gateway/metrics"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Health checks report the state of the service themselves, /readyz must see it
+const healthMethodPrefix = "/grpc.health.v1.Health/"
+
+// CircuitBreaker makes calls to a backend fail fast once it is unavailable. After
+// the configured number of consecutive UNAVAILABLE calls it opens and rejects calls
+// for the cooldown, then lets a single call through: the breaker closes when it
+// succeeds and opens again when it fails.
+type CircuitBreaker struct {
+	service   string
+	threshold int
+	cooldown  time.Duration
+
+	mu       sync.Mutex
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+// NewCircuitBreaker creates a closed breaker for the service.
+func NewCircuitBreaker(service string, threshold int, cooldown time.Duration) *CircuitBreaker {
+	metrics.CircuitBreakerOpen.WithLabelValues(service).Set(0)
+	return &CircuitBreaker{service: service, threshold: threshold, cooldown: cooldown}
+}
+
+// UnaryClientInterceptor rejects calls with UNAVAILABLE while the breaker is open.
+// Retries happen below interceptors, so only calls that failed every attempt count.
+func (b *CircuitBreaker) UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if strings.HasPrefix(method, healthMethodPrefix) {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+		if !b.allow() {
+			return status.Errorf(codes.Unavailable, "%s is unavailable, try again later", b.service)
+		}
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		b.record(status.Code(err))
+		return err
+	}
+}
+
+// allow reports whether a call may be made, letting one probe through after the cooldown
+func (b *CircuitBreaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.openedAt.IsZero() {
+		return true
+	}
+	if b.probing || time.Since(b.openedAt) < b.cooldown {
+		return false
+	}
+	b.probing = true
+	return true
+}
+
+// record counts the outcome of a call. Calls the caller gave up on say nothing
+// about the service, they only free the probe slot.
+func (b *CircuitBreaker) record(code codes.Code) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if code == codes.Canceled || code == codes.DeadlineExceeded {
+		b.probing = false
+		return
+	}
+	wasOpen := !b.openedAt.IsZero()
+	if code != codes.Unavailable {
+		b.failures = 0
+		b.probing = false
+		if wasOpen {
+			b.openedAt = time.Time{}
+			metrics.CircuitBreakerOpen.WithLabelValues(b.service).Set(0)
+			slog.Info("Circuit breaker closed", "service", b.service)
+		}
+		return
+	}
+
+	b.failures++
+	if b.probing || b.failures >= b.threshold {
+		// A failed probe restarts the cooldown
+		b.openedAt = time.Now()
+		b.probing = false
+		if !wasOpen {
+			metrics.CircuitBreakerOpen.WithLabelValues(b.service).Set(1)
+			slog.Warn("Circuit breaker opened", "service", b.service, "failures", b.failures, "cooldown", b.cooldown.String())
+		}
+	}
+}
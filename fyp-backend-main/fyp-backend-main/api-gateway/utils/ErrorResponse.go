@@ -0,0 +1,140 @@
+package utils
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Machine-readable error codes returned in the "code" field of the error envelope.
+const (
+	CodeInvalidArgument    = "INVALID_ARGUMENT"
+	CodeUnauthenticated    = "UNAUTHENTICATED"
+	CodePermissionDenied   = "PERMISSION_DENIED"
+	CodeNotFound           = "NOT_FOUND"
+	CodeAlreadyExists      = "ALREADY_EXISTS"
+	CodeAborted            = "ABORTED"
+	CodeFailedPrecondition = "FAILED_PRECONDITION"
+	CodeResourceExhausted  = "RESOURCE_EXHAUSTED"
+	CodeCanceled           = "CANCELED"
+	CodeDeadlineExceeded   = "DEADLINE_EXCEEDED"
+	CodeUnimplemented      = "UNIMPLEMENTED"
+	CodeUnavailable        = "UNAVAILABLE"
+	CodeInternal           = "INTERNAL"
+)
+
+// StatusClientClosedRequest is the non-standard status used when the client went away.
+const StatusClientClosedRequest = 499
+
+// FieldViolation describes a single invalid field reported by a backend service.
+type FieldViolation struct {
+	Field       string `json:"field"`
+	Description string `json:"description"`
+}
+
+// ErrorBody is the payload of the JSON error envelope.
+type ErrorBody struct {
+	Code      string           `json:"code"`
+	Message   string           `json:"message"`
+	RequestID string           `json:"request_id,omitempty"`
+	Reason    string           `json:"reason,omitempty"`
+	Fields    []FieldViolation `json:"fields,omitempty"`
+}
+
+// ErrorResponse is the JSON error envelope returned by every route: {"error": {...}}.
+type ErrorResponse struct {
+	Error ErrorBody `json:"error"`
+}
+
+type grpcMapping struct {
+	httpStatus int
+	code       string
+}
+
+var grpcMappings = map[codes.Code]grpcMapping{
+	codes.InvalidArgument:    {http.StatusBadRequest, CodeInvalidArgument},
+	codes.OutOfRange:         {http.StatusBadRequest, CodeInvalidArgument},
+	codes.FailedPrecondition: {http.StatusBadRequest, CodeFailedPrecondition},
+	codes.Unauthenticated:    {http.StatusUnauthorized, CodeUnauthenticated},
+	codes.PermissionDenied:   {http.StatusForbidden, CodePermissionDenied},
+	codes.NotFound:           {http.StatusNotFound, CodeNotFound},
+	codes.AlreadyExists:      {http.StatusConflict, CodeAlreadyExists},
+	codes.Aborted:            {http.StatusConflict, CodeAborted},
+	codes.ResourceExhausted:  {http.StatusTooManyRequests, CodeResourceExhausted},
+	codes.Canceled:           {StatusClientClosedRequest, CodeCanceled},
+	codes.DeadlineExceeded:   {http.StatusGatewayTimeout, CodeDeadlineExceeded},
+	codes.Unimplemented:      {http.StatusNotImplemented, CodeUnimplemented},
+	codes.Unavailable:        {http.StatusServiceUnavailable, CodeUnavailable},
+}
+
+// HTTPStatusFromCode returns the HTTP status and envelope code for a gRPC status code.
+func HTTPStatusFromCode(code codes.Code) (int, string) {
+	if m, ok := grpcMappings[code]; ok {
+		return m.httpStatus, m.code
+	}
+	return http.StatusInternalServerError, CodeInternal
+}
+
+// RespondWithError aborts the request and writes the JSON error envelope.
+func RespondWithError(c *gin.Context, httpStatus int, code string, message string) {
+	c.AbortWithStatusJSON(httpStatus, ErrorResponse{
+		Error: ErrorBody{
+			Code:      code,
+			Message:   message,
+			RequestID: GetRequestID(c),
+		},
+	})
+}
+
+// RespondWithGRPCError translates an error returned by a gRPC stub into the
+// matching HTTP status and JSON error envelope.
+func RespondWithGRPCError(c *gin.Context, err error) {
+	st, ok := status.FromError(err)
+	if !ok {
+		// Plain context errors (e.g. a deadline hit before the call was sent)
+		st = status.FromContextError(err)
+	}
+	httpStatus, code := HTTPStatusFromCode(st.Code())
+
+	body := ErrorBody{
+		Code:      code,
+		Message:   st.Message(),
+		RequestID: GetRequestID(c),
+	}
+
+	// Don't leak internal error messages to the client
+	if httpStatus == http.StatusInternalServerError {
+		slog.ErrorContext(c.Request.Context(), "Request failed", "error", err)
+		body.Message = "internal server error"
+	}
+
+	// Copy over structured details sent by the backend service
+	for _, detail := range st.Details() {
+		switch d := detail.(type) {
+		case *errdetails.BadRequest:
+			for _, v := range d.GetFieldViolations() {
+				body.Fields = append(body.Fields, FieldViolation{
+					Field:       v.GetField(),
+					Description: v.GetDescription(),
+				})
+			}
+		case *errdetails.ErrorInfo:
+			body.Reason = d.GetReason()
+		case *errdetails.RetryInfo:
+			if delay := d.GetRetryDelay(); delay != nil {
+				seconds := int(delay.AsDuration().Seconds())
+				if seconds < 1 {
+					seconds = 1
+				}
+				c.Header("Retry-After", strconv.Itoa(seconds))
+			}
+		}
+	}
+
+	c.AbortWithStatusJSON(httpStatus, ErrorResponse{Error: body})
+}
@@ -0,0 +1,13 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+
+	"api-gateway/config"
+)
+
+// FileURL returns the canonical URL of an uploaded file, served by GetFile
+func FileURL(namespace, key string) string {
+	return fmt.Sprintf("%s/docs/%s/%s", strings.TrimSuffix(config.Current.GatewayAddress, "/"), namespace, key)
+}
@@ -0,0 +1,77 @@
+package utils
+
+import (
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"time"
+
+	"shared/identity"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// InternalAssertionTTL is how long a signed assertion is accepted by the backend services.
+const InternalAssertionTTL = time.Minute
+
+// InternalIdentity is who the gateway is calling a backend service for.
+// An empty UserID means an anonymous call (e.g. Login).
+type InternalIdentity struct {
+	UserID     string
+	Scopes     []string
+	AuthMethod string
+	AuthTime   int64
+	ClientIP   string
+	RequestID  string
+}
+
+// InternalSigner signs the assertions attached to every backend call.
+type InternalSigner struct {
+	key ed25519.PrivateKey
+}
+
+// InternalAuth is the signer used by the gRPC client interceptors, loaded in main.
+var InternalAuth *InternalSigner
+
+// LoadInternalSigner reads the Ed25519 private key from a PKCS#8 PEM file.
+func LoadInternalSigner(path string) (*InternalSigner, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%s does not contain a PEM block", path)
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	edKey, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("%s is not an Ed25519 private key", path)
+	}
+	return &InternalSigner{key: edKey}, nil
+}
+
+// Sign returns a short-lived assertion of the identity for the given service.
+func (s *InternalSigner) Sign(caller InternalIdentity, audience string) (string, error) {
+	now := time.Now()
+	claims := identity.AssertionClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    identity.Issuer,
+			Subject:   caller.UserID,
+			Audience:  jwt.ClaimStrings{audience},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(InternalAssertionTTL)),
+		},
+		Scopes:     caller.Scopes,
+		AuthMethod: caller.AuthMethod,
+		AuthTime:   caller.AuthTime,
+		ClientIP:   caller.ClientIP,
+		RequestID:  caller.RequestID,
+	}
+	return jwt.NewWithClaims(jwt.SigningMethodEdDSA, claims).SignedString(s.key)
+}
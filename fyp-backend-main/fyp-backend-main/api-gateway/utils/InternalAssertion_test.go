@@ -0,0 +1,87 @@
+package utils
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"shared/identity"
+)
+
+func TestInternalAssertionVerifiedByServices(t *testing.T) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	privateFile := writePEM(t, dir, "internal-auth.pem", "PRIVATE KEY", mustDER(x509.MarshalPKCS8PrivateKey(private)))
+	publicFile := writePEM(t, dir, "internal-auth.pub.pem", "PUBLIC KEY", mustDER(x509.MarshalPKIXPublicKey(public)))
+
+	signer, err := LoadInternalSigner(privateFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	verifier, err := identity.LoadVerifier("auth-service", []string{publicFile})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	authTime := time.Now().Add(-time.Hour).Unix()
+	assertion, err := signer.Sign(InternalIdentity{
+		UserID:     "user-1",
+		Scopes:     []string{ScopeDocsRead},
+		AuthMethod: "pwd",
+		AuthTime:   authTime,
+		ClientIP:   "203.0.113.7",
+		RequestID:  "request-1",
+	}, "auth-service")
+	if err != nil {
+		t.Fatal(err)
+	}
+	principal, err := verifier.Verify(assertion)
+	if err != nil {
+		t.Fatalf("Verify() = %v", err)
+	}
+	want := &identity.Principal{
+		UserID:     "user-1",
+		Scopes:     []string{ScopeDocsRead},
+		AuthMethod: "pwd",
+		AuthTime:   time.Unix(authTime, 0),
+		ClientIP:   "203.0.113.7",
+		RequestID:  "request-1",
+	}
+	if !reflect.DeepEqual(principal, want) {
+		t.Fatalf("Verify() = %+v, want %+v", principal, want)
+	}
+
+	// Signed for another service
+	assertion, err = signer.Sign(InternalIdentity{UserID: "user-1"}, "workflow-service")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := verifier.Verify(assertion); err == nil {
+		t.Fatal("Verify() of an assertion for another service = nil, want an error")
+	}
+}
+
+func writePEM(t *testing.T, dir, name, blockType string, der []byte) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func mustDER(der []byte, err error) []byte {
+	if err != nil {
+		panic(err)
+	}
+	return der
+}
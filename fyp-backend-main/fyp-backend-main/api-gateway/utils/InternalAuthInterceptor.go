@@ -0,0 +1,61 @@
+package utils
+
+import (
+	"context"
+
+	"shared/identity"
+	"shared/logging"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+type internalIdentityKey struct{}
+
+// WithInternalIdentity returns a copy of ctx whose backend calls are made on behalf of the identity.
+func WithInternalIdentity(ctx context.Context, caller InternalIdentity) context.Context {
+	return context.WithValue(ctx, internalIdentityKey{}, caller)
+}
+
+// signOutgoing attaches an assertion for the identity in ctx, calls without one are anonymous
+func signOutgoing(ctx context.Context, audience string) (context.Context, error) {
+	if InternalAuth == nil {
+		return nil, status.Error(codes.Internal, "internal auth signer is not loaded")
+	}
+	caller, _ := ctx.Value(internalIdentityKey{}).(InternalIdentity)
+	assertion, err := InternalAuth.Sign(caller, audience)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to sign internal assertion: %v", err)
+	}
+	// The request ID is sent apart too, so services can log it even for calls they
+	// reject before verifying the assertion
+	kv := []string{identity.AssertionMetadataKey, assertion}
+	if caller.RequestID != "" {
+		kv = append(kv, logging.RequestIDMetadataKey, caller.RequestID)
+	}
+	return metadata.AppendToOutgoingContext(ctx, kv...), nil
+}
+
+// InternalAuthUnaryInterceptor signs an assertion for every unary call to the named service.
+func InternalAuthUnaryInterceptor(audience string) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		ctx, err := signOutgoing(ctx, audience)
+		if err != nil {
+			return err
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+// InternalAuthStreamInterceptor signs an assertion for every stream opened to the named service.
+func InternalAuthStreamInterceptor(audience string) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		ctx, err := signOutgoing(ctx, audience)
+		if err != nil {
+			return nil, err
+		}
+		return streamer(ctx, desc, cc, method, opts...)
+	}
+}
@@ -0,0 +1,47 @@
+package utils
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// Redis keys written by the auth service when tokens are revoked
+const (
+	denylistKeyPrefix      = "auth:denylist:"
+	revokedBeforeKeyPrefix = "auth:revoked-before:"
+)
+
+// IsTokenRevoked checks the revocation entries the auth service keeps in Redis
+// with a single round trip. If Redis is unreachable the token is accepted,
+// access tokens are short-lived so the exposure is bounded.
+func IsTokenRevoked(ctx context.Context, claims *jwt.RegisteredClaims) bool {
+	if RDB == nil {
+		return false
+	}
+	ctx, cancel := context.WithTimeout(ctx, 500*time.Millisecond)
+	defer cancel()
+
+	values, err := RDB.MGet(ctx, denylistKeyPrefix+claims.ID, revokedBeforeKeyPrefix+claims.Subject).Result()
+	if err != nil {
+		slog.Error("Failed to check token revocation", "error", err)
+		return false
+	}
+
+	// The token itself was revoked by a logout
+	if values[0] != nil {
+		return true
+	}
+
+	// All of the user's tokens issued before this time were revoked
+	if value, ok := values[1].(string); ok {
+		revokedBefore, err := strconv.ParseInt(value, 10, 64)
+		if err == nil && claims.IssuedAt != nil && claims.IssuedAt.Unix() < revokedBefore {
+			return true
+		}
+	}
+	return false
+}
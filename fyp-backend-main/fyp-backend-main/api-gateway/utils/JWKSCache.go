@@ -0,0 +1,194 @@
+package utils
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"sync"
+	"time"
+
+	auth_service "api-gateway/proto/generated/github.com/multiagentai/backend/auth-service"
+)
+
+const (
+	// jwksRefreshInterval is how often the key set is refreshed in the background
+	jwksRefreshInterval = 10 * time.Minute
+	// jwksMinRefreshInterval rate limits refreshes triggered by unknown key IDs
+	jwksMinRefreshInterval = 30 * time.Second
+)
+
+// JWK is a public key as published in the JWKS document.
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use,omitempty"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+type cachedKey struct {
+	alg       string
+	publicKey crypto.PublicKey
+}
+
+// JWKSCache keeps the auth service's verification keys in memory.
+type JWKSCache struct {
+	client      auth_service.AuthServiceClient
+	mu          sync.RWMutex
+	keys        map[string]cachedKey
+	document    []JWK
+	lastRefresh time.Time
+}
+
+// JWKS is the key cache used by ValidateToken, initialized in main.
+var JWKS *JWKSCache
+
+// NewJWKSCache loads the key set from the auth service and keeps it refreshed in the background.
+func NewJWKSCache(client auth_service.AuthServiceClient) *JWKSCache {
+	cache := &JWKSCache{client: client, keys: map[string]cachedKey{}}
+	if err := cache.Refresh(context.Background()); err != nil {
+		// Tokens are rejected until the auth service is reachable
+		slog.Error("Failed to load JWKS", "error", err)
+	}
+
+	go func() {
+		for range time.Tick(jwksRefreshInterval) {
+			if err := cache.Refresh(context.Background()); err != nil {
+				slog.Error("Failed to refresh JWKS", "error", err)
+			}
+		}
+	}()
+	return cache
+}
+
+// Refresh fetches the current key set from the auth service.
+func (j *JWKSCache) Refresh(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	j.mu.Lock()
+	j.lastRefresh = time.Now()
+	j.mu.Unlock()
+
+	res, err := j.client.GetJWKS(ctx, &auth_service.GetJWKSRequest{})
+	if err != nil {
+		return err
+	}
+
+	// Parse every key, skipping the ones we can't use
+	keys := map[string]cachedKey{}
+	document := []JWK{}
+	for _, k := range res.GetKeys() {
+		jwk := JWK{
+			Kty: k.GetKty(),
+			Kid: k.GetKid(),
+			Use: k.GetUse(),
+			Alg: k.GetAlg(),
+			N:   k.GetN(),
+			E:   k.GetE(),
+			Crv: k.GetCrv(),
+			X:   k.GetX(),
+			Y:   k.GetY(),
+		}
+		publicKey, err := parseJWK(jwk)
+		if err != nil {
+			slog.Warn("Skipping JWK", "kid", jwk.Kid, "error", err)
+			continue
+		}
+		keys[jwk.Kid] = cachedKey{alg: jwk.Alg, publicKey: publicKey}
+		document = append(document, jwk)
+	}
+
+	j.mu.Lock()
+	j.keys = keys
+	j.document = document
+	j.mu.Unlock()
+	return nil
+}
+
+// Key returns the public key and algorithm for a key ID, refreshing the set
+// once if the key ID is unknown (e.g. right after a key rotation).
+func (j *JWKSCache) Key(kid string) (crypto.PublicKey, string, error) {
+	j.mu.RLock()
+	key, ok := j.keys[kid]
+	canRefresh := time.Since(j.lastRefresh) >= jwksMinRefreshInterval
+	j.mu.RUnlock()
+	if ok {
+		return key.publicKey, key.alg, nil
+	}
+
+	if canRefresh {
+		if err := j.Refresh(context.Background()); err != nil {
+			slog.Error("Failed to refresh JWKS", "error", err)
+		}
+		j.mu.RLock()
+		key, ok = j.keys[kid]
+		j.mu.RUnlock()
+		if ok {
+			return key.publicKey, key.alg, nil
+		}
+	}
+	return nil, "", fmt.Errorf("unknown signing key %q", kid)
+}
+
+// Document returns the cached keys for the public JWKS endpoint.
+func (j *JWKSCache) Document() []JWK {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+	return j.document
+}
+
+// parseJWK converts an RSA or P-256 EC JWK into a public key
+func parseJWK(jwk JWK) (crypto.PublicKey, error) {
+	switch jwk.Kty {
+	case "RSA":
+		n, err := decodeBigInt(jwk.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(jwk.E)
+		if err != nil {
+			return nil, err
+		}
+		if !e.IsInt64() || e.Int64() > 1<<31-1 {
+			return nil, errors.New("invalid RSA exponent")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		if jwk.Crv != "P-256" {
+			return nil, fmt.Errorf("unsupported curve %q", jwk.Crv)
+		}
+		x, err := decodeBigInt(jwk.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(jwk.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !elliptic.P256().IsOnCurve(x, y) {
+			return nil, errors.New("point is not on the curve")
+		}
+		return &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}, nil
+	}
+	return nil, fmt.Errorf("unsupported key type %q", jwk.Kty)
+}
+
+func decodeBigInt(value string) (*big.Int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil || len(data) == 0 {
+		return nil, errors.New("invalid base64url integer")
+	}
+	return new(big.Int).SetBytes(data), nil
+}
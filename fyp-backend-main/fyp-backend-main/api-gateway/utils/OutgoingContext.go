@@ -0,0 +1,21 @@
+package utils
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+)
+
+// OutgoingContext returns the context for backend calls made while handling the request,
+// carrying who the request was authenticated as. It is derived from the request context,
+// so calls share its deadline and are canceled when the client disconnects.
+func OutgoingContext(c *gin.Context) context.Context {
+	return WithInternalIdentity(c.Request.Context(), InternalIdentity{
+		UserID:     c.GetString("userID"),
+		Scopes:     c.GetStringSlice("scopes"),
+		AuthMethod: c.GetString("authMethod"),
+		AuthTime:   c.GetInt64("authTime"),
+		ClientIP:   c.ClientIP(),
+		RequestID:  GetRequestID(c),
+	})
+}
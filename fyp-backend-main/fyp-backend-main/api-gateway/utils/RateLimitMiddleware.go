@@ -0,0 +1,74 @@
+package utils
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"api-gateway/metrics"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Route groups rate limits are configured for, see RATE_LIMITS
+const (
+	RateLimitAuth        = "auth"
+	RateLimitUpload      = "upload"
+	RateLimitIntegration = "integration"
+	RateLimitWorkflow    = "workflow"
+	// RateLimitAPIKey counts the API keys looked up at the auth service per client IP,
+	// before the caller is authenticated, so keys can't be guessed at full speed
+	RateLimitAPIKey = "api-key"
+)
+
+// RateLimit counts the request against the limit of the route group and rejects it
+// with 429 once the limit is used up. Authenticated requests are counted per user or
+// API key, so it must come after AuthMiddleware, other requests per client IP.
+func RateLimit(group string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if allowRequest(c, group, rateLimitCaller(c)) {
+			c.Next()
+		}
+	}
+}
+
+// allowRequest counts the request of the caller against the limit of the group, and
+// responds with 429 when it's used up
+func allowRequest(c *gin.Context, group, caller string) bool {
+	if RateLimits == nil {
+		return true
+	}
+
+	result := RateLimits.Allow(c.Request.Context(), group, caller)
+	if result.Limit == 0 {
+		return true
+	}
+	c.Header("X-RateLimit-Limit", strconv.Itoa(result.Limit))
+	c.Header("X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
+	c.Header("X-RateLimit-Reset", strconv.Itoa(ceilSeconds(result.ResetAfter)))
+
+	if !result.Allowed {
+		metrics.RateLimitedRequests.WithLabelValues(group).Inc()
+		c.Header("Retry-After", strconv.Itoa(max(ceilSeconds(result.RetryAfter), 1)))
+		RespondWithError(c, http.StatusTooManyRequests, CodeResourceExhausted, "rate limit exceeded, retry later")
+		return false
+	}
+	return true
+}
+
+// rateLimitCaller identifies who the request is counted for
+func rateLimitCaller(c *gin.Context) string {
+	if keyID := c.GetString("apiKeyID"); keyID != "" {
+		return "key:" + keyID
+	}
+	if userID := c.GetString("userID"); userID != "" {
+		return "user:" + userID
+	}
+	return "ip:" + c.ClientIP()
+}
+
+// ceilSeconds rounds d up to whole seconds, as used by the rate limit headers
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,157 @@
+package utils
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"api-gateway/config"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Redis keys holding the rate limit state, one per group and caller
+const rateLimitKeyPrefix = "gateway:ratelimit:"
+
+// How long a Redis call may take before the request is counted in process instead
+const rateLimitRedisTimeout = 200 * time.Millisecond
+
+// RateLimits is the limiter used by RateLimit, nil when rate limiting is disabled.
+var RateLimits *RateLimiter
+
+// RateLimitResult is the outcome of counting a request.
+type RateLimitResult struct {
+	Allowed bool
+	Limit   int
+	// Remaining requests that would be allowed right now
+	Remaining int
+	// RetryAfter is how long until the request would be allowed, zero when it is
+	RetryAfter time.Duration
+	// ResetAfter is how long until the full limit is available again
+	ResetAfter time.Duration
+}
+
+// RateLimiter enforces the configured limits with the generic cell rate algorithm, a
+// token bucket stored as the time at which it is full again. The state is kept in
+// Redis so it is shared by every gateway instance. While Redis can't be reached
+// requests are counted in process, so each instance enforces the limits on its own.
+type RateLimiter struct {
+	limits   map[string]config.RateLimit
+	fallback *memoryRateLimiter
+	degraded atomic.Bool
+}
+
+// NewRateLimiter creates a limiter enforcing the limits, keyed by route group.
+func NewRateLimiter(limits map[string]config.RateLimit) *RateLimiter {
+	return &RateLimiter{
+		limits:   limits,
+		fallback: &memoryRateLimiter{tats: make(map[string]time.Time)},
+	}
+}
+
+// Allow counts a request of the caller against the limit of the group. Groups
+// without a limit allow every request and report a Limit of 0.
+func (l *RateLimiter) Allow(ctx context.Context, group, caller string) RateLimitResult {
+	limit, ok := l.limits[group]
+	if !ok {
+		return RateLimitResult{Allowed: true}
+	}
+	key := rateLimitKeyPrefix + group + ":" + caller
+
+	if RDB != nil {
+		ctx, cancel := context.WithTimeout(ctx, rateLimitRedisTimeout)
+		defer cancel()
+		result, err := allowRedis(ctx, key, limit)
+		if err == nil {
+			if l.degraded.CompareAndSwap(true, false) {
+				slog.Info("Rate limiting is back on Redis")
+			}
+			return result
+		}
+		if l.degraded.CompareAndSwap(false, true) {
+			slog.WarnContext(ctx, "Rate limiting in process, Redis is unavailable", "error", err)
+		}
+	}
+	return l.fallback.allow(key, limit, time.Now())
+}
+
+// gcraScript applies the algorithm atomically. It stores the theoretical arrival time
+// (TAT) of the next request, a request is allowed when it is at most one window
+// ahead of now. Redis' clock is used so every gateway instance agrees on it.
+var gcraScript = redis.NewScript(`
+local now = redis.call('TIME')
+local now_us = tonumber(now[1]) * 1000000 + tonumber(now[2])
+local interval = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+
+local tat = tonumber(redis.call('GET', KEYS[1]) or now_us)
+if tat < now_us then
+	tat = now_us
+end
+local new_tat = tat + interval
+if new_tat - window > now_us then
+	return {0, 0, new_tat - window - now_us, tat - now_us}
+end
+
+redis.call('SET', KEYS[1], new_tat, 'PX', math.ceil((new_tat - now_us) / 1000))
+return {1, math.floor((now_us + window - new_tat) / interval), 0, new_tat - now_us}
+`)
+
+// allowRedis counts the request in Redis, durations are passed in microseconds
+func allowRedis(ctx context.Context, key string, limit config.RateLimit) (RateLimitResult, error) {
+	interval := limit.Window / time.Duration(limit.Limit)
+	values, err := gcraScript.Run(ctx, RDB, []string{key}, interval.Microseconds(), limit.Window.Microseconds()).Int64Slice()
+	if err != nil {
+		return RateLimitResult{}, err
+	}
+	return RateLimitResult{
+		Allowed:    values[0] == 1,
+		Limit:      limit.Limit,
+		Remaining:  int(values[1]),
+		RetryAfter: time.Duration(values[2]) * time.Microsecond,
+		ResetAfter: time.Duration(values[3]) * time.Microsecond,
+	}, nil
+}
+
+// How many callers the in-process limiter tracks before forgetting idle ones
+const memoryRateLimiterSweepSize = 10000
+
+// memoryRateLimiter applies the same algorithm to state held in process
+type memoryRateLimiter struct {
+	mu   sync.Mutex
+	tats map[string]time.Time
+}
+
+func (m *memoryRateLimiter) allow(key string, limit config.RateLimit, now time.Time) RateLimitResult {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// Callers whose bucket is full again carry no state
+	if len(m.tats) >= memoryRateLimiterSweepSize {
+		for k, tat := range m.tats {
+			if !tat.After(now) {
+				delete(m.tats, k)
+			}
+		}
+	}
+
+	interval := limit.Window / time.Duration(limit.Limit)
+	tat, ok := m.tats[key]
+	if !ok || tat.Before(now) {
+		tat = now
+	}
+	newTAT := tat.Add(interval)
+	if allowAt := newTAT.Add(-limit.Window); allowAt.After(now) {
+		return RateLimitResult{Limit: limit.Limit, RetryAfter: allowAt.Sub(now), ResetAfter: tat.Sub(now)}
+	}
+
+	m.tats[key] = newTAT
+	return RateLimitResult{
+		Allowed:    true,
+		Limit:      limit.Limit,
+		Remaining:  int(now.Add(limit.Window).Sub(newTAT) / interval),
+		ResetAfter: newTAT.Sub(now),
+	}
+}
@@ -0,0 +1,21 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"api-gateway/storage"
+)
+
+// RecordUpload registers a stored file with its size, ETag and checksum set, and returns
+// the URL the gateway serves it from.
+func RecordUpload(ctx context.Context, registry *storage.Registry, upload storage.Upload) (string, error) {
+	upload.Status = storage.UploadComplete
+	upload.ExpiresAt = nil
+	upload.CreatedAt = time.Now().UTC()
+	if err := registry.Record(ctx, upload); err != nil {
+		return "", fmt.Errorf("error recording upload: %w", err)
+	}
+	return FileURL(upload.Namespace, upload.Key), nil
+}
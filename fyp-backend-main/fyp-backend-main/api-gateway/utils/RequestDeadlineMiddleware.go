@@ -0,0 +1,26 @@
+package utils
+
+import (
+	"context"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestDeadlineMiddleware puts a deadline on the request context. Backend calls made
+// with OutgoingContext inherit it and are canceled when the client goes away.
+// Routes are looked up as "METHOD /path" using the route pattern, e.g. "POST /docs/:bucket",
+// and fall back to defaultTimeout.
+func RequestDeadlineMiddleware(defaultTimeout time.Duration, routeTimeouts map[string]time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		timeout, ok := routeTimeouts[c.Request.Method+" "+c.FullPath()]
+		if !ok {
+			timeout = defaultTimeout
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
@@ -0,0 +1,42 @@
+package utils
+
+import (
+	"log/slog"
+	"regexp"
+
+	"shared/logging"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// RequestIDHeader is the header used to carry the request ID.
+const RequestIDHeader = "X-Request-ID"
+
+// Only accept client supplied IDs that are safe to log and echo back
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._-]{1,128}$`)
+
+// RequestIDMiddleware accepts a client supplied X-Request-ID or generates one,
+// stores it in the context, where it's picked up by log lines, and echoes it in the response.
+func RequestIDMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(RequestIDHeader)
+		if !validRequestID.MatchString(requestID) {
+			requestID = uuid.New().String()
+		}
+
+		c.Set("requestID", requestID)
+		c.Request = c.Request.WithContext(logging.With(c.Request.Context(), slog.String("request_id", requestID)))
+		// Let traces be found by the ID clients see in error responses
+		trace.SpanFromContext(c.Request.Context()).SetAttributes(attribute.String("request.id", requestID))
+		c.Header(RequestIDHeader, requestID)
+		c.Next()
+	}
+}
+
+// GetRequestID returns the request ID assigned by RequestIDMiddleware.
+func GetRequestID(c *gin.Context) string {
+	return c.GetString("requestID")
+}
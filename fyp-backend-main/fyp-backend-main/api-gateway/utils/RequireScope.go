@@ -0,0 +1,50 @@
+package utils
+
+import (
+	"net/http"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Scopes API keys can be granted, matching the auth service
+const (
+	ScopeUserRead         = "user:read"
+	ScopeProjectRead      = "project:read"
+	ScopeProjectWrite     = "project:write"
+	ScopeWorkflowRead     = "workflow:read"
+	ScopeWorkflowWrite    = "workflow:write"
+	ScopeIntegrationRead  = "integration:read"
+	ScopeIntegrationWrite = "integration:write"
+	ScopeDocsRead         = "docs:read"
+	ScopeDocsWrite        = "docs:write"
+)
+
+// How the request was authenticated, stored under "authMethod"
+const (
+	AuthMethodSession = "session"
+	AuthMethodAPIKey  = "api_key"
+)
+
+// RequireScope rejects API key requests whose key wasn't granted the scope.
+// Requests authenticated with an access token have every scope.
+func RequireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetString("authMethod") == AuthMethodAPIKey && !slices.Contains(c.GetStringSlice("scopes"), scope) {
+			RespondWithError(c, http.StatusForbidden, CodePermissionDenied, "API key is missing the "+scope+" scope")
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequireSession rejects API keys on routes that manage the account itself.
+func RequireSession() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetString("authMethod") != AuthMethodSession {
+			RespondWithError(c, http.StatusForbidden, CodePermissionDenied, "this route can't be used with an API key")
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,53 @@
+package utils
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"log/slog"
+
+	"api-gateway/storage"
+)
+
+// UploadToStorage stores the file described by upload, records it in the registry and
+// returns the URL the gateway serves it from
+func UploadToStorage(ctx context.Context, file io.ReadSeeker, upload storage.Upload, store storage.BlobStore, registry *storage.Registry) (string, error) {
+	// Ensure the store is initialized
+	if store == nil || registry == nil {
+		return "", fmt.Errorf("storage not initialized")
+	}
+	if upload.ContentType == "" {
+		upload.ContentType = "application/octet-stream"
+	}
+
+	// Hash the file as not every store reports a checksum, then rewind it as S3 signs
+	// seekable bodies
+	checksum := sha256.New()
+	if _, err := io.Copy(checksum, file); err != nil {
+		return "", fmt.Errorf("error reading file: %w", err)
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return "", fmt.Errorf("error reading file: %w", err)
+	}
+
+	// Upload the file
+	info, err := store.Put(ctx, upload.Bucket, upload.Key, file, upload.Size, upload.ContentType)
+	if err != nil {
+		return "", fmt.Errorf("error uploading file: %w", err)
+	}
+
+	// Record who uploaded it, a file without a record could only be read through attachments
+	upload.Size = info.Size
+	upload.ETag = info.ETag
+	upload.ChecksumSHA256 = base64.StdEncoding.EncodeToString(checksum.Sum(nil))
+	fileURL, err := RecordUpload(ctx, registry, upload)
+	if err != nil {
+		if deleteErr := store.Delete(context.WithoutCancel(ctx), upload.Bucket, upload.Key); deleteErr != nil {
+			slog.WarnContext(ctx, "Failed to delete unrecorded upload", "bucket", upload.Bucket, "key", upload.Key, "error", deleteErr)
+		}
+		return "", err
+	}
+	return fileURL, nil
+}
@@ -3,32 +3,63 @@ package utils
 import (
 	"errors"
 
-	"github.com/golang-jwt/jwt"
+	"api-gateway/config"
+
+	"github.com/golang-jwt/jwt/v4"
 )
 
-func ValidateToken(tokenString string) (string, error) {
-	secretKey := []byte("abcd") // Replace with a secure key or fetch from env variables
+// AccessClaims are the claims of an access token issued by the auth service.
+type AccessClaims struct {
+	jwt.RegisteredClaims
+	AuthTime int64 `json:"auth_time,omitempty"` // When the user last entered their credentials
+}
+
+// ValidateToken verifies an access token against the cached JWKS and returns its claims.
+func ValidateToken(tokenString string) (*AccessClaims, error) {
+	if JWKS == nil {
+		return nil, errors.New("token verification keys are not loaded")
+	}
 
 	// Parse and validate the token
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		// Ensure the token is signed with HMAC-SHA256
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+	claims := &AccessClaims{}
+	parser := jwt.NewParser(jwt.WithValidMethods([]string{"RS256", "ES256"}))
+	token, err := parser.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		// Look up the signing key by its ID
+		kid, _ := token.Header["kid"].(string)
+		if kid == "" {
+			return nil, errors.New("token has no key ID")
+		}
+		publicKey, alg, err := JWKS.Key(kid)
+		if err != nil {
+			return nil, err
+		}
+		// The key must be used with the algorithm it was published for
+		if alg != token.Method.Alg() {
 			return nil, errors.New("unexpected signing method")
 		}
-		return secretKey, nil
+		return publicKey, nil
 	})
-
 	if err != nil {
-		return "", err
+		return nil, err
+	}
+	if !token.Valid {
+		return nil, errors.New("invalid token")
 	}
 
-	// Extract claims and user ID
-	if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
-		if userID, ok := claims["sub"].(string); ok {
-			return userID, nil
-		}
-		return "", errors.New("invalid claims in token")
+	// exp and nbf are checked by the parser, iss and aud are required here
+	if claims.ExpiresAt == nil || claims.IssuedAt == nil {
+		return nil, errors.New("token has no expiry or issue time")
+	}
+	if !claims.VerifyIssuer(config.Current.JWTIssuer, true) {
+		return nil, errors.New("invalid token issuer")
+	}
+	if !claims.VerifyAudience(config.Current.JWTAudience, true) {
+		return nil, errors.New("invalid token audience")
 	}
 
-	return "", errors.New("invalid token")
+	// Extract user ID and token ID
+	if claims.Subject == "" || claims.ID == "" {
+		return nil, errors.New("invalid claims in token")
+	}
+	return claims, nil
 }
@@ -0,0 +1,70 @@
+package controllers
+
+import (
+	"context"
+	"time"
+
+	"auth-service/config"
+	"auth-service/helpers"
+	"auth-service/models"
+	auth_service "auth-service/proto/generated/github.com/multiagentai/backend/auth-service"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"golang.org/x/crypto/bcrypt"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ConfirmPasswordReset sets a new password using a reset token and signs the user out everywhere.
+func (s *AuthServer) ConfirmPasswordReset(ctx context.Context, req *auth_service.ConfirmPasswordResetRequest) (*auth_service.ConfirmPasswordResetResponse, error) {
+	// Validate required fields
+	violations := map[string]string{}
+	if req.Token == "" {
+		violations["token"] = "token is required"
+	}
+	if req.NewPassword == "" {
+		violations["newPassword"] = "new password is required"
+	}
+	if len(violations) > 0 {
+		return nil, helpers.InvalidArgumentError(violations)
+	}
+
+	// Hash password before using up the token
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to hash password")
+	}
+
+	token, err := helpers.ConsumeUserToken(ctx, s.DocDB, req.Token, models.TokenPurposeResetPassword)
+	if err == helpers.ErrInvalidUserToken {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	} else if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to check reset token: %v", err)
+	}
+
+	// Receiving the email also proves the address belongs to the user
+	result, err := s.DocDB.Database(config.Current.MongoDatabase).Collection("users").UpdateOne(ctx,
+		bson.M{"_id": token.UserID, "deleted_at": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"password": string(hashedPassword), "updated_at": time.Now()}},
+	)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to update password: %v", err)
+	}
+	if result.MatchedCount == 0 {
+		return nil, status.Error(codes.NotFound, "user not found")
+	}
+	_, err = s.DocDB.Database(config.Current.MongoDatabase).Collection("users").UpdateOne(ctx,
+		bson.M{"_id": token.UserID, "email": token.Email},
+		bson.M{"$set": bson.M{"email_verified": true}},
+	)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to update user: %v", err)
+	}
+
+	// Whoever knew the old password is signed out
+	if _, err := helpers.RevokeUserSessions(ctx, s.DocDB, token.UserID); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to revoke sessions: %v", err)
+	}
+
+	return &auth_service.ConfirmPasswordResetResponse{}, nil
+}
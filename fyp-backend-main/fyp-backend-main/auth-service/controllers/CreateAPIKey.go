@@ -0,0 +1,89 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"auth-service/config"
+	"auth-service/helpers"
+	"auth-service/models"
+	auth_service "auth-service/proto/generated/github.com/multiagentai/backend/auth-service"
+	"shared/identity"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// CreateAPIKey creates a personal access token. The key is only returned in this response.
+func (s *AuthServer) CreateAPIKey(ctx context.Context, req *auth_service.CreateAPIKeyRequest) (*auth_service.CreateAPIKeyResponse, error) {
+	// Extract the userID of the verified caller
+	userID, err := identity.UserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// Convert userID string to ObjectID
+	objectID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid user ID format")
+	}
+
+	// Validate the request
+	violations := map[string]string{}
+	name := strings.TrimSpace(req.Name)
+	if name == "" || len(name) > 100 {
+		violations["name"] = "name is required and must be at most 100 characters"
+	}
+	scopes := []string{}
+	seen := map[string]bool{}
+	for _, scope := range req.Scopes {
+		if !helpers.APIKeyScopes[scope] {
+			violations["scopes"] = fmt.Sprintf("unknown scope %q", scope)
+			break
+		}
+		if !seen[scope] {
+			seen[scope] = true
+			scopes = append(scopes, scope)
+		}
+	}
+	if len(req.Scopes) == 0 {
+		violations["scopes"] = "at least one scope is required"
+	}
+	days := int(req.ExpiresInDays)
+	if days == 0 {
+		days = helpers.DefaultAPIKeyDays
+	}
+	if days < 0 || days > helpers.MaxAPIKeyDays {
+		violations["expiresInDays"] = fmt.Sprintf("must be between 1 and %d", helpers.MaxAPIKeyDays)
+	}
+	if len(violations) > 0 {
+		return nil, helpers.InvalidArgumentError(violations)
+	}
+
+	key, err := helpers.GenerateAPIKey()
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to generate API key")
+	}
+
+	now := time.Now()
+	apiKey := models.APIKey{
+		ID:        primitive.NewObjectID(),
+		UserID:    objectID,
+		Name:      name,
+		Prefix:    key[:len(helpers.APIKeyPrefix)+8],
+		KeyHash:   helpers.HashToken(key),
+		Scopes:    scopes,
+		CreatedAt: now,
+		ExpiresAt: now.AddDate(0, 0, days),
+	}
+	if _, err := s.DocDB.Database(config.Current.MongoDatabase).Collection("api_keys").InsertOne(ctx, apiKey); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to create API key: %v", err)
+	}
+
+	return &auth_service.CreateAPIKeyResponse{
+		Key:    key,
+		ApiKey: helpers.APIKeyToProto(&apiKey),
+	}, nil
+}
@@ -2,45 +2,60 @@ package controllers
 
 import (
 	"context"
-	"errors"
 	"time"
 
+	"auth-service/config"
 	"auth-service/helpers"
 	"auth-service/models"
 	auth_service "auth-service/proto/generated/github.com/multiagentai/backend/auth-service"
+	"shared/identity"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 func (s *AuthServer) DeleteUser(ctx context.Context, req *auth_service.DeleteUserRequest) (*auth_service.DeleteUserResponse, error) {
-	// Extract userID using the helper function
-	userID, err := helpers.ExtractUserIDFromContext(ctx)
+	// Extract the userID of the verified caller
+	userID, err := identity.UserID(ctx)
 	if err != nil {
 		return nil, err
 	}
 	// Convert userID string to ObjectID
 	objectID, err := primitive.ObjectIDFromHex(userID)
 	if err != nil {
-		return nil, errors.New("invalid user ID format")
+		return nil, status.Error(codes.InvalidArgument, "invalid user ID format")
 	}
 
 	// delete user by updating DeletedAt
 	filter := bson.M{"_id": objectID}
 	update := bson.M{"$set": bson.M{"deleted_at": time.Now()}}
-	result := s.DocDB.Database("fyp-db").Collection("users").FindOneAndUpdate(ctx, filter, update)
+	result := s.DocDB.Database(config.Current.MongoDatabase).Collection("users").FindOneAndUpdate(ctx, filter, update)
 	if result.Err() == mongo.ErrNoDocuments {
-		return nil, errors.New("user not found")
+		return nil, status.Error(codes.NotFound, "user not found")
 	} else if result.Err() != nil {
-		return nil, result.Err()
+		return nil, status.Errorf(codes.Internal, "failed to delete user: %v", result.Err())
+	}
+
+	// Log the deleted user out everywhere, API keys included
+	if _, err := helpers.RevokeUserSessions(ctx, s.DocDB, objectID); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to revoke sessions: %v", err)
+	}
+	_, err = s.DocDB.Database(config.Current.MongoDatabase).Collection("api_keys").UpdateMany(ctx,
+		bson.M{"user_id": objectID, "revoked_at": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"revoked_at": time.Now()}},
+	)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to revoke API keys: %v", err)
 	}
 
 	var deletedUser models.User
 	err = result.Decode(&deletedUser)
 	if err != nil {
-		return nil, errors.New("failed to decode deleted user")
+		return nil, status.Error(codes.Internal, "failed to decode deleted user")
 	}
 	createdAt := timestamppb.New(deletedUser.CreatedAt)
 	updatedAt := timestamppb.New(deletedUser.UpdatedAt)
@@ -49,14 +64,16 @@ func (s *AuthServer) DeleteUser(ctx context.Context, req *auth_service.DeleteUse
 	//return response
 	return &auth_service.DeleteUserResponse{
 		User: &auth_service.User{
-			Id:        deletedUser.ID.Hex(),
-			Firstname: deletedUser.FirstName,
-			Lastname:  deletedUser.LastName,
-			Email:     deletedUser.Email,
-			Credits:   deletedUser.Credits,
-			CreatedAt: createdAt,
-			UpdatedAt: updatedAt,
-			DeletedAt: deletedAt,
+			Id:            deletedUser.ID.Hex(),
+			Firstname:     deletedUser.FirstName,
+			Lastname:      deletedUser.LastName,
+			Email:         deletedUser.Email,
+			EmailVerified: deletedUser.EmailVerified,
+			MfaEnabled:    deletedUser.MFAEnabled(),
+			Credits:       deletedUser.Credits,
+			CreatedAt:     createdAt,
+			UpdatedAt:     updatedAt,
+			DeletedAt:     deletedAt,
 		},
 	}, nil
 }
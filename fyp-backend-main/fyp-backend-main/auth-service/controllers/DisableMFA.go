@@ -0,0 +1,61 @@
+package controllers
+
+import (
+	"context"
+
+	"auth-service/config"
+	"auth-service/helpers"
+	"auth-service/models"
+	auth_service "auth-service/proto/generated/github.com/multiagentai/backend/auth-service"
+	"shared/identity"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// DisableMFA turns MFA off. It needs a current TOTP code or a recovery code.
+func (s *AuthServer) DisableMFA(ctx context.Context, req *auth_service.DisableMFARequest) (*auth_service.DisableMFAResponse, error) {
+	// Extract the userID of the verified caller
+	userID, err := identity.UserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// Convert userID string to ObjectID
+	objectID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid user ID format")
+	}
+	if req.Code == "" {
+		return nil, helpers.InvalidArgumentError(map[string]string{"code": "code is required"})
+	}
+
+	// Find user by ID
+	users := s.DocDB.Database(config.Current.MongoDatabase).Collection("users")
+	var user models.User
+	err = users.FindOne(ctx, bson.M{"_id": objectID}).Decode(&user)
+	if err == mongo.ErrNoDocuments || (err == nil && user.DeletedAt != nil) {
+		return nil, status.Error(codes.NotFound, "user not found")
+	} else if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get user: %v", err)
+	}
+	if !user.MFAEnabled() {
+		return nil, status.Error(codes.FailedPrecondition, "MFA is not enabled")
+	}
+
+	ok, err := helpers.VerifySecondFactor(ctx, s.DocDB, &user, req.Code)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to verify code: %v", err)
+	}
+	if !ok {
+		return nil, status.Error(codes.InvalidArgument, "invalid code")
+	}
+
+	if _, err := users.UpdateOne(ctx, bson.M{"_id": objectID}, bson.M{"$unset": bson.M{"mfa": ""}}); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to update user: %v", err)
+	}
+
+	return &auth_service.DisableMFAResponse{}, nil
+}
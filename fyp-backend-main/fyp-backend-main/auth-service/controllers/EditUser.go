@@ -2,31 +2,67 @@ package controllers
 
 import (
 	"context"
-	"errors"
+	"log/slog"
 	"time"
 
+	"auth-service/config"
 	"auth-service/helpers"
 	"auth-service/models"
 	auth_service "auth-service/proto/generated/github.com/multiagentai/backend/auth-service"
+	"shared/identity"
 
 	"golang.org/x/crypto/bcrypt"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 func (s *AuthServer) EditUser(ctx context.Context, req *auth_service.EditUserRequest) (*auth_service.EditUserResponse, error) {
-	// Extract userID using the helper function
-	userID, err := helpers.ExtractUserIDFromContext(ctx)
+	// Extract the userID of the verified caller
+	userID, err := identity.UserID(ctx)
 	if err != nil {
 		return nil, err
 	}
 	// Convert userID string to ObjectID
 	objectID, err := primitive.ObjectIDFromHex(userID)
 	if err != nil {
-		return nil, errors.New("invalid user ID format")
+		return nil, status.Error(codes.InvalidArgument, "invalid user ID format")
+	}
+
+	users := s.DocDB.Database(config.Current.MongoDatabase).Collection("users")
+	req.Email = helpers.NormalizeEmail(req.Email)
+
+	// Changing the email or password requires the current password or a fresh sign-in
+	if req.Email != "" || req.Password != "" {
+		var user models.User
+		err := users.FindOne(ctx, bson.M{"_id": objectID}).Decode(&user)
+		if err == mongo.ErrNoDocuments {
+			return nil, status.Error(codes.NotFound, "user not found")
+		} else if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to get user: %v", err)
+		}
+
+		if err := helpers.CheckReauthentication(ctx, &user, req.CurrentPassword); err != nil {
+			return nil, err
+		}
+
+		// The new email must not belong to another account
+		if req.Email != "" && req.Email != user.Email {
+			count, err := users.CountDocuments(ctx, bson.M{"email": req.Email, "_id": bson.M{"$ne": objectID}})
+			if err != nil {
+				return nil, status.Errorf(codes.Internal, "failed to check existing user: %v", err)
+			}
+			if count > 0 {
+				return nil, status.Error(codes.AlreadyExists, "user already exists")
+			}
+		} else {
+			req.Email = ""
+		}
 	}
 
 	// Initialize fields to be updated
@@ -43,13 +79,14 @@ func (s *AuthServer) EditUser(ctx context.Context, req *auth_service.EditUserReq
 	}
 	if req.Email != "" {
 		updateFields["email"] = req.Email
+		updateFields["email_verified"] = false
 	}
 
 	// Hash password if given
 	if req.Password != "" {
 		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
 		if err != nil {
-			return nil, errors.New("failed to hash password")
+			return nil, status.Error(codes.Internal, "failed to hash password")
 		}
 		updateFields["password"] = string(hashedPassword)
 	}
@@ -57,18 +94,31 @@ func (s *AuthServer) EditUser(ctx context.Context, req *auth_service.EditUserReq
 	// Update user in db
 	filter := bson.M{"_id": objectID}
 	update := bson.M{"$set": updateFields}
-	result := s.DocDB.Database("fyp-db").Collection("users").FindOneAndUpdate(ctx, filter, update)
+	result := users.FindOneAndUpdate(ctx, filter, update, options.FindOneAndUpdate().SetReturnDocument(options.After))
 	if result.Err() == mongo.ErrNoDocuments {
-		return nil, errors.New("user not found")
+		return nil, status.Error(codes.NotFound, "user not found")
+	} else if mongo.IsDuplicateKeyError(result.Err()) {
+		return nil, status.Error(codes.AlreadyExists, "user already exists")
 	} else if result.Err() != nil {
-		return nil, result.Err()
+		return nil, status.Errorf(codes.Internal, "failed to update user: %v", result.Err())
 	}
 
 	var updatedUser models.User
 	err = result.Decode(&updatedUser)
 	if err != nil {
-		return nil, errors.New("failed to decode updated user")
+		return nil, status.Error(codes.Internal, "failed to decode updated user")
 	}
+	// The new email has to be verified again
+	if req.Email != "" {
+		verifyToken, err := helpers.CreateUserToken(ctx, s.DocDB, updatedUser.ID, models.TokenPurposeVerifyEmail, updatedUser.Email, helpers.VerifyEmailTokenTTL)
+		if err == nil {
+			err = helpers.SendVerificationEmail(ctx, s.Mailer, updatedUser.Email, verifyToken)
+		}
+		if err != nil {
+			slog.ErrorContext(ctx, "Failed to send verification email", "user_id", updatedUser.ID.Hex(), "error", err)
+		}
+	}
+
 	createdAt := timestamppb.New(updatedUser.CreatedAt)
 	updatedAt := timestamppb.New(updatedUser.UpdatedAt)
 	deletedAt := timestamppb.New(time.Time{}) // Set to a zero value for now
@@ -76,15 +126,17 @@ func (s *AuthServer) EditUser(ctx context.Context, req *auth_service.EditUserReq
 	// Return response
 	return &auth_service.EditUserResponse{
 		User: &auth_service.User{
-			Id:           updatedUser.ID.Hex(),
-			Firstname:    updatedUser.FirstName,
-			Lastname:     updatedUser.LastName,
-			Email:        updatedUser.Email,
-			Credits:      updatedUser.Credits,
-			SigninMethod: updatedUser.SignInMethod,
-			CreatedAt:    createdAt,
-			UpdatedAt:    updatedAt,
-			DeletedAt:    deletedAt,
+			Id:            updatedUser.ID.Hex(),
+			Firstname:     updatedUser.FirstName,
+			Lastname:      updatedUser.LastName,
+			Email:         updatedUser.Email,
+			EmailVerified: updatedUser.EmailVerified,
+			MfaEnabled:    updatedUser.MFAEnabled(),
+			Credits:       updatedUser.Credits,
+			SigninMethod:  updatedUser.SignInMethod,
+			CreatedAt:     createdAt,
+			UpdatedAt:     updatedAt,
+			DeletedAt:     deletedAt,
 		},
 	}, nil
 }
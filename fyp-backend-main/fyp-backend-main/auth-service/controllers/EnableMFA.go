@@ -0,0 +1,64 @@
+package controllers
+
+import (
+	"context"
+
+	"auth-service/config"
+	"auth-service/helpers"
+	"auth-service/models"
+	auth_service "auth-service/proto/generated/github.com/multiagentai/backend/auth-service"
+	"shared/identity"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// EnableMFA starts TOTP enrollment. The secret only takes effect once VerifyMFA receives a valid code for it.
+func (s *AuthServer) EnableMFA(ctx context.Context, req *auth_service.EnableMFARequest) (*auth_service.EnableMFAResponse, error) {
+	// Extract the userID of the verified caller
+	userID, err := identity.UserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// Convert userID string to ObjectID
+	objectID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid user ID format")
+	}
+
+	// Find user by ID
+	users := s.DocDB.Database(config.Current.MongoDatabase).Collection("users")
+	var user models.User
+	err = users.FindOne(ctx, bson.M{"_id": objectID}).Decode(&user)
+	if err == mongo.ErrNoDocuments || (err == nil && user.DeletedAt != nil) {
+		return nil, status.Error(codes.NotFound, "user not found")
+	} else if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get user: %v", err)
+	}
+	if user.MFAEnabled() {
+		return nil, status.Error(codes.FailedPrecondition, "MFA is already enabled")
+	}
+	if err := helpers.CheckReauthentication(ctx, &user, req.CurrentPassword); err != nil {
+		return nil, err
+	}
+
+	// Store the secret as pending until the user proves their app has it
+	secret, err := helpers.GenerateTOTPSecret()
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to generate MFA secret")
+	}
+	_, err = users.UpdateOne(ctx, bson.M{"_id": objectID}, bson.M{"$set": bson.M{
+		"mfa": models.MFA{PendingSecret: secret},
+	}})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to update user: %v", err)
+	}
+
+	return &auth_service.EnableMFAResponse{
+		Secret:          secret,
+		ProvisioningUri: helpers.TOTPProvisioningURI(secret, user.Email),
+	}, nil
+}
@@ -0,0 +1,36 @@
+package controllers
+
+import (
+	"context"
+
+	"auth-service/helpers"
+	auth_service "auth-service/proto/generated/github.com/multiagentai/backend/auth-service"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// GetJWKS returns the public keys access tokens can be verified with, including retired keys.
+func (s *AuthServer) GetJWKS(ctx context.Context, req *auth_service.GetJWKSRequest) (*auth_service.GetJWKSResponse, error) {
+	keys, err := helpers.Keys.JWKS()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to build JWKS: %v", err)
+	}
+
+	// Convert to proto messages
+	res := &auth_service.GetJWKSResponse{}
+	for _, key := range keys {
+		res.Keys = append(res.Keys, &auth_service.JWK{
+			Kty: key.Kty,
+			Kid: key.Kid,
+			Use: key.Use,
+			Alg: key.Alg,
+			N:   key.N,
+			E:   key.E,
+			Crv: key.Crv,
+			X:   key.X,
+			Y:   key.Y,
+		})
+	}
+	return res, nil
+}
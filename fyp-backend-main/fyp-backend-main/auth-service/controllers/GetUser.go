@@ -2,22 +2,24 @@ package controllers
 
 import (
 	"context"
-	"errors"
 	"time"
 
-	"auth-service/helpers"
+	"auth-service/config"
 	"auth-service/models"
 	auth_service "auth-service/proto/generated/github.com/multiagentai/backend/auth-service"
+	"shared/identity"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 func (s *AuthServer) GetUser(ctx context.Context, req *auth_service.GetUserRequest) (*auth_service.GetUserResponse, error) {
-	// Extract userID using the helper function
-	userID, err := helpers.ExtractUserIDFromContext(ctx)
+	// Extract the userID of the verified caller
+	userID, err := identity.UserID(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -25,19 +27,19 @@ func (s *AuthServer) GetUser(ctx context.Context, req *auth_service.GetUserReque
 	// Convert userID string to ObjectID
 	objectID, err := primitive.ObjectIDFromHex(userID)
 	if err != nil {
-		return nil, errors.New("invalid user ID format")
+		return nil, status.Error(codes.InvalidArgument, "invalid user ID format")
 	}
 
 	// Find user by ID
 	var user models.User
-	err = s.DocDB.Database("fyp-db").Collection("users").FindOne(ctx, bson.M{"_id": objectID}).Decode(&user)
+	err = s.DocDB.Database(config.Current.MongoDatabase).Collection("users").FindOne(ctx, bson.M{"_id": objectID}).Decode(&user)
 	if err == mongo.ErrNoDocuments {
-		return nil, errors.New("user not found")
+		return nil, status.Error(codes.NotFound, "user not found")
 	} else if err != nil {
-		return nil, err
+		return nil, status.Errorf(codes.Internal, "failed to get user: %v", err)
 	}
 	if user.DeletedAt != nil {
-		return nil, errors.New("user not found")
+		return nil, status.Error(codes.NotFound, "user not found")
 	}
 	// Convert timestamps
 	createdAt := timestamppb.New(user.CreatedAt)
@@ -47,14 +49,16 @@ func (s *AuthServer) GetUser(ctx context.Context, req *auth_service.GetUserReque
 	// Return user details
 	return &auth_service.GetUserResponse{
 		User: &auth_service.User{
-			Id:        user.ID.Hex(),
-			Firstname: user.FirstName,
-			Lastname:  user.LastName,
-			Email:     user.Email,
-			Credits:   user.Credits,
-			CreatedAt: createdAt,
-			UpdatedAt: updatedAt,
-			DeletedAt: deletedAt,
+			Id:            user.ID.Hex(),
+			Firstname:     user.FirstName,
+			Lastname:      user.LastName,
+			Email:         user.Email,
+			EmailVerified: user.EmailVerified,
+			MfaEnabled:    user.MFAEnabled(),
+			Credits:       user.Credits,
+			CreatedAt:     createdAt,
+			UpdatedAt:     updatedAt,
+			DeletedAt:     deletedAt,
 		},
 	}, nil
 }
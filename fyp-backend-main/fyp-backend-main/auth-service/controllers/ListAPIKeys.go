@@ -0,0 +1,52 @@
+package controllers
+
+import (
+	"context"
+	"time"
+
+	"auth-service/config"
+	"auth-service/helpers"
+	"auth-service/models"
+	auth_service "auth-service/proto/generated/github.com/multiagentai/backend/auth-service"
+	"shared/identity"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ListAPIKeys returns the user's active API keys, newest first.
+func (s *AuthServer) ListAPIKeys(ctx context.Context, req *auth_service.ListAPIKeysRequest) (*auth_service.ListAPIKeysResponse, error) {
+	// Extract the userID of the verified caller
+	userID, err := identity.UserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// Convert userID string to ObjectID
+	objectID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid user ID format")
+	}
+
+	filter := bson.M{
+		"user_id":    objectID,
+		"revoked_at": bson.M{"$exists": false},
+		"expires_at": bson.M{"$gt": time.Now()},
+	}
+	cursor, err := s.DocDB.Database(config.Current.MongoDatabase).Collection("api_keys").Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}}))
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list API keys: %v", err)
+	}
+	var keys []models.APIKey
+	if err := cursor.All(ctx, &keys); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to decode API keys: %v", err)
+	}
+
+	res := &auth_service.ListAPIKeysResponse{}
+	for i := range keys {
+		res.ApiKeys = append(res.ApiKeys, helpers.APIKeyToProto(&keys[i]))
+	}
+	return res, nil
+}
@@ -2,9 +2,11 @@ package controllers
 
 import (
 	"context"
-	"errors"
+	"time"
 
+	"auth-service/config"
 	"auth-service/helpers"
+	"auth-service/metrics"
 	"auth-service/models"
 	auth_service "auth-service/proto/generated/github.com/multiagentai/backend/auth-service"
 
@@ -16,27 +18,60 @@ import (
 )
 
 func (s *AuthServer) Login(ctx context.Context, req *auth_service.LoginRequest) (*auth_service.LoginResponse, error) {
+	// Refuse attempts while the account or client IP is throttled
+	req.Email = helpers.NormalizeEmail(req.Email)
+	ip := helpers.ExtractClientIP(ctx)
+	if err := helpers.CheckLoginAllowed(ctx, req.Email, ip); err != nil {
+		helpers.AuditLoginFailure(ctx, s.DocDB, req.Email, nil, ip, models.LoginFailureThrottled)
+		return nil, err
+	}
+
 	// Find user by email
 	var user models.User
-	err := s.DocDB.Database("fyp-db").Collection("users").FindOne(ctx, bson.M{"email": req.Email}).Decode(&user)
+	err := s.DocDB.Database(config.Current.MongoDatabase).Collection("users").FindOne(ctx, bson.M{"email": req.Email}).Decode(&user)
 	if err == mongo.ErrNoDocuments {
-		return nil, errors.New("user not found")
+		helpers.CompareDummyPassword(req.Password)
+		return nil, helpers.FailLogin(ctx, s.DocDB, req.Email, nil, ip, models.LoginFailureUnknownUser)
 	} else if err != nil {
-		return nil, err
+		return nil, status.Errorf(codes.Internal, "failed to get user: %v", err)
 	}
 
-	// Check if the password is correct
+	// Check if the password is correct, accounts without a password only use social login
+	if user.Password == "" {
+		helpers.CompareDummyPassword(req.Password)
+		return nil, helpers.FailLogin(ctx, s.DocDB, req.Email, &user.ID, ip, models.LoginFailureInvalidPassword)
+	}
 	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
-		return nil, status.Errorf(codes.Unauthenticated, "invalid password")
+		return nil, helpers.FailLogin(ctx, s.DocDB, req.Email, &user.ID, ip, models.LoginFailureInvalidPassword)
 	}
-	// Generate JWT token
-	token, err := helpers.CreateToken(user.ID.Hex())
+	if user.DeletedAt != nil {
+		return nil, helpers.FailLogin(ctx, s.DocDB, req.Email, &user.ID, ip, models.LoginFailureDeletedUser)
+	}
+	helpers.ResetLoginFailures(ctx, req.Email)
+
+	// With MFA enabled the client has to complete the login with LoginMFA
+	if user.MFAEnabled() {
+		mfaToken, err := helpers.CreateUserToken(ctx, s.DocDB, user.ID, models.TokenPurposeMFAChallenge, user.Email, helpers.MFAChallengeTTL)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to create MFA challenge: %v", err)
+		}
+		return &auth_service.LoginResponse{
+			MfaRequired: true,
+			MfaToken:    mfaToken,
+		}, nil
+	}
+
+	// Generate access and refresh tokens for a new session
+	tokens, err := helpers.IssueTokens(ctx, s.DocDB, user.ID, "", time.Now())
 	if err != nil {
-		return nil, errors.New("failed to generate token")
+		return nil, status.Errorf(codes.Internal, "failed to generate token: %v", err)
 	}
+	metrics.Logins.WithLabelValues("password").Inc()
 
 	//return login response
 	return &auth_service.LoginResponse{
-		AccessToken: token,
+		AccessToken:  tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresIn:    tokens.ExpiresIn,
 	}, nil
 }
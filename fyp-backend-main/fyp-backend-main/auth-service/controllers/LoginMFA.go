@@ -0,0 +1,87 @@
+package controllers
+
+import (
+	"context"
+	"time"
+
+	"auth-service/config"
+	"auth-service/helpers"
+	"auth-service/metrics"
+	"auth-service/models"
+	auth_service "auth-service/proto/generated/github.com/multiagentai/backend/auth-service"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// LoginMFA completes a login with the MFA challenge token returned by Login and a TOTP or recovery code.
+func (s *AuthServer) LoginMFA(ctx context.Context, req *auth_service.LoginMFARequest) (*auth_service.LoginResponse, error) {
+	// Validate required fields
+	violations := map[string]string{}
+	if req.MfaToken == "" {
+		violations["mfaToken"] = "MFA token is required"
+	}
+	if req.Code == "" {
+		violations["code"] = "code is required"
+	}
+	if len(violations) > 0 {
+		return nil, helpers.InvalidArgumentError(violations)
+	}
+
+	// Find the challenge, it stays valid for a few wrong codes
+	challenge, err := helpers.FindUserToken(ctx, s.DocDB, req.MfaToken, models.TokenPurposeMFAChallenge)
+	if err == helpers.ErrInvalidUserToken {
+		return nil, status.Error(codes.Unauthenticated, "MFA challenge is invalid or expired, please log in again")
+	} else if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get MFA challenge: %v", err)
+	}
+
+	var user models.User
+	err = s.DocDB.Database(config.Current.MongoDatabase).Collection("users").FindOne(ctx, bson.M{"_id": challenge.UserID}).Decode(&user)
+	if err != nil || user.DeletedAt != nil {
+		return nil, status.Error(codes.Unauthenticated, "user not found")
+	}
+
+	// Wrong codes count towards the account's failed logins
+	ip := helpers.ExtractClientIP(ctx)
+	if err := helpers.CheckLoginAllowed(ctx, user.Email, ip); err != nil {
+		helpers.AuditLoginFailure(ctx, s.DocDB, user.Email, &user.ID, ip, models.LoginFailureThrottled)
+		return nil, err
+	}
+
+	// Check the second factor
+	ok, err := helpers.VerifySecondFactor(ctx, s.DocDB, &user, req.Code)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to verify code: %v", err)
+	}
+	if !ok {
+		if err := helpers.RecordFailedTokenAttempt(ctx, s.DocDB, challenge); err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to update MFA challenge: %v", err)
+		}
+		helpers.RecordLoginFailure(ctx, user.Email, ip)
+		helpers.AuditLoginFailure(ctx, s.DocDB, user.Email, &user.ID, ip, models.LoginFailureInvalidMFACode)
+		return nil, status.Error(codes.Unauthenticated, "invalid code")
+	}
+	helpers.ResetLoginFailures(ctx, user.Email)
+
+	// The challenge can only be completed once
+	if _, err := helpers.ConsumeUserToken(ctx, s.DocDB, req.MfaToken, models.TokenPurposeMFAChallenge); err == helpers.ErrInvalidUserToken {
+		return nil, status.Error(codes.Unauthenticated, "MFA challenge is invalid or expired, please log in again")
+	} else if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to complete MFA challenge: %v", err)
+	}
+
+	// Generate access and refresh tokens for a new session
+	tokens, err := helpers.IssueTokens(ctx, s.DocDB, user.ID, "", time.Now())
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to generate token: %v", err)
+	}
+	metrics.Logins.WithLabelValues("mfa").Inc()
+
+	return &auth_service.LoginResponse{
+		AccessToken:  tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresIn:    tokens.ExpiresIn,
+	}, nil
+}
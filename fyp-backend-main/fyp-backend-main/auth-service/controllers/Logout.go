@@ -0,0 +1,67 @@
+package controllers
+
+import (
+	"context"
+
+	"auth-service/config"
+	"auth-service/helpers"
+	"auth-service/models"
+	auth_service "auth-service/proto/generated/github.com/multiagentai/backend/auth-service"
+	"shared/identity"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Logout ends the current session: the access token is denylisted and its refresh token family revoked.
+func (s *AuthServer) Logout(ctx context.Context, req *auth_service.LogoutRequest) (*auth_service.LogoutResponse, error) {
+	// Extract the userID of the verified caller
+	userID, err := identity.UserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	collection := s.DocDB.Database(config.Current.MongoDatabase).Collection("refresh_tokens")
+
+	// Revoke the access token the request was made with
+	families := map[string]bool{}
+	if req.AccessToken != "" {
+		claims, err := helpers.ParseToken(req.AccessToken)
+		if err != nil || claims.Subject != userID {
+			return nil, status.Error(codes.InvalidArgument, "invalid access token")
+		}
+		if err := helpers.RevokeAccessToken(ctx, claims.ID, claims.ExpiresAt.Time); err != nil {
+			return nil, status.Errorf(codes.Unavailable, "failed to revoke access token: %v", err)
+		}
+
+		// The session the access token was issued for
+		var token models.RefreshToken
+		err = collection.FindOne(ctx, bson.M{"access_token_id": claims.ID}).Decode(&token)
+		if err == nil {
+			families[token.FamilyID] = true
+		} else if err != mongo.ErrNoDocuments {
+			return nil, status.Errorf(codes.Internal, "failed to get session: %v", err)
+		}
+	}
+
+	// Revoke the session of the refresh token, if given
+	if req.RefreshToken != "" {
+		var token models.RefreshToken
+		err := collection.FindOne(ctx, bson.M{"token_hash": helpers.HashToken(req.RefreshToken)}).Decode(&token)
+		if err == mongo.ErrNoDocuments || (err == nil && token.UserID.Hex() != userID) {
+			return nil, status.Error(codes.InvalidArgument, "invalid refresh token")
+		} else if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to get refresh token: %v", err)
+		}
+		families[token.FamilyID] = true
+	}
+
+	for familyID := range families {
+		if err := helpers.RevokeTokenFamily(ctx, s.DocDB, familyID); err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to revoke session: %v", err)
+		}
+	}
+
+	return &auth_service.LogoutResponse{}, nil
+}
@@ -0,0 +1,80 @@
+package controllers
+
+import (
+	"context"
+	"time"
+
+	"auth-service/config"
+	"auth-service/helpers"
+	"auth-service/models"
+	auth_service "auth-service/proto/generated/github.com/multiagentai/backend/auth-service"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Refresh exchanges a refresh token for a new access token and a new refresh token.
+// Refresh tokens are single use: presenting one twice revokes the whole session.
+func (s *AuthServer) Refresh(ctx context.Context, req *auth_service.RefreshRequest) (*auth_service.RefreshResponse, error) {
+	if req.RefreshToken == "" {
+		return nil, helpers.InvalidArgumentError(map[string]string{"refreshToken": "refresh token is required"})
+	}
+	collection := s.DocDB.Database(config.Current.MongoDatabase).Collection("refresh_tokens")
+
+	// Find the refresh token by its hash
+	var token models.RefreshToken
+	err := collection.FindOne(ctx, bson.M{"token_hash": helpers.HashToken(req.RefreshToken)}).Decode(&token)
+	if err == mongo.ErrNoDocuments {
+		return nil, status.Error(codes.Unauthenticated, "invalid refresh token")
+	} else if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get refresh token: %v", err)
+	}
+	if token.RevokedAt != nil {
+		return nil, status.Error(codes.Unauthenticated, "refresh token has been revoked")
+	}
+	if time.Now().After(token.ExpiresAt) {
+		return nil, status.Error(codes.Unauthenticated, "refresh token expired")
+	}
+
+	// Mark the token as used, a token that was already used has been stolen or replayed
+	now := time.Now()
+	err = collection.FindOneAndUpdate(ctx,
+		bson.M{"_id": token.ID, "rotated_at": bson.M{"$exists": false}, "revoked_at": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"rotated_at": now}},
+	).Err()
+	if err == mongo.ErrNoDocuments {
+		if err := helpers.RevokeTokenFamily(ctx, s.DocDB, token.FamilyID); err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to revoke session: %v", err)
+		}
+		return nil, status.Error(codes.Unauthenticated, "refresh token reuse detected, session revoked")
+	} else if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to rotate refresh token: %v", err)
+	}
+
+	// Make sure the user still exists
+	var user models.User
+	err = s.DocDB.Database(config.Current.MongoDatabase).Collection("users").FindOne(ctx, bson.M{"_id": token.UserID}).Decode(&user)
+	if err == mongo.ErrNoDocuments || (err == nil && user.DeletedAt != nil) {
+		return nil, status.Error(codes.Unauthenticated, "user not found")
+	} else if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get user: %v", err)
+	}
+
+	// Issue the next tokens of the same session
+	authTime := token.AuthTime
+	if authTime.IsZero() {
+		authTime = token.CreatedAt
+	}
+	tokens, err := helpers.IssueTokens(ctx, s.DocDB, token.UserID, token.FamilyID, authTime)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to generate token: %v", err)
+	}
+
+	return &auth_service.RefreshResponse{
+		AccessToken:  tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresIn:    tokens.ExpiresIn,
+	}, nil
+}
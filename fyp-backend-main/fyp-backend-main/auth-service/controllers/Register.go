@@ -2,10 +2,12 @@ package controllers
 
 import (
 	"context"
-	"errors"
+	"log/slog"
 	"time"
 
+	"auth-service/config"
 	"auth-service/helpers"
+	"auth-service/metrics"
 	"auth-service/models"
 	auth_service "auth-service/proto/generated/github.com/multiagentai/backend/auth-service"
 
@@ -13,21 +15,39 @@ import (
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 func (s *AuthServer) Register(ctx context.Context, req *auth_service.RegisterRequest) (*auth_service.RegisterResponse, error) {
+	// Validate required fields
+	req.Email = helpers.NormalizeEmail(req.Email)
+	violations := map[string]string{}
+	if req.Email == "" {
+		violations["email"] = "email is required"
+	}
+	if req.Password == "" {
+		violations["password"] = "password is required"
+	}
+	if len(violations) > 0 {
+		return nil, helpers.InvalidArgumentError(violations)
+	}
+
 	// Check if user already exists
 	var existingUser models.User
-	err := s.DocDB.Database("fyp-db").Collection("users").FindOne(ctx, bson.M{"email": req.Email}).Decode(&existingUser)
+	err := s.DocDB.Database(config.Current.MongoDatabase).Collection("users").FindOne(ctx, bson.M{"email": req.Email}).Decode(&existingUser)
 	if err == nil {
-		return nil, errors.New("user already exists")
+		return nil, status.Error(codes.AlreadyExists, "user already exists")
+	} else if err != mongo.ErrNoDocuments {
+		return nil, status.Errorf(codes.Internal, "failed to check existing user: %v", err)
 	}
 
 	// Hash password
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
 	if err != nil {
-		return nil, errors.New("failed to hash password")
+		return nil, status.Error(codes.Internal, "failed to hash password")
 	}
 
 	// Create new user
@@ -43,15 +63,27 @@ func (s *AuthServer) Register(ctx context.Context, req *auth_service.RegisterReq
 		UpdatedAt:    time.Now(),
 	}
 
-	_, err = s.DocDB.Database("fyp-db").Collection("users").InsertOne(ctx, newUser)
+	_, err = s.DocDB.Database(config.Current.MongoDatabase).Collection("users").InsertOne(ctx, newUser)
+	if mongo.IsDuplicateKeyError(err) {
+		return nil, status.Error(codes.AlreadyExists, "user already exists")
+	} else if err != nil {
+		return nil, status.Error(codes.Internal, "failed to create user")
+	}
+	metrics.Registrations.WithLabelValues("password").Inc()
+
+	// Send the email verification link, the account works before it's verified
+	verifyToken, err := helpers.CreateUserToken(ctx, s.DocDB, newUser.ID, models.TokenPurposeVerifyEmail, newUser.Email, helpers.VerifyEmailTokenTTL)
+	if err == nil {
+		err = helpers.SendVerificationEmail(ctx, s.Mailer, newUser.Email, verifyToken)
+	}
 	if err != nil {
-		return nil, errors.New("failed to create user")
+		slog.ErrorContext(ctx, "Failed to send verification email", "user_id", newUser.ID.Hex(), "error", err)
 	}
 
 	// Generate token using helper
-	token, err := helpers.CreateToken(newUser.ID.Hex())
+	tokens, err := helpers.IssueTokens(ctx, s.DocDB, newUser.ID, "", time.Now())
 	if err != nil {
-		return nil, errors.New("failed to generate token")
+		return nil, status.Errorf(codes.Internal, "failed to generate token: %v", err)
 	}
 
 	createdAt := timestamppb.New(newUser.CreatedAt)
@@ -59,17 +91,21 @@ func (s *AuthServer) Register(ctx context.Context, req *auth_service.RegisterReq
 	deletedAt := timestamppb.New(time.Time{}) // Set to a zero value for now
 	//Return response
 	return &auth_service.RegisterResponse{
-		Token: token,
+		Token:        tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresIn:    tokens.ExpiresIn,
 		User: &auth_service.User{
-			Id:           newUser.ID.Hex(),
-			Firstname:    newUser.FirstName,
-			Lastname:     newUser.LastName,
-			Email:        newUser.Email,
-			Credits:      newUser.Credits,
-			SigninMethod: newUser.SignInMethod,
-			CreatedAt:    createdAt,
-			UpdatedAt:    updatedAt,
-			DeletedAt:    deletedAt,
+			Id:            newUser.ID.Hex(),
+			Firstname:     newUser.FirstName,
+			Lastname:      newUser.LastName,
+			Email:         newUser.Email,
+			EmailVerified: newUser.EmailVerified,
+			MfaEnabled:    newUser.MFAEnabled(),
+			Credits:       newUser.Credits,
+			SigninMethod:  newUser.SignInMethod,
+			CreatedAt:     createdAt,
+			UpdatedAt:     updatedAt,
+			DeletedAt:     deletedAt,
 		},
 	}, nil
 }
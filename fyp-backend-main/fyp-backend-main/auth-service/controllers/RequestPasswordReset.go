@@ -0,0 +1,51 @@
+package controllers
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"auth-service/config"
+	"auth-service/helpers"
+	"auth-service/models"
+	auth_service "auth-service/proto/generated/github.com/multiagentai/backend/auth-service"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// RequestPasswordReset mails a password reset link. It succeeds whether or not the
+// email belongs to an account so it can't be used to find registered addresses.
+func (s *AuthServer) RequestPasswordReset(ctx context.Context, req *auth_service.RequestPasswordResetRequest) (*auth_service.RequestPasswordResetResponse, error) {
+	req.Email = helpers.NormalizeEmail(req.Email)
+	if req.Email == "" {
+		return nil, helpers.InvalidArgumentError(map[string]string{"email": "email is required"})
+	}
+
+	// Find user by email
+	var user models.User
+	err := s.DocDB.Database(config.Current.MongoDatabase).Collection("users").FindOne(ctx, bson.M{"email": req.Email}).Decode(&user)
+	if err == mongo.ErrNoDocuments || (err == nil && user.DeletedAt != nil) {
+		return &auth_service.RequestPasswordResetResponse{}, nil
+	} else if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get user: %v", err)
+	}
+
+	token, err := helpers.CreateUserToken(ctx, s.DocDB, user.ID, models.TokenPurposeResetPassword, user.Email, helpers.ResetPasswordTokenTTL)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to create reset token: %v", err)
+	}
+
+	// Send in the background so the response time doesn't reveal whether the account exists
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		if err := helpers.SendPasswordResetEmail(ctx, s.Mailer, user.Email, token); err != nil {
+			slog.ErrorContext(ctx, "Failed to send password reset email", "user_id", user.ID.Hex(), "error", err)
+		}
+	}()
+
+	return &auth_service.RequestPasswordResetResponse{}, nil
+}
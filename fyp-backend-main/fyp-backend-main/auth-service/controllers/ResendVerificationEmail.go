@@ -0,0 +1,53 @@
+package controllers
+
+import (
+	"context"
+
+	"auth-service/config"
+	"auth-service/helpers"
+	"auth-service/models"
+	auth_service "auth-service/proto/generated/github.com/multiagentai/backend/auth-service"
+	"shared/identity"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ResendVerificationEmail sends a new verification link, invalidating the previous one.
+func (s *AuthServer) ResendVerificationEmail(ctx context.Context, req *auth_service.ResendVerificationEmailRequest) (*auth_service.ResendVerificationEmailResponse, error) {
+	// Extract the userID of the verified caller
+	userID, err := identity.UserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// Convert userID string to ObjectID
+	objectID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid user ID format")
+	}
+
+	// Find user by ID
+	var user models.User
+	err = s.DocDB.Database(config.Current.MongoDatabase).Collection("users").FindOne(ctx, bson.M{"_id": objectID}).Decode(&user)
+	if err == mongo.ErrNoDocuments || (err == nil && user.DeletedAt != nil) {
+		return nil, status.Error(codes.NotFound, "user not found")
+	} else if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get user: %v", err)
+	}
+	if user.EmailVerified {
+		return nil, status.Error(codes.FailedPrecondition, "email is already verified")
+	}
+
+	token, err := helpers.CreateUserToken(ctx, s.DocDB, user.ID, models.TokenPurposeVerifyEmail, user.Email, helpers.VerifyEmailTokenTTL)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to create verification token: %v", err)
+	}
+	if err := helpers.SendVerificationEmail(ctx, s.Mailer, user.Email, token); err != nil {
+		return nil, status.Errorf(codes.Unavailable, "failed to send verification email: %v", err)
+	}
+
+	return &auth_service.ResendVerificationEmailResponse{}, nil
+}
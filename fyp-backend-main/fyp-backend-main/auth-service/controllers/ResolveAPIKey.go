@@ -0,0 +1,60 @@
+package controllers
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"time"
+
+	"auth-service/config"
+	"auth-service/helpers"
+	"auth-service/models"
+	auth_service "auth-service/proto/generated/github.com/multiagentai/backend/auth-service"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ResolveAPIKey is used by the gateway to turn an API key into the user ID and scopes it grants.
+func (s *AuthServer) ResolveAPIKey(ctx context.Context, req *auth_service.ResolveAPIKeyRequest) (*auth_service.ResolveAPIKeyResponse, error) {
+	if !strings.HasPrefix(req.Key, helpers.APIKeyPrefix) {
+		return nil, status.Error(codes.Unauthenticated, "invalid API key")
+	}
+	collection := s.DocDB.Database(config.Current.MongoDatabase).Collection("api_keys")
+
+	// Find the key by its hash
+	var key models.APIKey
+	err := collection.FindOne(ctx, bson.M{"key_hash": helpers.HashToken(req.Key)}).Decode(&key)
+	if err == mongo.ErrNoDocuments {
+		return nil, status.Error(codes.Unauthenticated, "invalid API key")
+	} else if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get API key: %v", err)
+	}
+	if key.RevokedAt != nil {
+		return nil, status.Error(codes.Unauthenticated, "API key has been revoked")
+	}
+	if time.Now().After(key.ExpiresAt) {
+		return nil, status.Error(codes.Unauthenticated, "API key expired")
+	}
+
+	// Record when the key was last used, at most once per interval
+	now := time.Now()
+	_, err = collection.UpdateOne(ctx,
+		bson.M{"_id": key.ID, "$or": bson.A{
+			bson.M{"last_used_at": bson.M{"$exists": false}},
+			bson.M{"last_used_at": bson.M{"$lt": now.Add(-helpers.APIKeyLastUsedInterval)}},
+		}},
+		bson.M{"$set": bson.M{"last_used_at": now}},
+	)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to update last use of API key", "key_id", key.ID.Hex(), "error", err)
+	}
+
+	return &auth_service.ResolveAPIKeyResponse{
+		UserId: key.UserID.Hex(),
+		Scopes: key.Scopes,
+		KeyId:  key.ID.Hex(),
+	}, nil
+}
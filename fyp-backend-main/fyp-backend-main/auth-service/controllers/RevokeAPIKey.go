@@ -0,0 +1,45 @@
+package controllers
+
+import (
+	"context"
+	"time"
+
+	"auth-service/config"
+	auth_service "auth-service/proto/generated/github.com/multiagentai/backend/auth-service"
+	"shared/identity"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// RevokeAPIKey revokes one of the user's API keys.
+func (s *AuthServer) RevokeAPIKey(ctx context.Context, req *auth_service.RevokeAPIKeyRequest) (*auth_service.RevokeAPIKeyResponse, error) {
+	// Extract the userID of the verified caller
+	userID, err := identity.UserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// Convert IDs to ObjectIDs
+	objectID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid user ID format")
+	}
+	keyID, err := primitive.ObjectIDFromHex(req.Id)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid API key ID format")
+	}
+
+	// Only the owner can revoke the key
+	filter := bson.M{"_id": keyID, "user_id": objectID, "revoked_at": bson.M{"$exists": false}}
+	result, err := s.DocDB.Database(config.Current.MongoDatabase).Collection("api_keys").UpdateOne(ctx, filter, bson.M{"$set": bson.M{"revoked_at": time.Now()}})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to revoke API key: %v", err)
+	}
+	if result.MatchedCount == 0 {
+		return nil, status.Error(codes.NotFound, "API key not found")
+	}
+
+	return &auth_service.RevokeAPIKeyResponse{}, nil
+}
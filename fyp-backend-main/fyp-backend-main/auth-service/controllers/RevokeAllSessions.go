@@ -0,0 +1,37 @@
+package controllers
+
+import (
+	"context"
+
+	"auth-service/helpers"
+	auth_service "auth-service/proto/generated/github.com/multiagentai/backend/auth-service"
+	"shared/identity"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// RevokeAllSessions logs the user out everywhere, revoking all refresh tokens and access tokens.
+func (s *AuthServer) RevokeAllSessions(ctx context.Context, req *auth_service.RevokeAllSessionsRequest) (*auth_service.RevokeAllSessionsResponse, error) {
+	// Extract the userID of the verified caller
+	userID, err := identity.UserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Convert userID string to ObjectID
+	objectID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid user ID format")
+	}
+
+	revoked, err := helpers.RevokeUserSessions(ctx, s.DocDB, objectID)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to revoke sessions: %v", err)
+	}
+
+	return &auth_service.RevokeAllSessionsResponse{
+		RevokedSessions: revoked,
+	}, nil
+}
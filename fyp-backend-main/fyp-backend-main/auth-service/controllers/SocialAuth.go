@@ -1,37 +1,171 @@
 package controllers
 
 import (
-	auth_service "auth-service/proto/generated/github.com/multiagentai/backend/auth-service"
 	"context"
+	"strings"
 	"time"
 
+	"auth-service/config"
+	"auth-service/helpers"
+	"auth-service/metrics"
+	"auth-service/models"
+	auth_service "auth-service/proto/generated/github.com/multiagentai/backend/auth-service"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
-// TODO: Implement SocialAuth function correctly with google auth
+// SocialAuth signs a user in with an ID token from Google or another configured OIDC provider.
+// Unknown users are created, and an existing account with the same verified email is linked.
+// Linking an account whose email was never verified drops its password, MFA and sessions.
 func (s *AuthServer) SocialAuth(ctx context.Context, req *auth_service.SocialAuthRequest) (*auth_service.SocialAuthResponse, error) {
-	//  return a dummy response with dummy token and data
+	if req.Token == "" {
+		return nil, helpers.InvalidArgumentError(map[string]string{"token": "ID token is required"})
+	}
+
+	// Verify the ID token with its provider
+	provider, err := helpers.FindOIDCProvider(req.Provider, req.Token)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	claims, err := provider.Verify(ctx, req.Token, req.Nonce)
+	if err != nil {
+		return nil, status.Errorf(codes.Unauthenticated, "invalid ID token: %v", err)
+	}
+
+	users := s.DocDB.Database(config.Current.MongoDatabase).Collection("users")
+	now := time.Now()
+	created := false
+
+	// Find the user by the linked identity first
+	var user models.User
+	err = users.FindOne(ctx, bson.M{
+		"identities": bson.M{"$elemMatch": bson.M{"provider": provider.Name, "subject": claims.Subject}},
+	}).Decode(&user)
+	if err == mongo.ErrNoDocuments {
+		email := helpers.NormalizeEmail(claims.Email)
+		if email == "" {
+			return nil, status.Error(codes.FailedPrecondition, "the provider did not share an email address")
+		}
+		identity := models.Identity{Provider: provider.Name, Subject: claims.Subject, LinkedAt: now}
 
-	user := &auth_service.User{
-		Id:           "dummyUserId",
-		Firstname:    "John",
-		Lastname:     "Doe",
-		Email:        "john.doe@example.com",
-		Password:     "dummyPassword123",
-		SigninMethod: "email",
-		Credits:      100,
+		// Link to an existing account with the same email, only if the provider verified it
+		err = users.FindOne(ctx, bson.M{"email": email}).Decode(&user)
+		if err == nil {
+			if !claims.IsEmailVerified() {
+				return nil, status.Error(codes.AlreadyExists, "an account with this email already exists")
+			}
+			update := bson.M{
+				"$push": bson.M{"identities": identity},
+				"$set":  bson.M{"email_verified": true, "updated_at": now},
+			}
+			// Anyone could have registered an unverified email before its owner signed in here,
+			// so the password, second factor and sessions of that account are dropped
+			if !user.EmailVerified {
+				update["$unset"] = bson.M{"password": "", "mfa": ""}
+			}
+			if _, err = users.UpdateOne(ctx, bson.M{"_id": user.ID}, update); err != nil {
+				return nil, status.Errorf(codes.Internal, "failed to link account: %v", err)
+			}
+			if !user.EmailVerified {
+				if err := s.revokeUnverifiedAccess(ctx, user.ID); err != nil {
+					return nil, err
+				}
+				user.Password = ""
+				user.MFA = nil
+			}
+			user.Identities = append(user.Identities, identity)
+			user.EmailVerified = true
+		} else if err == mongo.ErrNoDocuments {
+			// Create new user
+			firstName, lastName := claims.GivenName, claims.FamilyName
+			if firstName == "" && lastName == "" {
+				firstName, lastName, _ = strings.Cut(claims.Name, " ")
+			}
+			user = models.User{
+				ID:            primitive.NewObjectID(),
+				FirstName:     firstName,
+				LastName:      lastName,
+				Email:         email,
+				EmailVerified: claims.IsEmailVerified(),
+				SignInMethod:  provider.Name,
+				Identities:    []models.Identity{identity},
+				Credits:       0,
+				CreatedAt:     now,
+				UpdatedAt:     now,
+			}
+			if _, err := users.InsertOne(ctx, user); mongo.IsDuplicateKeyError(err) {
+				return nil, status.Error(codes.AlreadyExists, "an account with this email already exists")
+			} else if err != nil {
+				return nil, status.Errorf(codes.Internal, "failed to create user: %v", err)
+			}
+			created = true
+			metrics.Registrations.WithLabelValues(provider.Name).Inc()
+		} else {
+			return nil, status.Errorf(codes.Internal, "failed to get user: %v", err)
+		}
+	} else if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get user: %v", err)
 	}
+	if user.DeletedAt != nil {
+		return nil, status.Error(codes.PermissionDenied, "account has been deleted")
+	}
+
+	res := &auth_service.SocialAuthResponse{Created: created}
 
-	createdAt := timestamppb.New(time.Now())
-	updatedAt := timestamppb.New(time.Now())
+	// With MFA enabled the client has to complete the login with LoginMFA
+	if user.MFAEnabled() {
+		res.MfaRequired = true
+		res.MfaToken, err = helpers.CreateUserToken(ctx, s.DocDB, user.ID, models.TokenPurposeMFAChallenge, user.Email, helpers.MFAChallengeTTL)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to create MFA challenge: %v", err)
+		}
+	} else {
+		// Generate access and refresh tokens for a new session
+		tokens, err := helpers.IssueTokens(ctx, s.DocDB, user.ID, "", time.Now())
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to generate token: %v", err)
+		}
+		res.Token = tokens.AccessToken
+		res.RefreshToken = tokens.RefreshToken
+		res.ExpiresIn = tokens.ExpiresIn
+		metrics.Logins.WithLabelValues(provider.Name).Inc()
+	}
+
+	createdAt := timestamppb.New(user.CreatedAt)
+	updatedAt := timestamppb.New(user.UpdatedAt)
 	deletedAt := timestamppb.New(time.Time{}) // Set to a zero value for now
-	user.CreatedAt = createdAt
-	user.UpdatedAt = updatedAt
-	user.DeletedAt = deletedAt
-
-	// Returning a dummy token and user data
-	return &auth_service.SocialAuthResponse{
-		Token: "dummy-social-token",
-		User:  user,
-	}, nil
+	res.User = &auth_service.User{
+		Id:            user.ID.Hex(),
+		Firstname:     user.FirstName,
+		Lastname:      user.LastName,
+		Email:         user.Email,
+		EmailVerified: user.EmailVerified,
+		MfaEnabled:    user.MFAEnabled(),
+		Credits:       user.Credits,
+		SigninMethod:  user.SignInMethod,
+		CreatedAt:     createdAt,
+		UpdatedAt:     updatedAt,
+		DeletedAt:     deletedAt,
+	}
+	return res, nil
+}
+
+// revokeUnverifiedAccess logs an account that never proved its email out everywhere, API keys included
+func (s *AuthServer) revokeUnverifiedAccess(ctx context.Context, userID primitive.ObjectID) error {
+	if _, err := helpers.RevokeUserSessions(ctx, s.DocDB, userID); err != nil {
+		return status.Errorf(codes.Internal, "failed to revoke sessions: %v", err)
+	}
+	_, err := s.DocDB.Database(config.Current.MongoDatabase).Collection("api_keys").UpdateMany(ctx,
+		bson.M{"user_id": userID, "revoked_at": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"revoked_at": time.Now()}},
+	)
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to revoke API keys: %v", err)
+	}
+	return nil
 }
@@ -0,0 +1,64 @@
+package controllers
+
+import (
+	"context"
+	"time"
+
+	"auth-service/config"
+	"auth-service/helpers"
+	"auth-service/models"
+	auth_service "auth-service/proto/generated/github.com/multiagentai/backend/auth-service"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// VerifyEmail marks the user's email as verified using the token sent to it.
+func (s *AuthServer) VerifyEmail(ctx context.Context, req *auth_service.VerifyEmailRequest) (*auth_service.VerifyEmailResponse, error) {
+	if req.Token == "" {
+		return nil, helpers.InvalidArgumentError(map[string]string{"token": "token is required"})
+	}
+
+	token, err := helpers.ConsumeUserToken(ctx, s.DocDB, req.Token, models.TokenPurposeVerifyEmail)
+	if err == helpers.ErrInvalidUserToken {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	} else if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to check verification token: %v", err)
+	}
+
+	// Only verify the address the token was sent to, the email may have changed since
+	filter := bson.M{"_id": token.UserID, "email": token.Email, "deleted_at": bson.M{"$exists": false}}
+	update := bson.M{"$set": bson.M{"email_verified": true, "updated_at": time.Now()}}
+	var user models.User
+	err = s.DocDB.Database(config.Current.MongoDatabase).Collection("users").
+		FindOneAndUpdate(ctx, filter, update, options.FindOneAndUpdate().SetReturnDocument(options.After)).
+		Decode(&user)
+	if err == mongo.ErrNoDocuments {
+		return nil, status.Error(codes.FailedPrecondition, "the email address has changed since the link was sent")
+	} else if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to update user: %v", err)
+	}
+
+	createdAt := timestamppb.New(user.CreatedAt)
+	updatedAt := timestamppb.New(user.UpdatedAt)
+	deletedAt := timestamppb.New(time.Time{}) // Set to a zero value for now
+	return &auth_service.VerifyEmailResponse{
+		User: &auth_service.User{
+			Id:            user.ID.Hex(),
+			Firstname:     user.FirstName,
+			Lastname:      user.LastName,
+			Email:         user.Email,
+			EmailVerified: user.EmailVerified,
+			MfaEnabled:    user.MFAEnabled(),
+			Credits:       user.Credits,
+			SigninMethod:  user.SignInMethod,
+			CreatedAt:     createdAt,
+			UpdatedAt:     updatedAt,
+			DeletedAt:     deletedAt,
+		},
+	}, nil
+}
@@ -0,0 +1,85 @@
+package controllers
+
+import (
+	"context"
+	"time"
+
+	"auth-service/config"
+	"auth-service/helpers"
+	"auth-service/models"
+	auth_service "auth-service/proto/generated/github.com/multiagentai/backend/auth-service"
+	"shared/identity"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// VerifyMFA confirms enrollment with a code from the authenticator app, enables MFA
+// and returns the recovery codes. They are only shown this once.
+func (s *AuthServer) VerifyMFA(ctx context.Context, req *auth_service.VerifyMFARequest) (*auth_service.VerifyMFAResponse, error) {
+	// Extract the userID of the verified caller
+	userID, err := identity.UserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// Convert userID string to ObjectID
+	objectID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid user ID format")
+	}
+	if req.Code == "" {
+		return nil, helpers.InvalidArgumentError(map[string]string{"code": "code is required"})
+	}
+
+	// Find user by ID
+	users := s.DocDB.Database(config.Current.MongoDatabase).Collection("users")
+	var user models.User
+	err = users.FindOne(ctx, bson.M{"_id": objectID}).Decode(&user)
+	if err == mongo.ErrNoDocuments || (err == nil && user.DeletedAt != nil) {
+		return nil, status.Error(codes.NotFound, "user not found")
+	} else if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get user: %v", err)
+	}
+	if user.MFAEnabled() {
+		return nil, status.Error(codes.FailedPrecondition, "MFA is already enabled")
+	}
+	if user.MFA == nil || user.MFA.PendingSecret == "" {
+		return nil, status.Error(codes.FailedPrecondition, "MFA enrollment has not been started")
+	}
+
+	step, ok := helpers.ValidateTOTP(user.MFA.PendingSecret, req.Code, 0)
+	if !ok {
+		return nil, status.Error(codes.InvalidArgument, "invalid code")
+	}
+
+	recoveryCodes, hashes, err := helpers.GenerateRecoveryCodes()
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to generate recovery codes")
+	}
+
+	// Enable MFA, unless the pending secret was replaced in the meantime
+	now := time.Now()
+	result, err := users.UpdateOne(ctx,
+		bson.M{"_id": objectID, "mfa.pending_secret": user.MFA.PendingSecret},
+		bson.M{"$set": bson.M{"mfa": models.MFA{
+			Enabled:       true,
+			Secret:        user.MFA.PendingSecret,
+			RecoveryCodes: hashes,
+			LastStep:      step,
+			EnabledAt:     &now,
+		}}},
+	)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to update user: %v", err)
+	}
+	if result.MatchedCount == 0 {
+		return nil, status.Error(codes.Aborted, "MFA enrollment changed, please start again")
+	}
+
+	return &auth_service.VerifyMFAResponse{
+		RecoveryCodes: recoveryCodes,
+	}, nil
+}
@@ -1,6 +1,7 @@
 package controllers
 
 import (
+	"auth-service/mailer"
 	auth_service "auth-service/proto/generated/github.com/multiagentai/backend/auth-service"
 
 	"go.mongodb.org/mongo-driver/mongo"
@@ -9,5 +10,6 @@ import (
 // AuthServer implements the AuthService server
 type AuthServer struct {
 	auth_service.UnimplementedAuthServiceServer
-	DocDB *mongo.Client // MongoDB client to interact with the database
+	DocDB  *mongo.Client // MongoDB client to interact with the database
+	Mailer mailer.Mailer // Sends verification and password reset emails
 }
@@ -0,0 +1,62 @@
+package helpers
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"time"
+
+	"auth-service/models"
+	auth_service "auth-service/proto/generated/github.com/multiagentai/backend/auth-service"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// APIKeyPrefix starts every API key so it can be told apart from a JWT.
+const APIKeyPrefix = "pat_"
+
+// API key lifetime in days
+const (
+	DefaultAPIKeyDays = 90
+	MaxAPIKeyDays     = 365
+)
+
+// APIKeyLastUsedInterval limits how often the last-used timestamp is written.
+const APIKeyLastUsedInterval = time.Minute
+
+// APIKeyScopes are the scopes an API key can be granted. Each covers a gateway route group.
+var APIKeyScopes = map[string]bool{
+	"user:read":         true,
+	"project:read":      true,
+	"project:write":     true,
+	"workflow:read":     true,
+	"workflow:write":    true,
+	"integration:read":  true,
+	"integration:write": true,
+	"docs:read":         true,
+	"docs:write":        true,
+}
+
+// GenerateAPIKey returns a new random API key.
+func GenerateAPIKey() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return APIKeyPrefix + base64.RawURLEncoding.EncodeToString(secret), nil
+}
+
+// APIKeyToProto converts a stored API key for responses, never including the key itself.
+func APIKeyToProto(key *models.APIKey) *auth_service.APIKey {
+	res := &auth_service.APIKey{
+		Id:        key.ID.Hex(),
+		Name:      key.Name,
+		Prefix:    key.Prefix,
+		Scopes:    key.Scopes,
+		CreatedAt: timestamppb.New(key.CreatedAt),
+		ExpiresAt: timestamppb.New(key.ExpiresAt),
+	}
+	if key.LastUsedAt != nil {
+		res.LastUsedAt = timestamppb.New(*key.LastUsedAt)
+	}
+	return res
+}
@@ -0,0 +1,53 @@
+package helpers
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"auth-service/config"
+	"auth-service/metrics"
+	"auth-service/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// AuditLoginFailure stores an audit record of a failed login. userID is nil for unknown accounts.
+func AuditLoginFailure(ctx context.Context, db *mongo.Client, email string, userID *primitive.ObjectID, ip string, reason string) {
+	metrics.LoginFailures.WithLabelValues(reason).Inc()
+	_, err := db.Database(config.Current.MongoDatabase).Collection("login_audit").InsertOne(ctx, models.LoginAudit{
+		ID:        primitive.NewObjectID(),
+		Email:     normalizeEmail(email),
+		UserID:    userID,
+		ClientIP:  ip,
+		Reason:    reason,
+		CreatedAt: time.Now(),
+	})
+	if err != nil {
+		slog.Error("Failed to write login audit record", "error", err)
+	}
+}
+
+// FailLogin records a failed login for throttling and auditing, and returns the uniform error.
+func FailLogin(ctx context.Context, db *mongo.Client, email string, userID *primitive.ObjectID, ip string, reason string) error {
+	RecordLoginFailure(ctx, email, ip)
+	AuditLoginFailure(ctx, db, email, userID, ip, reason)
+	return ErrInvalidCredentials
+}
+
+var (
+	dummyHashOnce sync.Once
+	dummyHash     []byte
+)
+
+// CompareDummyPassword spends as long as a real password check so unknown
+// emails can't be told apart from wrong passwords by timing.
+func CompareDummyPassword(password string) {
+	dummyHashOnce.Do(func() {
+		dummyHash, _ = bcrypt.GenerateFromPassword([]byte("dummy-password"), bcrypt.DefaultCost)
+	})
+	_ = bcrypt.CompareHashAndPassword(dummyHash, []byte(password))
+}
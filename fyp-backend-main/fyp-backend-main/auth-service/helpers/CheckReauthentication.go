@@ -0,0 +1,27 @@
+package helpers
+
+import (
+	"context"
+	"time"
+
+	"auth-service/models"
+
+	"golang.org/x/crypto/bcrypt"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// CheckReauthentication guards sensitive account changes: the user must have signed in
+// within FreshAuthWindow or confirm their current password.
+func CheckReauthentication(ctx context.Context, user *models.User, currentPassword string) error {
+	if time.Since(ExtractAuthTimeFromContext(ctx)) < FreshAuthWindow {
+		return nil
+	}
+	if currentPassword == "" {
+		return status.Error(codes.PermissionDenied, "current password is required for this change")
+	}
+	if user.Password == "" || bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(currentPassword)) != nil {
+		return status.Error(codes.PermissionDenied, "current password is incorrect")
+	}
+	return nil
+}
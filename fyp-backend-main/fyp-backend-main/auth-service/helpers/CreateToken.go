@@ -1,29 +1,63 @@
 package helpers
 
 import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
 	"time"
 
 	"github.com/golang-jwt/jwt/v4"
 )
 
-// CreateToken generates a signed JWT token for the given user ID.
-func CreateToken(userID string) (string, error) {
-	secretKey := []byte("abcd") // Replace with a secure key or fetch from env variables
+// AccessTokenTTL is how long an access token stays valid. Sessions are kept alive with refresh tokens.
+const AccessTokenTTL = 15 * time.Minute
+
+// AccessClaims are the claims of an access token. AuthTime is when the user last
+// entered their credentials, it is carried over when the token is refreshed.
+type AccessClaims struct {
+	jwt.RegisteredClaims
+	AuthTime int64 `json:"auth_time,omitempty"`
+}
+
+// CreateToken generates a signed JWT token for the given user ID, using tokenID as the jti claim.
+func CreateToken(userID string, tokenID string, authTime time.Time) (string, error) {
+	if Keys == nil || Keys.Active == nil {
+		return "", errors.New("signing keys are not loaded")
+	}
 
 	// Create token claims
-	claims := jwt.MapClaims{
-		"sub": userID,                                // Subject (user ID)
-		"exp": time.Now().Add(time.Hour * 24).Unix(), // Token valid for 24 hours
+	now := time.Now()
+	claims := AccessClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    Keys.Issuer,
+			Subject:   userID, // Subject (user ID)
+			Audience:  jwt.ClaimStrings{Keys.Audience},
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(AccessTokenTTL)),
+			ID:        tokenID,
+		},
+		AuthTime: authTime.Unix(),
 	}
 
-	// Create a new token with the claims
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	// Create a new token with the claims, tagged with the signing key ID
+	token := jwt.NewWithClaims(Keys.Active.Method, claims)
+	token.Header["kid"] = Keys.Active.ID
 
-	// Sign the token with the secret key
-	tokenString, err := token.SignedString(secretKey)
+	// Sign the token with the active private key
+	tokenString, err := token.SignedString(Keys.Active.PrivateKey)
 	if err != nil {
 		return "", err
 	}
 
 	return tokenString, nil
 }
+
+// NewTokenID returns a random identifier for the jti claim and token families.
+func NewTokenID() (string, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(id), nil
+}
@@ -0,0 +1,21 @@
+package helpers
+
+import (
+	"context"
+	"time"
+
+	"shared/identity"
+)
+
+// FreshAuthWindow is how long after signing in a user may change their email or password without re-entering it.
+const FreshAuthWindow = 5 * time.Minute
+
+// ExtractAuthTimeFromContext returns when the caller signed in, as asserted by the gateway.
+// The zero time is returned if it's missing.
+func ExtractAuthTimeFromContext(ctx context.Context) time.Time {
+	p, ok := identity.FromContext(ctx)
+	if !ok {
+		return time.Time{}
+	}
+	return p.AuthTime
+}
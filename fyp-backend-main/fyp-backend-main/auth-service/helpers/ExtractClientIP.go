@@ -0,0 +1,18 @@
+package helpers
+
+import (
+	"context"
+	"net"
+
+	"shared/identity"
+)
+
+// ExtractClientIP returns the client IP asserted by the gateway,
+// or an empty string if it's missing or malformed.
+func ExtractClientIP(ctx context.Context) string {
+	p, ok := identity.FromContext(ctx)
+	if !ok || net.ParseIP(p.ClientIP) == nil {
+		return ""
+	}
+	return p.ClientIP
+}
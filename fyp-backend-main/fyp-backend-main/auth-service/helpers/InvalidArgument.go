@@ -0,0 +1,33 @@
+package helpers
+
+import (
+	"sort"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// InvalidArgumentError builds an InvalidArgument status carrying one field
+// violation per entry so the gateway can report them individually.
+func InvalidArgumentError(violations map[string]string) error {
+	fields := make([]string, 0, len(violations))
+	for field := range violations {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	badRequest := &errdetails.BadRequest{}
+	for _, field := range fields {
+		badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field:       field,
+			Description: violations[field],
+		})
+	}
+
+	st, err := status.New(codes.InvalidArgument, "invalid request").WithDetails(badRequest)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, "invalid request")
+	}
+	return st.Err()
+}
@@ -0,0 +1,170 @@
+package helpers
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+
+	"auth-service/config"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// SigningKey is a key pair used to sign access tokens.
+type SigningKey struct {
+	ID         string
+	Method     jwt.SigningMethod
+	PrivateKey crypto.Signer
+}
+
+// VerificationKey is a public key published in the JWKS document.
+type VerificationKey struct {
+	ID        string
+	Method    jwt.SigningMethod
+	PublicKey crypto.PublicKey
+}
+
+// KeyStore holds the active signing key and every key tokens may still be verified with.
+type KeyStore struct {
+	Active           *SigningKey
+	VerificationKeys []VerificationKey
+	Issuer           string
+	Audience         string
+}
+
+// Keys is the key store used by CreateToken, loaded once at startup.
+var Keys *KeyStore
+
+// LoadKeyStore reads the signing keys configured by:
+//
+//	JWT_SIGNING_KEY_FILE   PEM encoded RSA or EC (P-256) private key used to sign new tokens
+//	JWT_SIGNING_KEY_ID     optional kid for the signing key, defaults to its RFC 7638 thumbprint
+//	JWT_RETIRED_KEY_FILES  comma separated PEM files ([kid=]path) still accepted for verification
+//	JWT_ISSUER             iss claim of issued tokens
+//	JWT_AUDIENCE           aud claim of issued tokens
+//
+// Without a signing key an ephemeral ES256 key is generated, which is only suitable for local development.
+func LoadKeyStore(cfg *config.Config) (*KeyStore, error) {
+	store := &KeyStore{
+		Issuer:   cfg.JWTIssuer,
+		Audience: cfg.JWTAudience,
+	}
+
+	// Active signing key
+	var signer crypto.Signer
+	if path := cfg.JWTSigningKeyFile; path != "" {
+		key, err := readPEMKey(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load signing key: %w", err)
+		}
+		var ok bool
+		signer, ok = key.(crypto.Signer)
+		if !ok {
+			return nil, fmt.Errorf("signing key %s is not a private key", path)
+		}
+	} else {
+		slog.Warn("JWT_SIGNING_KEY_FILE is not set, generating an ephemeral signing key (tokens won't survive a restart)")
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate signing key: %w", err)
+		}
+		signer = key
+	}
+
+	method, err := signingMethodFor(signer.Public())
+	if err != nil {
+		return nil, err
+	}
+	kid := cfg.JWTSigningKeyID
+	if kid == "" {
+		kid, err = Thumbprint(signer.Public())
+		if err != nil {
+			return nil, err
+		}
+	}
+	store.Active = &SigningKey{ID: kid, Method: method, PrivateKey: signer}
+	store.VerificationKeys = append(store.VerificationKeys, VerificationKey{ID: kid, Method: method, PublicKey: signer.Public()})
+
+	// Retired keys are only published so tokens they signed stay valid until they expire
+	for _, entry := range cfg.JWTRetiredKeyFiles {
+		retiredKID, path := "", entry
+		if i := strings.Index(entry, "="); i > 0 {
+			retiredKID, path = entry[:i], entry[i+1:]
+		}
+
+		key, err := readPEMKey(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load retired key: %w", err)
+		}
+		publicKey := key
+		if privateKey, ok := key.(crypto.Signer); ok {
+			publicKey = privateKey.Public()
+		}
+		retiredMethod, err := signingMethodFor(publicKey)
+		if err != nil {
+			return nil, err
+		}
+		if retiredKID == "" {
+			retiredKID, err = Thumbprint(publicKey)
+			if err != nil {
+				return nil, err
+			}
+		}
+		store.VerificationKeys = append(store.VerificationKeys, VerificationKey{ID: retiredKID, Method: retiredMethod, PublicKey: publicKey})
+	}
+
+	slog.Info("Signing tokens", "alg", method.Alg(), "kid", kid, "verification_keys", len(store.VerificationKeys))
+	return store, nil
+}
+
+// signingMethodFor picks RS256 for RSA keys and ES256 for P-256 keys
+func signingMethodFor(publicKey crypto.PublicKey) (jwt.SigningMethod, error) {
+	switch key := publicKey.(type) {
+	case *rsa.PublicKey:
+		if key.N.BitLen() < 2048 {
+			return nil, errors.New("RSA keys must be at least 2048 bits")
+		}
+		return jwt.SigningMethodRS256, nil
+	case *ecdsa.PublicKey:
+		if key.Curve != elliptic.P256() {
+			return nil, errors.New("only P-256 EC keys are supported")
+		}
+		return jwt.SigningMethodES256, nil
+	}
+	return nil, fmt.Errorf("unsupported key type %T", publicKey)
+}
+
+// readPEMKey parses the first PEM block of a file as a private or public key
+func readPEMKey(path string) (any, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%s does not contain a PEM block", path)
+	}
+
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		return x509.ParseECPrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		return x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		return x509.ParsePKIXPublicKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		return x509.ParsePKCS1PublicKey(block.Bytes)
+	}
+	return nil, fmt.Errorf("%s: unsupported PEM block %q", path, block.Type)
+}
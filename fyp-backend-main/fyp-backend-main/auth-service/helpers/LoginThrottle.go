@@ -0,0 +1,133 @@
+package helpers
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"time"
+
+	"auth-service/utils"
+
+	"github.com/redis/go-redis/v9"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// Failed login policy. Failures are counted per email address, whether or not it
+// belongs to an account, and per client IP.
+const (
+	loginFailureWindow = 15 * time.Minute
+
+	// After loginDelayThreshold failures each attempt has to wait, doubling up to maxLoginDelay
+	loginDelayThreshold = 3
+	maxLoginDelay       = 30 * time.Second
+
+	// After accountLockThreshold failures the account is locked for accountLockDuration
+	accountLockThreshold = 10
+	accountLockDuration  = 15 * time.Minute
+
+	// After ipBlockThreshold failures from one IP it's blocked for ipBlockDuration
+	ipBlockThreshold = 100
+	ipBlockDuration  = 15 * time.Minute
+)
+
+const loginKeyPrefix = "auth:login:"
+
+// ErrInvalidCredentials is returned for every failed login so responses don't reveal which accounts exist.
+var ErrInvalidCredentials = status.Error(codes.Unauthenticated, "invalid email or password")
+
+// CheckLoginAllowed returns a ResourceExhausted error with retry info while the account or IP is
+// locked or has to wait before the next attempt. If Redis is unreachable the login is allowed.
+func CheckLoginAllowed(ctx context.Context, email string, ip string) error {
+	keys := []string{
+		loginKeyPrefix + "lock:account:" + normalizeEmail(email),
+		loginKeyPrefix + "wait:account:" + normalizeEmail(email),
+	}
+	if ip != "" {
+		keys = append(keys, loginKeyPrefix+"lock:ip:"+ip)
+	}
+
+	pipe := utils.RDB.Pipeline()
+	ttls := make([]*redis.DurationCmd, len(keys))
+	for i, key := range keys {
+		ttls[i] = pipe.PTTL(ctx, key)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		slog.Error("Failed to check login throttling", "error", err)
+		return nil
+	}
+
+	var wait time.Duration
+	for _, ttl := range ttls {
+		if ttl.Val() > wait {
+			wait = ttl.Val()
+		}
+	}
+	if wait <= 0 {
+		return nil
+	}
+
+	st, err := status.New(codes.ResourceExhausted, "too many failed login attempts, try again later").
+		WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(wait)})
+	if err != nil {
+		return status.Error(codes.ResourceExhausted, "too many failed login attempts, try again later")
+	}
+	return st.Err()
+}
+
+// RecordLoginFailure counts a failed attempt and applies delays, lockouts and IP blocks.
+func RecordLoginFailure(ctx context.Context, email string, ip string) {
+	account := normalizeEmail(email)
+	accountKey := loginKeyPrefix + "failures:account:" + account
+	ipKey := loginKeyPrefix + "failures:ip:" + ip
+
+	// Count the failure, the window starts with the first one
+	var accountFailures, ipFailures *redis.IntCmd
+	_, err := utils.RDB.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		accountFailures = pipe.Incr(ctx, accountKey)
+		pipe.ExpireNX(ctx, accountKey, loginFailureWindow)
+		if ip != "" {
+			ipFailures = pipe.Incr(ctx, ipKey)
+			pipe.ExpireNX(ctx, ipKey, loginFailureWindow)
+		}
+		return nil
+	})
+	if err != nil {
+		slog.Error("Failed to record login failure", "error", err)
+		return
+	}
+
+	pipe := utils.RDB.Pipeline()
+	if failures := accountFailures.Val(); failures >= accountLockThreshold {
+		pipe.Set(ctx, loginKeyPrefix+"lock:account:"+account, 1, accountLockDuration)
+		pipe.Del(ctx, accountKey)
+	} else if failures >= loginDelayThreshold {
+		delay := time.Second << (failures - loginDelayThreshold)
+		if delay > maxLoginDelay {
+			delay = maxLoginDelay
+		}
+		pipe.Set(ctx, loginKeyPrefix+"wait:account:"+account, 1, delay)
+	}
+	if ipFailures != nil && ipFailures.Val() >= ipBlockThreshold {
+		pipe.Set(ctx, loginKeyPrefix+"lock:ip:"+ip, 1, ipBlockDuration)
+		pipe.Del(ctx, ipKey)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		slog.Error("Failed to apply login throttling", "error", err)
+	}
+}
+
+// ResetLoginFailures clears an account's failure count after a successful login.
+func ResetLoginFailures(ctx context.Context, email string) {
+	account := normalizeEmail(email)
+	err := utils.RDB.Del(ctx, loginKeyPrefix+"failures:account:"+account, loginKeyPrefix+"wait:account:"+account).Err()
+	if err != nil {
+		slog.Error("Failed to reset login failures", "error", err)
+	}
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
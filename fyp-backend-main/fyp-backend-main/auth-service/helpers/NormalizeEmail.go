@@ -0,0 +1,9 @@
+package helpers
+
+import "strings"
+
+// NormalizeEmail returns the form emails are stored and looked up in, trimmed and
+// lower case, so an address matches however its owner typed it
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
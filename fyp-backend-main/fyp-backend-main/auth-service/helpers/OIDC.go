@@ -0,0 +1,293 @@
+package helpers
+
+import (
+	"context"
+	"crypto"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"auth-service/config"
+	"shared/envconfig"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+const (
+	googleIssuer = "https://accounts.google.com"
+	// oidcKeysMaxAge is how long a provider's JWKS is cached
+	oidcKeysMaxAge = time.Hour
+	// oidcMinRefreshInterval rate limits refreshes triggered by unknown key IDs
+	oidcMinRefreshInterval = time.Minute
+)
+
+// OIDCProvider is an OpenID Connect issuer whose ID tokens are accepted by SocialAuth.
+type OIDCProvider struct {
+	Name         string
+	Issuer       string
+	ClientIDs    []string // Accepted aud values
+	DiscoveryURL string
+	JWKSURL      string // Skips discovery when set
+	RequireNonce bool
+
+	mu          sync.Mutex
+	keys        map[string]crypto.PublicKey
+	lastRefresh time.Time
+}
+
+// IDTokenClaims are the claims SocialAuth reads from an ID token.
+type IDTokenClaims struct {
+	jwt.RegisteredClaims
+	Email         string `json:"email"`
+	EmailVerified any    `json:"email_verified"` // Some providers send "true" as a string
+	Name          string `json:"name"`
+	GivenName     string `json:"given_name"`
+	FamilyName    string `json:"family_name"`
+	Nonce         string `json:"nonce"`
+}
+
+// IsEmailVerified reports whether the provider vouches for the email address.
+func (c *IDTokenClaims) IsEmailVerified() bool {
+	switch v := c.EmailVerified.(type) {
+	case bool:
+		return v
+	case string:
+		return v == "true"
+	}
+	return false
+}
+
+// OIDCProviders are the configured identity providers, keyed by name.
+var OIDCProviders = map[string]*OIDCProvider{}
+
+var oidcHTTPClient = &http.Client{Timeout: 10 * time.Second}
+
+// LoadOIDCProviders reads the configured identity providers.
+//
+// Google is enabled by OIDC_GOOGLE_CLIENT_ID (or GOOGLE_CLIENT_ID). Other providers are
+// listed in OIDC_PROVIDERS=name,... and configured with OIDC_<NAME>_ISSUER, OIDC_<NAME>_CLIENT_ID
+// (comma separated), and optionally OIDC_<NAME>_DISCOVERY_URL, OIDC_<NAME>_JWKS_URL and
+// OIDC_<NAME>_REQUIRE_NONCE (default true). Pointing the URLs at a mock IdP is enough for tests.
+func LoadOIDCProviders(cfg *config.Config) (map[string]*OIDCProvider, error) {
+	providers := map[string]*OIDCProvider{}
+
+	names := []string{}
+	if envconfig.Lookup("OIDC_GOOGLE_CLIENT_ID") != "" || cfg.GoogleClientID != "" {
+		names = append(names, "google")
+	}
+	for _, name := range cfg.OIDCProviders {
+		name = strings.ToLower(name)
+		if name != "google" {
+			names = append(names, name)
+		}
+	}
+
+	for _, name := range names {
+		prefix := "OIDC_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_")) + "_"
+		provider := &OIDCProvider{
+			Name:         name,
+			Issuer:       strings.TrimSuffix(envconfig.Lookup(prefix+"ISSUER"), "/"),
+			DiscoveryURL: envconfig.Lookup(prefix + "DISCOVERY_URL"),
+			JWKSURL:      envconfig.Lookup(prefix + "JWKS_URL"),
+			RequireNonce: envconfig.Lookup(prefix+"REQUIRE_NONCE") != "false",
+		}
+		clientIDs := envconfig.Lookup(prefix + "CLIENT_ID")
+		if name == "google" {
+			if provider.Issuer == "" {
+				provider.Issuer = googleIssuer
+			}
+			if clientIDs == "" {
+				clientIDs = cfg.GoogleClientID
+			}
+		}
+		for _, id := range strings.Split(clientIDs, ",") {
+			if id = strings.TrimSpace(id); id != "" {
+				provider.ClientIDs = append(provider.ClientIDs, id)
+			}
+		}
+
+		if provider.Issuer == "" {
+			return nil, fmt.Errorf("%sISSUER is required", prefix)
+		}
+		if len(provider.ClientIDs) == 0 {
+			return nil, fmt.Errorf("%sCLIENT_ID is required", prefix)
+		}
+		if provider.DiscoveryURL == "" {
+			provider.DiscoveryURL = provider.Issuer + "/.well-known/openid-configuration"
+		}
+		providers[name] = provider
+		slog.Info("Accepting ID tokens", "provider", name, "issuer", provider.Issuer)
+	}
+	return providers, nil
+}
+
+// FindOIDCProvider returns the provider by name, or the one matching the token's issuer when name is empty.
+func FindOIDCProvider(name string, idToken string) (*OIDCProvider, error) {
+	if name != "" {
+		provider, ok := OIDCProviders[strings.ToLower(name)]
+		if !ok {
+			return nil, fmt.Errorf("unknown provider %q", name)
+		}
+		return provider, nil
+	}
+
+	// Peek at the unverified issuer, the signature is checked by Verify
+	claims := jwt.RegisteredClaims{}
+	if _, _, err := jwt.NewParser().ParseUnverified(idToken, &claims); err != nil {
+		return nil, errors.New("malformed ID token")
+	}
+	for _, provider := range OIDCProviders {
+		if provider.matchesIssuer(claims.Issuer) {
+			return provider, nil
+		}
+	}
+	return nil, fmt.Errorf("no provider configured for issuer %q", claims.Issuer)
+}
+
+// Verify checks the ID token's signature against the issuer's JWKS as well as
+// its issuer, audience, expiry and nonce, and returns its claims.
+func (p *OIDCProvider) Verify(ctx context.Context, idToken string, nonce string) (*IDTokenClaims, error) {
+	claims := &IDTokenClaims{}
+	parser := jwt.NewParser(jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "ES256", "ES384"}))
+	_, err := parser.ParseWithClaims(idToken, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return p.key(ctx, kid)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if !p.matchesIssuer(claims.Issuer) {
+		return nil, errors.New("invalid issuer")
+	}
+	if claims.ExpiresAt == nil {
+		return nil, errors.New("token has no expiry")
+	}
+	audienceOK := false
+	for _, clientID := range p.ClientIDs {
+		if claims.VerifyAudience(clientID, true) {
+			audienceOK = true
+			break
+		}
+	}
+	if !audienceOK {
+		return nil, errors.New("invalid audience")
+	}
+	if claims.Subject == "" {
+		return nil, errors.New("token has no subject")
+	}
+
+	// The nonce binds the token to the client's sign-in request
+	if nonce != "" || claims.Nonce != "" || p.RequireNonce {
+		if nonce == "" || subtle.ConstantTimeCompare([]byte(nonce), []byte(claims.Nonce)) != 1 {
+			return nil, errors.New("invalid nonce")
+		}
+	}
+	return claims, nil
+}
+
+// matchesIssuer compares an iss claim with the configured issuer
+func (p *OIDCProvider) matchesIssuer(issuer string) bool {
+	issuer = strings.TrimSuffix(issuer, "/")
+	if issuer == p.Issuer {
+		return true
+	}
+	// Google also issues tokens without the scheme
+	return p.Issuer == googleIssuer && issuer == "accounts.google.com"
+}
+
+// key returns the provider's public key for a key ID, refreshing the JWKS when it's stale or the key is unknown
+func (p *OIDCProvider) key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	key, ok := p.keys[kid]
+	stale := time.Since(p.lastRefresh) > oidcKeysMaxAge
+	if ok && !stale {
+		return key, nil
+	}
+	if !stale && time.Since(p.lastRefresh) < oidcMinRefreshInterval {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+
+	if err := p.refreshKeys(ctx); err != nil {
+		// Keep using the cached keys if the provider is briefly unreachable
+		if ok {
+			slog.Error("Failed to refresh JWKS", "provider", p.Name, "error", err)
+			return key, nil
+		}
+		return nil, err
+	}
+	key, ok = p.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+	return key, nil
+}
+
+// refreshKeys fetches the provider's JWKS, discovering its location first if needed
+func (p *OIDCProvider) refreshKeys(ctx context.Context) error {
+	p.lastRefresh = time.Now()
+
+	jwksURL := p.JWKSURL
+	if jwksURL == "" {
+		var discovery struct {
+			Issuer  string `json:"issuer"`
+			JWKSURI string `json:"jwks_uri"`
+		}
+		if err := getJSON(ctx, p.DiscoveryURL, &discovery); err != nil {
+			return fmt.Errorf("discovery failed: %w", err)
+		}
+		if !p.matchesIssuer(discovery.Issuer) {
+			return fmt.Errorf("discovery document is for issuer %q", discovery.Issuer)
+		}
+		if discovery.JWKSURI == "" {
+			return errors.New("discovery document has no jwks_uri")
+		}
+		jwksURL = discovery.JWKSURI
+	}
+
+	var document struct {
+		Keys []JWK `json:"keys"`
+	}
+	if err := getJSON(ctx, jwksURL, &document); err != nil {
+		return fmt.Errorf("failed to fetch JWKS: %w", err)
+	}
+
+	keys := map[string]crypto.PublicKey{}
+	for _, jwk := range document.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := ParseJWK(jwk)
+		if err != nil {
+			slog.Warn("Skipping JWK", "kid", jwk.Kid, "provider", p.Name, "error", err)
+			continue
+		}
+		keys[jwk.Kid] = key
+	}
+	p.keys = keys
+	return nil
+}
+
+func getJSON(ctx context.Context, url string, target any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	res, err := oidcHTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s returned %s", url, res.Status)
+	}
+	return json.NewDecoder(res.Body).Decode(target)
+}
@@ -0,0 +1,34 @@
+package helpers
+
+import (
+	"errors"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// ParseToken verifies an access token issued by this service and returns its claims.
+func ParseToken(tokenString string) (*AccessClaims, error) {
+	if Keys == nil {
+		return nil, errors.New("signing keys are not loaded")
+	}
+
+	claims := &AccessClaims{}
+	_, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		// Find the verification key by its ID
+		kid, _ := token.Header["kid"].(string)
+		for _, key := range Keys.VerificationKeys {
+			if key.ID == kid && key.Method.Alg() == token.Method.Alg() {
+				return key.PublicKey, nil
+			}
+		}
+		return nil, errors.New("unknown signing key")
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if !claims.VerifyIssuer(Keys.Issuer, true) || !claims.VerifyAudience(Keys.Audience, true) {
+		return nil, errors.New("token was not issued for this service")
+	}
+	return claims, nil
+}
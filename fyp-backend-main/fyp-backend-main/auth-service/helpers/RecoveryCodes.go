@@ -0,0 +1,47 @@
+package helpers
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"math/big"
+	"strings"
+)
+
+// RecoveryCodeCount is how many recovery codes are issued when MFA is enabled.
+const RecoveryCodeCount = 10
+
+// recoveryCodeAlphabet leaves out characters that are easy to confuse
+const recoveryCodeAlphabet = "abcdefghjkmnpqrstuvwxyz23456789"
+
+// GenerateRecoveryCodes returns new one-time recovery codes and the hashes to store.
+func GenerateRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, 0, RecoveryCodeCount)
+	hashes := make([]string, 0, RecoveryCodeCount)
+	for i := 0; i < RecoveryCodeCount; i++ {
+		var code strings.Builder
+		for j := 0; j < 10; j++ {
+			if j == 5 {
+				code.WriteByte('-')
+			}
+			n, err := rand.Int(rand.Reader, big.NewInt(int64(len(recoveryCodeAlphabet))))
+			if err != nil {
+				return nil, nil, err
+			}
+			code.WriteByte(recoveryCodeAlphabet[n.Int64()])
+		}
+		codes = append(codes, code.String())
+		hashes = append(hashes, HashToken(code.String()))
+	}
+	return codes, hashes, nil
+}
+
+// MatchRecoveryCode returns the stored hash matching the code, or an empty string.
+func MatchRecoveryCode(hashes []string, code string) string {
+	hash := HashToken(strings.ToLower(strings.TrimSpace(code)))
+	for _, stored := range hashes {
+		if subtle.ConstantTimeCompare([]byte(stored), []byte(hash)) == 1 {
+			return stored
+		}
+	}
+	return ""
+}
@@ -0,0 +1,33 @@
+package helpers
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"auth-service/utils"
+)
+
+// Redis keys consulted by the gateway's AuthMiddleware
+const (
+	// DenylistKeyPrefix + jti marks a single access token as revoked
+	DenylistKeyPrefix = "auth:denylist:"
+	// RevokedBeforeKeyPrefix + userID holds a unix time, tokens issued before it are revoked
+	RevokedBeforeKeyPrefix = "auth:revoked-before:"
+)
+
+// RevokeAccessToken adds an access token to the denylist until it expires on its own.
+func RevokeAccessToken(ctx context.Context, tokenID string, expiresAt time.Time) error {
+	ttl := time.Until(expiresAt)
+	if tokenID == "" || ttl <= 0 {
+		return nil
+	}
+	return utils.RDB.Set(ctx, DenylistKeyPrefix+tokenID, 1, ttl+time.Minute).Err()
+}
+
+// RevokeUserAccessTokens revokes every access token issued to a user up to now.
+func RevokeUserAccessTokens(ctx context.Context, userID string) error {
+	// Round up so tokens issued within the current second are covered as well
+	revokedBefore := time.Now().Unix() + 1
+	return utils.RDB.Set(ctx, RevokedBeforeKeyPrefix+userID, strconv.FormatInt(revokedBefore, 10), AccessTokenTTL+time.Minute).Err()
+}
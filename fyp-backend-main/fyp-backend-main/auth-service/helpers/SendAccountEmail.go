@@ -0,0 +1,40 @@
+package helpers
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"auth-service/config"
+	"auth-service/mailer"
+)
+
+// appURL is the frontend the links in emails point to
+func appURL() string {
+	return strings.TrimSuffix(config.Current.AppURL, "/")
+}
+
+// SendVerificationEmail mails the email verification link.
+func SendVerificationEmail(ctx context.Context, m mailer.Mailer, email string, token string) error {
+	link := fmt.Sprintf("%s/verify-email?token=%s", appURL(), url.QueryEscape(token))
+	return m.Send(ctx, mailer.Message{
+		To:      email,
+		Subject: "Verify your email address",
+		Body: "Welcome to Flomny!\n\n" +
+			"Please confirm your email address by opening the link below:\n\n" + link + "\n\n" +
+			fmt.Sprintf("The link expires in %d hours.\n", int(VerifyEmailTokenTTL.Hours())),
+	})
+}
+
+// SendPasswordResetEmail mails the password reset link.
+func SendPasswordResetEmail(ctx context.Context, m mailer.Mailer, email string, token string) error {
+	link := fmt.Sprintf("%s/reset-password?token=%s", appURL(), url.QueryEscape(token))
+	return m.Send(ctx, mailer.Message{
+		To:      email,
+		Subject: "Reset your password",
+		Body: "Someone asked to reset the password of your Flomny account.\n\n" +
+			"Open the link below to choose a new password:\n\n" + link + "\n\n" +
+			fmt.Sprintf("The link expires in %d minutes. If you didn't ask for this, you can ignore this email.\n", int(ResetPasswordTokenTTL.Minutes())),
+	})
+}
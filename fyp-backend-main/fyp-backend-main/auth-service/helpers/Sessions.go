@@ -0,0 +1,141 @@
+package helpers
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"auth-service/config"
+	"auth-service/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// RefreshTokenTTL is how long an unused refresh token stays valid.
+const RefreshTokenTTL = 30 * 24 * time.Hour
+
+// Tokens is an access token with the refresh token used to renew it.
+type Tokens struct {
+	AccessToken  string
+	RefreshToken string
+	ExpiresIn    int64 // Access token lifetime in seconds
+}
+
+// HashToken returns the hash an opaque token is stored under.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// IssueTokens creates an access token and a refresh token for the user. An empty familyID starts a new session,
+// authTime is when the user signed in to the session.
+func IssueTokens(ctx context.Context, db *mongo.Client, userID primitive.ObjectID, familyID string, authTime time.Time) (*Tokens, error) {
+	var err error
+	if familyID == "" {
+		if familyID, err = NewTokenID(); err != nil {
+			return nil, err
+		}
+	}
+
+	// Access token
+	accessTokenID, err := NewTokenID()
+	if err != nil {
+		return nil, err
+	}
+	accessToken, err := CreateToken(userID.Hex(), accessTokenID, authTime)
+	if err != nil {
+		return nil, err
+	}
+
+	// Opaque refresh token, only its hash is stored
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+	refreshToken := base64.RawURLEncoding.EncodeToString(secret)
+
+	now := time.Now()
+	_, err = db.Database(config.Current.MongoDatabase).Collection("refresh_tokens").InsertOne(ctx, models.RefreshToken{
+		ID:              primitive.NewObjectID(),
+		UserID:          userID,
+		FamilyID:        familyID,
+		TokenHash:       HashToken(refreshToken),
+		AccessTokenID:   accessTokenID,
+		AccessExpiresAt: now.Add(AccessTokenTTL),
+		AuthTime:        authTime,
+		CreatedAt:       now,
+		ExpiresAt:       now.Add(RefreshTokenTTL),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to store refresh token: %w", err)
+	}
+
+	return &Tokens{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int64(AccessTokenTTL.Seconds()),
+	}, nil
+}
+
+// RevokeTokenFamily revokes every refresh token of a session and denylists
+// the access tokens issued alongside them that haven't expired yet.
+func RevokeTokenFamily(ctx context.Context, db *mongo.Client, familyID string) error {
+	collection := db.Database(config.Current.MongoDatabase).Collection("refresh_tokens")
+
+	// Collect the access tokens that are still valid before revoking
+	cursor, err := collection.Find(ctx, bson.M{"family_id": familyID, "access_expires_at": bson.M{"$gt": time.Now()}})
+	if err != nil {
+		return err
+	}
+	var tokens []models.RefreshToken
+	if err := cursor.All(ctx, &tokens); err != nil {
+		return err
+	}
+
+	if _, err := collection.UpdateMany(ctx,
+		bson.M{"family_id": familyID, "revoked_at": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"revoked_at": time.Now()}},
+	); err != nil {
+		return err
+	}
+
+	for _, token := range tokens {
+		if err := RevokeAccessToken(ctx, token.AccessTokenID, token.AccessExpiresAt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RevokeUserSessions revokes every refresh token and access token of a user and returns the number of active sessions.
+func RevokeUserSessions(ctx context.Context, db *mongo.Client, userID primitive.ObjectID) (int64, error) {
+	collection := db.Database(config.Current.MongoDatabase).Collection("refresh_tokens")
+
+	// Count the sessions that were still active
+	families, err := collection.Distinct(ctx, "family_id", bson.M{
+		"user_id":    userID,
+		"rotated_at": bson.M{"$exists": false},
+		"revoked_at": bson.M{"$exists": false},
+		"expires_at": bson.M{"$gt": time.Now()},
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	if _, err := collection.UpdateMany(ctx,
+		bson.M{"user_id": userID, "revoked_at": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"revoked_at": time.Now()}},
+	); err != nil {
+		return 0, err
+	}
+	if err := RevokeUserAccessTokens(ctx, userID.Hex()); err != nil {
+		return 0, err
+	}
+	return int64(len(families)), nil
+}
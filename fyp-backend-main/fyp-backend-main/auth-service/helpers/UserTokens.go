@@ -0,0 +1,117 @@
+package helpers
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"time"
+
+	"auth-service/config"
+	"auth-service/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Lifetimes of single-use tokens
+const (
+	VerifyEmailTokenTTL   = 48 * time.Hour
+	ResetPasswordTokenTTL = time.Hour
+	MFAChallengeTTL       = 5 * time.Minute
+)
+
+// MaxTokenAttempts is how many wrong codes an MFA challenge allows before it's used up.
+const MaxTokenAttempts = 5
+
+// ErrInvalidUserToken is returned for unknown, used or expired tokens.
+var ErrInvalidUserToken = errors.New("invalid or expired token")
+
+// CreateUserToken creates a single-use token for the purpose and returns it.
+// Earlier unused tokens of the same purpose stop working.
+func CreateUserToken(ctx context.Context, db *mongo.Client, userID primitive.ObjectID, purpose string, email string, ttl time.Duration) (string, error) {
+	collection := db.Database(config.Current.MongoDatabase).Collection("user_tokens")
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	token := base64.RawURLEncoding.EncodeToString(secret)
+
+	// Only the latest token is valid
+	now := time.Now()
+	_, err := collection.UpdateMany(ctx,
+		bson.M{"user_id": userID, "purpose": purpose, "used_at": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"used_at": now}},
+	)
+	if err != nil {
+		return "", err
+	}
+
+	_, err = collection.InsertOne(ctx, models.UserToken{
+		ID:        primitive.NewObjectID(),
+		UserID:    userID,
+		Purpose:   purpose,
+		Email:     email,
+		TokenHash: HashToken(token),
+		CreatedAt: now,
+		ExpiresAt: now.Add(ttl),
+	})
+	if err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// ConsumeUserToken marks a token as used and returns it, or ErrInvalidUserToken.
+func ConsumeUserToken(ctx context.Context, db *mongo.Client, token string, purpose string) (*models.UserToken, error) {
+	filter := bson.M{
+		"token_hash": HashToken(token),
+		"purpose":    purpose,
+		"used_at":    bson.M{"$exists": false},
+		"expires_at": bson.M{"$gt": time.Now()},
+	}
+	update := bson.M{"$set": bson.M{"used_at": time.Now()}}
+
+	var userToken models.UserToken
+	err := db.Database(config.Current.MongoDatabase).Collection("user_tokens").
+		FindOneAndUpdate(ctx, filter, update, options.FindOneAndUpdate().SetReturnDocument(options.After)).
+		Decode(&userToken)
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrInvalidUserToken
+	} else if err != nil {
+		return nil, err
+	}
+	return &userToken, nil
+}
+
+// FindUserToken returns a valid token without using it up, or ErrInvalidUserToken.
+func FindUserToken(ctx context.Context, db *mongo.Client, token string, purpose string) (*models.UserToken, error) {
+	filter := bson.M{
+		"token_hash": HashToken(token),
+		"purpose":    purpose,
+		"used_at":    bson.M{"$exists": false},
+		"expires_at": bson.M{"$gt": time.Now()},
+	}
+
+	var userToken models.UserToken
+	err := db.Database(config.Current.MongoDatabase).Collection("user_tokens").FindOne(ctx, filter).Decode(&userToken)
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrInvalidUserToken
+	} else if err != nil {
+		return nil, err
+	}
+	return &userToken, nil
+}
+
+// RecordFailedTokenAttempt counts a wrong code and uses the token up after MaxTokenAttempts.
+func RecordFailedTokenAttempt(ctx context.Context, db *mongo.Client, token *models.UserToken) error {
+	update := bson.M{"$inc": bson.M{"attempts": 1}}
+	if token.Attempts+1 >= MaxTokenAttempts {
+		update["$set"] = bson.M{"used_at": time.Now()}
+	}
+	_, err := db.Database(config.Current.MongoDatabase).Collection("user_tokens").UpdateOne(ctx, bson.M{"_id": token.ID}, update)
+	return err
+}
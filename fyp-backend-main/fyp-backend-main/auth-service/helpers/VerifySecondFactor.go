@@ -0,0 +1,45 @@
+package helpers
+
+import (
+	"context"
+
+	"auth-service/config"
+	"auth-service/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// VerifySecondFactor checks a TOTP code or a recovery code for a user with MFA enabled.
+// Accepted codes are recorded so they can't be used again.
+func VerifySecondFactor(ctx context.Context, db *mongo.Client, user *models.User, code string) (bool, error) {
+	if !user.MFAEnabled() || code == "" {
+		return false, nil
+	}
+	users := db.Database(config.Current.MongoDatabase).Collection("users")
+
+	// TOTP code, the step is only accepted if no later code was used
+	if step, ok := ValidateTOTP(user.MFA.Secret, code, user.MFA.LastStep); ok {
+		result, err := users.UpdateOne(ctx,
+			bson.M{"_id": user.ID, "mfa.last_step": bson.M{"$lt": step}},
+			bson.M{"$set": bson.M{"mfa.last_step": step}},
+		)
+		if err != nil {
+			return false, err
+		}
+		return result.ModifiedCount == 1, nil
+	}
+
+	// Recovery code, removed once used
+	if hash := MatchRecoveryCode(user.MFA.RecoveryCodes, code); hash != "" {
+		result, err := users.UpdateOne(ctx,
+			bson.M{"_id": user.ID, "mfa.recovery_codes": hash},
+			bson.M{"$pull": bson.M{"mfa.recovery_codes": hash}},
+		)
+		if err != nil {
+			return false, err
+		}
+		return result.ModifiedCount == 1, nil
+	}
+	return false, nil
+}
@@ -0,0 +1,35 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+)
+
+// Message is a plain text email.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer sends transactional emails such as verification and password reset links.
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// NewMailer picks the mailer by driver:
+//
+//	smtp    sends through the SMTP server
+//	memory  keeps messages in memory and logs them without delivering, for local development
+//
+// logBodies makes the memory mailer log the links in messages too.
+func NewMailer(driver string, smtp SMTPConfig, logBodies bool) (Mailer, error) {
+	switch driver {
+	case "memory":
+		return NewMemoryMailer(logBodies), nil
+	case "smtp":
+		return NewSMTPMailer(smtp)
+	default:
+		return nil, fmt.Errorf("unknown MAIL_DRIVER %q", driver)
+	}
+}
@@ -0,0 +1,50 @@
+package mailer
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"sync"
+)
+
+// memoryMailerLimit is how many of the last messages a MemoryMailer keeps
+const memoryMailerLimit = 100
+
+// MemoryMailer keeps the last sent messages in memory instead of delivering them.
+// Messages are also logged, their bodies only when logBodies is set since they carry
+// verification and password reset links, so links can be followed during local testing.
+type MemoryMailer struct {
+	mu        sync.Mutex
+	messages  []Message
+	logBodies bool
+}
+
+// NewMemoryMailer returns an empty in-memory mailer, logging the body of messages if logBodies is set.
+func NewMemoryMailer(logBodies bool) *MemoryMailer {
+	return &MemoryMailer{logBodies: logBodies}
+}
+
+// Send records the message, forgetting the oldest one past memoryMailerLimit.
+func (m *MemoryMailer) Send(ctx context.Context, msg Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if len(m.messages) == memoryMailerLimit {
+		m.messages = slices.Delete(m.messages, 0, 1)
+	}
+	m.messages = append(m.messages, msg)
+	if m.logBodies {
+		slog.Info("Mail", "to", msg.To, "subject", msg.Subject, "body", msg.Body)
+	} else {
+		slog.Info("Mail", "to", msg.To, "subject", msg.Subject)
+	}
+	return nil
+}
+
+// Messages returns a copy of the messages kept, oldest first.
+func (m *MemoryMailer) Messages() []Message {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return append([]Message(nil), m.messages...)
+}
@@ -0,0 +1,26 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	// Registrations counts created accounts by sign in method, "password" or the social provider
+	Registrations = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "auth_registrations_total",
+		Help: "Accounts created, by sign in method.",
+	}, []string{"method"})
+
+	// Logins counts started sessions by how the user signed in: "password", "mfa" or the social provider
+	Logins = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "auth_logins_total",
+		Help: "Sessions started, by sign in method.",
+	}, []string{"method"})
+
+	// LoginFailures counts rejected logins by the reason stored in the audit record
+	LoginFailures = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "auth_login_failures_total",
+		Help: "Failed logins, by reason.",
+	}, []string{"reason"})
+)
@@ -0,0 +1,27 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+var rpcDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+	Name:    "grpc_server_handling_seconds",
+	Help:    "Time taken to handle gRPC calls, by method and status code.",
+	Buckets: prometheus.DefBuckets,
+}, []string{"method", "code"})
+
+// UnaryServerInterceptor records the latency and status code of every call.
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		res, err := handler(ctx, req)
+		rpcDuration.WithLabelValues(info.FullMethod, status.Code(err).String()).Observe(time.Since(start).Seconds())
+		return res, err
+	}
+}
@@ -0,0 +1,30 @@
+package metrics
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"shared/logging"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Serve exposes /metrics on the admin address in the background. It listens on
+// its own port so metrics aren't reachable through the public one.
+func Serve(addr string) *http.Server {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logging.Fatal("Failed to serve metrics", "error", err)
+		}
+	}()
+	return srv
+}
@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Reasons recorded for failed logins
+const (
+	LoginFailureUnknownUser     = "unknown_user"
+	LoginFailureInvalidPassword = "invalid_password"
+	LoginFailureInvalidMFACode  = "invalid_mfa_code"
+	LoginFailureThrottled       = "throttled"
+	LoginFailureDeletedUser     = "deleted_user"
+)
+
+// LoginAudit is an audit record of a failed login attempt.
+type LoginAudit struct {
+	ID        primitive.ObjectID  `bson:"_id,omitempty" json:"id,omitempty"`
+	Email     string              `bson:"email" json:"email"`
+	UserID    *primitive.ObjectID `bson:"user_id,omitempty" json:"userId,omitempty"`
+	ClientIP  string              `bson:"client_ip,omitempty" json:"clientIp,omitempty"`
+	Reason    string              `bson:"reason" json:"reason"`
+	CreatedAt time.Time           `bson:"created_at" json:"createdAt"`
+}
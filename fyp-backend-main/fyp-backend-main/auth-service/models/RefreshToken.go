@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// RefreshToken is a single-use refresh token. Only the SHA-256 hash of the token is stored.
+// Every rotation creates a new token in the same family, so a reused token can revoke the whole chain.
+type RefreshToken struct {
+	ID              primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+	UserID          primitive.ObjectID `bson:"user_id" json:"userId"`
+	FamilyID        string             `bson:"family_id" json:"familyId"`
+	TokenHash       string             `bson:"token_hash" json:"-"`
+	AccessTokenID   string             `bson:"access_token_id" json:"accessTokenId"`
+	AccessExpiresAt time.Time          `bson:"access_expires_at" json:"accessExpiresAt"`
+	AuthTime        time.Time          `bson:"auth_time" json:"authTime"`
+	CreatedAt       time.Time          `bson:"created_at" json:"createdAt"`
+	ExpiresAt       time.Time          `bson:"expires_at" json:"expiresAt"`
+	RotatedAt       *time.Time         `bson:"rotated_at,omitempty" json:"rotatedAt,omitempty"`
+	RevokedAt       *time.Time         `bson:"revoked_at,omitempty" json:"revokedAt,omitempty"`
+}
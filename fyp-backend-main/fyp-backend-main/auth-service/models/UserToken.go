@@ -0,0 +1,27 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Purposes of single-use user tokens
+const (
+	TokenPurposeVerifyEmail   = "verify_email"
+	TokenPurposeResetPassword = "reset_password"
+	TokenPurposeMFAChallenge  = "mfa_challenge"
+)
+
+// UserToken is a single-use, expiring token sent to the user by email. Only its SHA-256 hash is stored.
+type UserToken struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+	UserID    primitive.ObjectID `bson:"user_id" json:"userId"`
+	Purpose   string             `bson:"purpose" json:"purpose"`
+	Email     string             `bson:"email" json:"email"` // Address the token was sent to
+	TokenHash string             `bson:"token_hash" json:"-"`
+	CreatedAt time.Time          `bson:"created_at" json:"createdAt"`
+	ExpiresAt time.Time          `bson:"expires_at" json:"expiresAt"`
+	UsedAt    *time.Time         `bson:"used_at,omitempty" json:"usedAt,omitempty"`
+	Attempts  int                `bson:"attempts,omitempty" json:"attempts,omitempty"` // Failed uses, for tokens that allow retries
+}
@@ -7,14 +7,39 @@ import (
 )
 
 type User struct {
-	ID           primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
-	FirstName    string             `bson:"first_name" json:"firstname"`
-	LastName     string             `bson:"last_name" json:"lastname"`
-	Email        string             `bson:"email" json:"email"`
-	Password     string             `bson:"password" json:"password"`
-	SignInMethod string             `bson:"signin_method" json:"signin_method"`
-	Credits      int64              `bson:"credits" json:"credits"`
-	CreatedAt    time.Time          `bson:"created_at" json:"createdAt"`
-	UpdatedAt    time.Time          `bson:"updated_at" json:"updatedAt"`
-	DeletedAt    *time.Time         `bson:"deleted_at,omitempty" json:"deletedAt,omitempty"`
+	ID            primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+	FirstName     string             `bson:"first_name" json:"firstname"`
+	LastName      string             `bson:"last_name" json:"lastname"`
+	Email         string             `bson:"email" json:"email"`
+	EmailVerified bool               `bson:"email_verified" json:"emailVerified"`
+	Password      string             `bson:"password" json:"password"`
+	SignInMethod  string             `bson:"signin_method" json:"signin_method"`
+	Identities    []Identity         `bson:"identities,omitempty" json:"identities,omitempty"`
+	MFA           *MFA               `bson:"mfa,omitempty" json:"mfa,omitempty"`
+	Credits       int64              `bson:"credits" json:"credits"`
+	CreatedAt     time.Time          `bson:"created_at" json:"createdAt"`
+	UpdatedAt     time.Time          `bson:"updated_at" json:"updatedAt"`
+	DeletedAt     *time.Time         `bson:"deleted_at,omitempty" json:"deletedAt,omitempty"`
+}
+
+// Identity links a user to an account at an external identity provider.
+type Identity struct {
+	Provider string    `bson:"provider" json:"provider"`
+	Subject  string    `bson:"subject" json:"subject"`
+	LinkedAt time.Time `bson:"linked_at" json:"linkedAt"`
+}
+
+// MFA holds a user's TOTP enrollment.
+type MFA struct {
+	Enabled       bool       `bson:"enabled" json:"enabled"`
+	Secret        string     `bson:"secret,omitempty" json:"-"`         // Base32 TOTP secret once enabled
+	PendingSecret string     `bson:"pending_secret,omitempty" json:"-"` // Secret awaiting the first code
+	RecoveryCodes []string   `bson:"recovery_codes,omitempty" json:"-"` // SHA-256 hashes of unused recovery codes
+	LastStep      int64      `bson:"last_step" json:"-"`                // Last accepted time step, codes can't be replayed
+	EnabledAt     *time.Time `bson:"enabled_at,omitempty" json:"enabledAt,omitempty"`
+}
+
+// MFAEnabled reports whether the user signs in with a second factor.
+func (u *User) MFAEnabled() bool {
+	return u.MFA != nil && u.MFA.Enabled
 }
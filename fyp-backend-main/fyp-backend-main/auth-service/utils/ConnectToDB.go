@@ -2,40 +2,39 @@ package utils
 
 import (
 	"context"
-	"log"
-	"os"
-	"time"
+	"fmt"
+	"log/slog"
+
+	"auth-service/config"
+	"auth-service/metrics"
+	"shared/platform"
 
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.opentelemetry.io/contrib/instrumentation/go.mongodb.org/mongo-driver/mongo/otelmongo"
 )
 
-// ConnectToDB initializes the MongoDB client and connects to the zksc database
-func ConnectToDB() (*mongo.Client, error) {
-	//env variable
-	uri := os.Getenv("MONGO_URL")
-	if uri == "" {
-		log.Fatal("MONGO_URI environment variable is not set")
-	}
-	clientOptions := options.Client().ApplyURI(uri)
-
-	// Connect to MongoDB
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
+// ConnectToDB initializes the MongoDB client, retrying the first ping for up to
+// cfg.StartupMaxWait so a slow database doesn't crash the service
+func ConnectToDB(ctx context.Context, cfg *config.Config) (*mongo.Client, error) {
+	// Every command is recorded as a span of the call that issued it and in the latency histogram
+	clientOptions := options.Client().ApplyURI(cfg.MongoURL).SetMonitor(metrics.MongoMonitor(otelmongo.NewMonitor()))
 
+	// Connect to MongoDB, this only fails on invalid options
 	client, err := mongo.Connect(ctx, clientOptions)
 	if err != nil {
-		log.Fatalf("Failed to connect to MongoDB: %v", err)
-		return nil, err
+		return nil, fmt.Errorf("failed to connect to MongoDB: %w", err)
 	}
 
 	// Check the connection
-	err = client.Ping(ctx, nil)
+	err = platform.Retry(ctx, "MongoDB ping", cfg.StartupMaxWait, func(ctx context.Context) error {
+		return client.Ping(ctx, nil)
+	})
 	if err != nil {
-		log.Fatalf("Failed to ping MongoDB: %v", err)
+		client.Disconnect(context.Background())
 		return nil, err
 	}
 
-	log.Println("Connected to MongoDB!")
+	slog.Info("Connected to MongoDB!")
 	return client, nil
 }
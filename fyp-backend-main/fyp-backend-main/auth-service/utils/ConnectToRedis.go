@@ -0,0 +1,46 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"auth-service/config"
+	"shared/platform"
+
+	"github.com/redis/go-redis/extra/redisotel/v9"
+	"github.com/redis/go-redis/v9"
+)
+
+var RDB *redis.Client
+
+// ConnectToCache initializes the Redis client, retrying the first ping for up to
+// cfg.StartupMaxWait so a slow Redis doesn't crash the service
+func ConnectToCache(ctx context.Context, cfg *config.Config) error {
+	// Create a new Redis client
+	client := redis.NewClient(&redis.Options{
+		Addr:     cfg.RedisAddr,
+		Password: cfg.RedisPassword,
+		DB:       cfg.RedisDB,
+	})
+
+	// Record commands as spans
+	if err := redisotel.InstrumentTracing(client); err != nil {
+		return fmt.Errorf("failed to instrument Redis client: %w", err)
+	}
+
+	// Ping Redis to check connection
+	err := platform.Retry(ctx, "Redis ping", cfg.StartupMaxWait, func(ctx context.Context) error {
+		return client.Ping(ctx).Err()
+	})
+	if err != nil {
+		client.Close()
+		return fmt.Errorf("failed to connect to Redis: %w", err)
+	}
+
+	// Assign the client to global RDB
+	RDB = client
+
+	slog.Info("Successfully connected to Redis!")
+	return nil
+}
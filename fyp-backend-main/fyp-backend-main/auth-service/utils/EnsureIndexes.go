@@ -0,0 +1,117 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"auth-service/config"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// EnsureIndexes creates the indexes the auth service relies on
+func EnsureIndexes(client *mongo.Client) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	// Emails are stored as helpers.NormalizeEmail returns them and belong to one account,
+	// deleted ones included. Emails stored before then are normalized first.
+	users := client.Database(config.Current.MongoDatabase).Collection("users")
+	_, err := users.UpdateMany(ctx,
+		bson.M{"email": bson.M{"$regex": `[A-Z]|^\s|\s$`}},
+		mongo.Pipeline{{{Key: "$set", Value: bson.M{"email": bson.M{"$toLower": bson.M{"$trim": bson.M{"input": "$email"}}}}}}},
+	)
+	if err != nil {
+		return err
+	}
+	_, err = users.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "email", Value: 1}},
+		Options: options.Index().SetName("user_email").SetUnique(true).SetPartialFilterExpression(bson.M{"email": bson.M{"$type": "string"}}),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create the unique index on users.email, merge the accounts sharing an email first: %w", err)
+	}
+
+	// Refresh tokens are looked up by hash, revoked per user or family and expire on their own
+	_, err = client.Database(config.Current.MongoDatabase).Collection("refresh_tokens").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "token_hash", Value: 1}},
+			Options: options.Index().SetName("refresh_token_hash").SetUnique(true),
+		},
+		{
+			Keys:    bson.D{{Key: "user_id", Value: 1}},
+			Options: options.Index().SetName("refresh_token_user"),
+		},
+		{
+			Keys:    bson.D{{Key: "family_id", Value: 1}},
+			Options: options.Index().SetName("refresh_token_family"),
+		},
+		{
+			Keys:    bson.D{{Key: "access_token_id", Value: 1}},
+			Options: options.Index().SetName("refresh_token_access_token"),
+		},
+		{
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetName("refresh_token_expiry").SetExpireAfterSeconds(0),
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	// Verification and reset tokens, removed once expired
+	_, err = client.Database(config.Current.MongoDatabase).Collection("user_tokens").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "token_hash", Value: 1}},
+			Options: options.Index().SetName("user_token_hash").SetUnique(true),
+		},
+		{
+			Keys:    bson.D{{Key: "user_id", Value: 1}, {Key: "purpose", Value: 1}},
+			Options: options.Index().SetName("user_token_user_purpose"),
+		},
+		{
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetName("user_token_expiry").SetExpireAfterSeconds(0),
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	// Failed login audit records are kept for 90 days
+	_, err = client.Database(config.Current.MongoDatabase).Collection("login_audit").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "email", Value: 1}, {Key: "created_at", Value: -1}},
+			Options: options.Index().SetName("login_audit_email"),
+		},
+		{
+			Keys:    bson.D{{Key: "created_at", Value: 1}},
+			Options: options.Index().SetName("login_audit_expiry").SetExpireAfterSeconds(90 * 24 * 60 * 60),
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	// API keys are looked up by hash and listed per user
+	_, err = client.Database(config.Current.MongoDatabase).Collection("api_keys").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "key_hash", Value: 1}},
+			Options: options.Index().SetName("api_key_hash").SetUnique(true),
+		},
+		{
+			Keys:    bson.D{{Key: "user_id", Value: 1}, {Key: "created_at", Value: -1}},
+			Options: options.Index().SetName("api_key_user"),
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	slog.Info("MongoDB indexes are up to date")
+	return nil
+}
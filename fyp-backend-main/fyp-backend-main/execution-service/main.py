@@ -23,6 +23,12 @@ MONGO_URL = os.getenv("MONGO_URL")
 AWS_ACCESS_KEY_ID = os.getenv('AWS_ACCESS_KEY_ID')
 AWS_SECRET_ACCESS_KEY = os.getenv('AWS_SECRET_ACCESS_KEY')
 AWS_REGION = os.getenv('AWS_REGION', 'eu-north-1')
+# Namespaces in file URLs and their buckets, must match the gateway's STORAGE_NAMESPACES
+STORAGE_NAMESPACES = dict(
+    entry.strip().split('=', 1)
+    for entry in os.getenv('STORAGE_NAMESPACES', 'integrations=flomny-integrations,workflows=flomny-workflows,assets=flomny-assets').split(',')
+    if '=' in entry
+)
 
 # MongoDB client (reuse across requests)
 mongo_client = MongoClient(MONGO_URL) if MONGO_URL else None
@@ -51,10 +57,10 @@ def download_file_from_s3(s3_client, bucket_name, file_name, local_path=None):
         return None
 
 def parse_s3_bucket_key_from_url(url):
-    # Assumes URL format: http(s)://.../docs/<bucket>/<key>
+    # Assumes URL format: http(s)://.../docs/<namespace>/<key>, older URLs name the bucket
     try:
         parts = url.split('/docs/', 1)[-1].split('/', 1)
-        bucket = parts[0]
+        bucket = STORAGE_NAMESPACES.get(parts[0], parts[0])
         key = parts[1]
         return bucket, key
     except Exception:
@@ -3,66 +3,75 @@ package controllers
 import (
 	"context"
 	"encoding/json"
-	"errors"
+	"strconv"
 	"strings"
 	"time"
 
+	"integration-service/config"
+	"integration-service/metrics"
 	"integration-service/models"
 	integration_service "integration-service/proto/generated/github.com/multiagentai/backend/integration-service"
 	"integration-service/utils"
+	"shared/identity"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
-	"google.golang.org/grpc/metadata"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
-func (s *IntegrationServer) CreateIntegration(ctx context.Context, req *integration_service.CreateIntegrationRequest) (*integration_service.CreateIntegrationResponse, error) {
-	// Extract userID from gRPC metadata
-	md, ok := metadata.FromIncomingContext(ctx)
-	if !ok {
-		return nil, errors.New("metadata not provided in gRPC context")
-	}
+// integrationCreatedEvent is the payload published on integration_created: the integration's
+// fields plus the W3C trace context of the request that created it
+type integrationCreatedEvent struct {
+	*integration_service.Integration
+	Traceparent string `json:"traceparent,omitempty"`
+	Tracestate  string `json:"tracestate,omitempty"`
+}
 
-	userIDs := md.Get("userID")
-	if len(userIDs) == 0 {
-		return nil, errors.New("userID not found in metadata")
+func (s *IntegrationServer) CreateIntegration(ctx context.Context, req *integration_service.CreateIntegrationRequest) (*integration_service.CreateIntegrationResponse, error) {
+	// Extract the userID of the verified caller
+	userID, err := identity.UserID(ctx)
+	if err != nil {
+		return nil, err
 	}
-	userID := userIDs[0]
 
 	// Validate userID
-	userCollection := s.DocDB.Database("fyp-db").Collection("users")
+	userCollection := s.DocDB.Database(config.Current.MongoDatabase).Collection("users")
 	userObjectID, err := primitive.ObjectIDFromHex(userID)
 	if err != nil {
-		return nil, errors.New("invalid userID format")
+		return nil, status.Error(codes.InvalidArgument, "invalid userID format")
 	}
 
 	var user struct{}
 	err = userCollection.FindOne(ctx, bson.M{"_id": userObjectID}).Decode(&user)
 	if err == mongo.ErrNoDocuments {
-		return nil, errors.New("user not found")
+		return nil, status.Error(codes.NotFound, "user not found")
 	} else if err != nil {
-		return nil, err
+		return nil, status.Errorf(codes.Internal, "failed to get user: %v", err)
 	}
 
 	// Normalize unique_name
 	normalizedUniqueName := strings.TrimSpace(strings.ToLower(req.GetUniqueName()))
 
 	// Check if unique_name already exists
-	integrationCollection := s.DocDB.Database("fyp-db").Collection("integrations")
+	integrationCollection := s.DocDB.Database(config.Current.MongoDatabase).Collection("integrations")
 	var existingIntegration models.Integration
 	err = integrationCollection.FindOne(ctx, bson.M{"unique_name": normalizedUniqueName}).Decode(&existingIntegration)
 	if err == nil {
-		return nil, errors.New("unique_name already exists")
+		return nil, status.Error(codes.AlreadyExists, "unique_name already exists")
 	} else if err != mongo.ErrNoDocuments {
-		return nil, err
+		return nil, status.Errorf(codes.Internal, "failed to check unique_name: %v", err)
 	}
 
 	// Generate integration data
 	integrationID := primitive.NewObjectID()
 	additionalInfo := models.AdditionalInfo{
 		DocumentationURL: req.GetDocumentationUrl(),
+		DocumentationKey: models.AttachmentKey(req.GetDocumentationUrl()),
 		PublicBaseURL:    req.GetBaseUrl(),
 	}
 
@@ -91,8 +100,9 @@ func (s *IntegrationServer) CreateIntegration(ctx context.Context, req *integrat
 	// Insert integration into DB
 	_, err = integrationCollection.InsertOne(ctx, integration)
 	if err != nil {
-		return nil, err
+		return nil, status.Errorf(codes.Internal, "failed to create integration: %v", err)
 	}
+	metrics.IntegrationsCreated.WithLabelValues(strconv.FormatBool(integration.AdditionalInfo.IsFileBased)).Inc()
 
 	// Prepare gRPC Integration object
 	createdAt := timestamppb.New(integration.CreatedAt)
@@ -124,19 +134,25 @@ func (s *IntegrationServer) CreateIntegration(ctx context.Context, req *integrat
 	//  Publish event if file is based
 	if integrationObject.AdditionalInfo.IsFileBased {
 		if utils.RDB != nil {
-			// Marshal the integrationObject to JSON
-			payload, err := json.Marshal(integrationObject)
+			// Marshal the integrationObject to JSON, with the trace context so consumers can continue the trace
+			event := integrationCreatedEvent{Integration: integrationObject}
+			carrier := propagation.MapCarrier{}
+			otel.GetTextMapPropagator().Inject(ctx, carrier)
+			event.Traceparent = carrier.Get("traceparent")
+			event.Tracestate = carrier.Get("tracestate")
+			payload, err := json.Marshal(event)
 			if err != nil {
-				return nil, err
+				return nil, status.Errorf(codes.Internal, "failed to encode integration event: %v", err)
 			}
 
 			// Publish to Redis channel
-			err = utils.RDB.Publish(context.Background(), "integration_created", payload).Err()
+			err = utils.RDB.Publish(ctx, "integration_created", payload).Err()
 			if err != nil {
-				return nil, err
+				metrics.PublishFailures.WithLabelValues("integration_created").Inc()
+				return nil, status.Errorf(codes.Unavailable, "failed to publish integration event: %v", err)
 			}
 		} else {
-			return nil, errors.New("redis client not initialized")
+			return nil, status.Error(codes.Unavailable, "redis client not initialized")
 		}
 	}
 
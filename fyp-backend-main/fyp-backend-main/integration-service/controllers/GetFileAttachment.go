@@ -0,0 +1,78 @@
+package controllers
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"integration-service/config"
+	integration_service "integration-service/proto/generated/github.com/multiagentai/backend/integration-service"
+	"shared/identity"
+)
+
+// GetFileAttachment tells the gateway whether an uploaded file documents a public integration
+// or one of the caller's. The gateway relies on it for files uploaded before its registry,
+// and sets owner for the others so only their uploader's integrations publish them.
+func (i *IntegrationServer) GetFileAttachment(ctx context.Context, req *integration_service.GetFileAttachmentRequest) (*integration_service.GetFileAttachmentResponse, error) {
+	// Extract the userID of the verified caller
+	userID, err := identity.UserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Validate the file name, uploads are stored under a single path segment
+	fileName := req.GetFileName()
+	if fileName == "" || strings.Contains(fileName, "/") {
+		return nil, status.Error(codes.InvalidArgument, "invalid file name")
+	}
+
+	// Match the indexed key of the documentation URL, file names start with a UUID so they're unique
+	cursor, err := i.DocDB.Database(config.Current.MongoDatabase).Collection("integrations").Find(ctx, bson.M{
+		"additional_info.documentation_key": fileName,
+		"deleted_at": bson.M{
+			"$exists": false,
+		},
+	}, options.Find().SetProjection(bson.M{"public": 1, "created_by": 1, "created_at": 1}))
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to fetch integrations: %v", err)
+	}
+	defer cursor.Close(ctx)
+
+	res := &integration_service.GetFileAttachmentResponse{}
+	for cursor.Next(ctx) {
+		var integration struct {
+			Public    bool      `bson:"public"`
+			CreatedBy string    `bson:"created_by"`
+			CreatedAt time.Time `bson:"created_at"`
+		}
+		if err := cursor.Decode(&integration); err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to decode integration: %v", err)
+		}
+		// Attaching a file someone else uploaded doesn't publish it
+		if integration.Public && (req.GetOwner() == "" || sameUser(integration.CreatedBy, req.GetOwner())) {
+			res.Public = true
+		}
+		if sameUser(integration.CreatedBy, userID) {
+			res.Owned = true
+			if res.OwnedSince == nil || integration.CreatedAt.Before(res.OwnedSince.AsTime()) {
+				res.OwnedSince = timestamppb.New(integration.CreatedAt)
+			}
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to fetch integrations: %v", err)
+	}
+
+	return res, nil
+}
+
+// sameUser compares user IDs the way integrations store them
+func sameUser(a, b string) bool {
+	return strings.EqualFold(strings.TrimSpace(a), strings.TrimSpace(b))
+}
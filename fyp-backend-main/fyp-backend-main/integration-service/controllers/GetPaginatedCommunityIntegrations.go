@@ -2,6 +2,7 @@ package controllers
 
 import (
 	"context"
+	"integration-service/config"
 	"integration-service/models"
 	integration_service "integration-service/proto/generated/github.com/multiagentai/backend/integration-service"
 
@@ -23,7 +24,7 @@ func (i *IntegrationServer) GetPaginatedCommunityIntegrations(ctx context.Contex
 		SetSort(bson.M{"created_at": -1})
 
 	// Find public integrations that haven't been deleted
-	cursor, err := i.DocDB.Database("fyp-db").Collection("integrations").Find(ctx, bson.M{
+	cursor, err := i.DocDB.Database(config.Current.MongoDatabase).Collection("integrations").Find(ctx, bson.M{
 		"public": true,
 		"deleted_at": bson.M{
 			"$exists": false,
@@ -69,7 +70,7 @@ func (i *IntegrationServer) GetPaginatedCommunityIntegrations(ctx context.Contex
 	}
 
 	// Count total number of public integrations that haven't been deleted
-	total, err := i.DocDB.Database("fyp-db").Collection("integrations").CountDocuments(ctx, bson.M{
+	total, err := i.DocDB.Database(config.Current.MongoDatabase).Collection("integrations").CountDocuments(ctx, bson.M{
 		"public": true,
 		"deleted_at": bson.M{
 			"$exists": false,
@@ -2,32 +2,28 @@ package controllers
 
 import (
 	"context"
-	"errors"
 
+	"integration-service/config"
 	"integration-service/models"
 	integration_service "integration-service/proto/generated/github.com/multiagentai/backend/integration-service"
+	"shared/identity"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
-	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 func (s *IntegrationServer) GetUserIntegrations(ctx context.Context, req *integration_service.GetUserIntegrationsRequest) (*integration_service.GetUserIntegrationsResponse, error) {
-	// Extract userID from gRPC metadata
-	md, ok := metadata.FromIncomingContext(ctx)
-	if !ok {
-		return nil, errors.New("metadata not provided in gRPC context")
-	}
-
-	userIDs := md.Get("userID")
-	if len(userIDs) == 0 {
-		return nil, errors.New("userID not found in metadata")
+	// Extract the userID of the verified caller
+	userID, err := identity.UserID(ctx)
+	if err != nil {
+		return nil, err
 	}
-	userID := userIDs[0]
 
 	// Connect to the integrations collection
-	integrationCollection := s.DocDB.Database("fyp-db").Collection("integrations")
+	integrationCollection := s.DocDB.Database(config.Current.MongoDatabase).Collection("integrations")
 
 	// Query for integrations that are either created by the user OR are public
 	filter := bson.M{
@@ -43,7 +39,7 @@ func (s *IntegrationServer) GetUserIntegrations(ctx context.Context, req *integr
 			Integrations: []*integration_service.Integration{},
 		}, nil
 	} else if err != nil {
-		return nil, err
+		return nil, status.Errorf(codes.Internal, "failed to fetch integrations: %v", err)
 	}
 	defer cursor.Close(ctx)
 
@@ -52,7 +48,7 @@ func (s *IntegrationServer) GetUserIntegrations(ctx context.Context, req *integr
 	for cursor.Next(ctx) {
 		var integration models.Integration
 		if err := cursor.Decode(&integration); err != nil {
-			return nil, err
+			return nil, status.Errorf(codes.Internal, "failed to decode integration: %v", err)
 		}
 
 		// Convert timestamps
@@ -2,62 +2,158 @@ package controllers
 
 import (
 	"context"
-	"errors"
+	"encoding/base64"
+	"encoding/json"
 	"strings"
 
+	"integration-service/config"
 	"integration-service/models"
 	integration_service "integration-service/proto/generated/github.com/multiagentai/backend/integration-service"
+	"shared/identity"
 
 	"go.mongodb.org/mongo-driver/bson"
-	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 100
+)
+
+// searchCursor marks the last result of a page: its text score and id
+type searchCursor struct {
+	Score float64 `json:"s"`
+	ID    string  `json:"id"`
+}
+
+// searchResult is an integration decorated with its text score
+type searchResult struct {
+	models.Integration `bson:",inline"`
+	Score              float64 `bson:"score"`
+}
+
 func (s *IntegrationServer) SearchIntegration(ctx context.Context, req *integration_service.SearchIntegrationRequest) (*integration_service.SearchIntegrationResponse, error) {
+	// Extract the userID of the verified caller
+	userID, err := identity.UserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	// Validate the search query
 	query := strings.TrimSpace(req.GetQuery())
 	if query == "" {
-		return nil, errors.New("search query cannot be empty")
+		return nil, status.Error(codes.InvalidArgument, "search query cannot be empty")
 	}
 
-	// Connect to the integrations collection
-	integrationCollection := s.DocDB.Database("fyp-db").Collection("integrations")
+	// Validate paging
+	if req.GetOffset() < 0 {
+		return nil, status.Error(codes.InvalidArgument, "offset cannot be negative")
+	}
+	limit := req.GetLimit()
+	if limit <= 0 {
+		limit = defaultSearchLimit
+	}
+	if limit > maxSearchLimit {
+		limit = maxSearchLimit
+	}
 
-	// Case-insensitive filter for the search query
+	// The query goes through the text index, so user input is never used as a pattern.
+	// Only the caller's own, public and official integrations that haven't been deleted are visible.
 	filter := bson.M{
+		"$text": bson.M{"$search": query},
 		"$or": []bson.M{
-			{"display_name": bson.M{"$regex": query, "$options": "i"}},
-			{"unique_name": bson.M{"$regex": query, "$options": "i"}},
-			{"description": bson.M{"$regex": query, "$options": "i"}},
+			{"created_by": userID},
+			{"created_by": "official"},
+			{"public": true},
 		},
+		"deleted_at": bson.M{"$exists": false},
+	}
+
+	// Optional filters
+	if req.IsFileBased != nil {
+		filter["additional_info.is_file_based"] = req.GetIsFileBased()
+	}
+	if fileStatus := req.GetFileStatus(); fileStatus != "" {
+		if !models.IsValidFileStatus(fileStatus) {
+			return nil, status.Errorf(codes.InvalidArgument, "unknown file status %q", fileStatus)
+		}
+		filter["additional_info.file_status"] = fileStatus
+	}
+
+	// Rank by text score, ties broken by id so the order is stable
+	pipeline := []bson.M{
+		{"$match": filter},
+		{"$addFields": bson.M{"score": bson.M{"$meta": "textScore"}}},
 	}
 
+	// A cursor continues after the last result of the previous page
+	if req.GetCursor() != "" {
+		cursor, err := decodeSearchCursor(req.GetCursor())
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, "invalid cursor")
+		}
+		lastID, err := primitive.ObjectIDFromHex(cursor.ID)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, "invalid cursor")
+		}
+		pipeline = append(pipeline, bson.M{"$match": bson.M{
+			"$or": []bson.M{
+				{"score": bson.M{"$lt": cursor.Score}},
+				{"score": cursor.Score, "_id": bson.M{"$lt": lastID}},
+			},
+		}})
+	}
+
+	pipeline = append(pipeline, bson.M{"$sort": bson.D{
+		{Key: "score", Value: -1},
+		{Key: "_id", Value: -1},
+	}})
+	if req.GetCursor() == "" && req.GetOffset() > 0 {
+		pipeline = append(pipeline, bson.M{"$skip": req.GetOffset()})
+	}
+	// Fetch one extra document to know whether there is a next page
+	pipeline = append(pipeline, bson.M{"$limit": limit + 1})
+
+	// Connect to the integrations collection
+	integrationCollection := s.DocDB.Database(config.Current.MongoDatabase).Collection("integrations")
+
 	// Perform the query
-	cursor, err := integrationCollection.Find(ctx, filter)
-	if err == mongo.ErrNoDocuments {
-		// No integrations found
-		return &integration_service.SearchIntegrationResponse{
-			Integrations: []*integration_service.Integration{},
-		}, nil
-	} else if err != nil {
-		return nil, err
+	cursor, err := integrationCollection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to search integrations: %v", err)
 	}
 	defer cursor.Close(ctx)
 
+	var results []searchResult
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to decode integration: %v", err)
+	}
+
+	// Count every match for the client's pager
+	total, err := integrationCollection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to count integrations: %v", err)
+	}
+
+	var nextCursor string
+	if len(results) > int(limit) {
+		results = results[:limit]
+		last := results[len(results)-1]
+		nextCursor = encodeSearchCursor(searchCursor{Score: last.Score, ID: last.ID.Hex()})
+	}
+
 	// Process the results
-	var integrations []*integration_service.Integration
-	for cursor.Next(ctx) {
-		var integration models.Integration
-		if err := cursor.Decode(&integration); err != nil {
-			return nil, err
-		}
+	integrations := make([]*integration_service.Integration, 0, len(results))
+	for _, result := range results {
+		integration := result.Integration
 
-		// Convert timestamps
-		createdAt := timestamppb.New(integration.CreatedAt)
-		updatedAt := timestamppb.New(integration.UpdatedAt)
-		var deletedAt *timestamppb.Timestamp
-		if integration.DeletedAt != nil {
-			deletedAt = timestamppb.New(*integration.DeletedAt)
+		// Handle FailedReason pointer
+		var failedReason string
+		if integration.AdditionalInfo.FailedReason != nil {
+			failedReason = *integration.AdditionalInfo.FailedReason
 		}
 
 		// Add the integration to the response
@@ -69,20 +165,38 @@ func (s *IntegrationServer) SearchIntegration(ctx context.Context, req *integrat
 			AdditionalInfo: &integration_service.AdditionalInfo{
 				IsFileBased:      integration.AdditionalInfo.IsFileBased,
 				FileStatus:       string(integration.AdditionalInfo.FileStatus),
+				FailedReason:     failedReason,
 				PublicBaseURL:    integration.AdditionalInfo.PublicBaseURL,
 				DocumentationURL: integration.AdditionalInfo.DocumentationURL,
 				IsLocallyStored:  integration.AdditionalInfo.IsLocallyStored,
 			},
 			CreatedBy: integration.CreatedBy,
 			Public:    integration.Public,
-			CreatedAt: createdAt,
-			UpdatedAt: updatedAt,
-			DeletedAt: deletedAt,
+			CreatedAt: timestamppb.New(integration.CreatedAt),
+			UpdatedAt: timestamppb.New(integration.UpdatedAt),
+			DeletedAt: nil,
 		})
 	}
 
 	// Return the response
 	return &integration_service.SearchIntegrationResponse{
 		Integrations: integrations,
+		Total:        int32(total),
+		NextCursor:   nextCursor,
 	}, nil
 }
+
+func encodeSearchCursor(cursor searchCursor) string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeSearchCursor(value string) (searchCursor, error) {
+	var cursor searchCursor
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return cursor, err
+	}
+	err = json.Unmarshal(data, &cursor)
+	return cursor, err
+}
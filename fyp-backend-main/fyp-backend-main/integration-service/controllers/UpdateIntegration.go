@@ -2,56 +2,52 @@ package controllers
 
 import (
 	"context"
-	"errors"
 	"time"
 
+	"integration-service/config"
 	"integration-service/models"
 	integration_service "integration-service/proto/generated/github.com/multiagentai/backend/integration-service"
+	"shared/identity"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
-	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 func (s *IntegrationServer) UpdateIntegration(ctx context.Context, req *integration_service.UpdateIntegrationRequest) (*integration_service.UpdateIntegrationResponse, error) {
 	// Validate input fields
 	if req.GetId() == "" {
-		return nil, errors.New("id is required")
+		return nil, status.Error(codes.InvalidArgument, "id is required")
 	}
 
-	// Extract userID from gRPC metadata
-	md, ok := metadata.FromIncomingContext(ctx)
-	if !ok {
-		return nil, errors.New("metadata not provided in gRPC context")
-	}
-
-	userIDs := md.Get("userID")
-	if len(userIDs) == 0 {
-		return nil, errors.New("userID not found in metadata")
+	// Extract the userID of the verified caller
+	userID, err := identity.UserID(ctx)
+	if err != nil {
+		return nil, err
 	}
-	userID := userIDs[0]
 
 	// Find the integration by id
-	integrationCollection := s.DocDB.Database("fyp-db").Collection("integrations")
+	integrationCollection := s.DocDB.Database(config.Current.MongoDatabase).Collection("integrations")
 	objectID, err := primitive.ObjectIDFromHex(req.GetId())
 	if err != nil {
-		return nil, errors.New("invalid integration id format")
+		return nil, status.Error(codes.InvalidArgument, "invalid integration id format")
 	}
 	filter := bson.M{"_id": objectID}
 
 	var integration models.Integration
 	err = integrationCollection.FindOne(ctx, filter).Decode(&integration)
 	if err == mongo.ErrNoDocuments {
-		return nil, errors.New("integration not found")
+		return nil, status.Error(codes.NotFound, "integration not found")
 	} else if err != nil {
-		return nil, err
+		return nil, status.Errorf(codes.Internal, "failed to get integration: %v", err)
 	}
 
 	// Ensure the user is authorized to update the integration
 	if integration.CreatedBy != userID {
-		return nil, errors.New("user is not authorized to update this integration")
+		return nil, status.Error(codes.PermissionDenied, "user is not authorized to update this integration")
 	}
 
 	// If uniqueName is being updated, check if it's already taken
@@ -65,10 +61,10 @@ func (s *IntegrationServer) UpdateIntegration(ctx context.Context, req *integrat
 		}
 		count, err := integrationCollection.CountDocuments(ctx, existingFilter)
 		if err != nil {
-			return nil, err
+			return nil, status.Errorf(codes.Internal, "failed to check unique name: %v", err)
 		}
 		if count > 0 {
-			return nil, errors.New("unique name is already taken")
+			return nil, status.Error(codes.AlreadyExists, "unique name is already taken")
 		}
 	}
 
@@ -89,6 +85,7 @@ func (s *IntegrationServer) UpdateIntegration(ctx context.Context, req *integrat
 	}
 	if req.GetDocumentationURL() != "" {
 		updateFields["additional_info.documentation_url"] = req.GetDocumentationURL()
+		updateFields["additional_info.documentation_key"] = models.AttachmentKey(req.GetDocumentationURL())
 	}
 	if req.GetPublicBaseURL() != "" {
 		updateFields["additional_info.public_base_url"] = req.GetPublicBaseURL()
@@ -101,13 +98,13 @@ func (s *IntegrationServer) UpdateIntegration(ctx context.Context, req *integrat
 	update := bson.M{"$set": updateFields}
 	_, err = integrationCollection.UpdateOne(ctx, filter, update)
 	if err != nil {
-		return nil, err
+		return nil, status.Errorf(codes.Internal, "failed to update integration: %v", err)
 	}
 
 	// Fetch the updated integration
 	err = integrationCollection.FindOne(ctx, filter).Decode(&integration)
 	if err != nil {
-		return nil, err
+		return nil, status.Errorf(codes.Internal, "failed to fetch updated integration: %v", err)
 	}
 
 	// Convert timestamps to gRPC-compatible format
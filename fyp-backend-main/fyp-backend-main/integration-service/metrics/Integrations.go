@@ -0,0 +1,78 @@
+package metrics
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"integration-service/config"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var (
+	// IntegrationsCreated counts created integrations, by whether they are file based
+	IntegrationsCreated = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "integrations_created_total",
+		Help: "Integrations created, by whether they are file based.",
+	}, []string{"file_based"})
+
+	// PublishFailures counts events that couldn't be published to Redis, by channel
+	PublishFailures = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "redis_publish_failures_total",
+		Help: "Events that failed to publish to Redis, by channel.",
+	}, []string{"channel"})
+)
+
+// integrationsByStatusTimeout bounds the query made on every scrape
+const integrationsByStatusTimeout = 5 * time.Second
+
+var integrationsByStatusDesc = prometheus.NewDesc(
+	"integrations",
+	"File based integrations that aren't deleted, by file status.",
+	[]string{"file_status"}, nil,
+)
+
+// integrationsByStatus counts the file based integrations in MongoDB when scraped,
+// so the numbers follow status changes made by the chunkers
+type integrationsByStatus struct {
+	db *mongo.Client
+}
+
+// RegisterIntegrationsByStatus adds the integrations gauge, read from db on every scrape.
+func RegisterIntegrationsByStatus(db *mongo.Client) {
+	prometheus.MustRegister(&integrationsByStatus{db: db})
+}
+
+func (c *integrationsByStatus) Describe(ch chan<- *prometheus.Desc) {
+	ch <- integrationsByStatusDesc
+}
+
+func (c *integrationsByStatus) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), integrationsByStatusTimeout)
+	defer cancel()
+
+	cursor, err := c.db.Database(config.Current.MongoDatabase).Collection("integrations").Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"additional_info.is_file_based": true, "deleted_at": bson.M{"$exists": false}}}},
+		{{Key: "$group", Value: bson.M{"_id": "$additional_info.file_status", "count": bson.M{"$sum": 1}}}},
+	})
+	if err != nil {
+		slog.Error("Failed to count integrations by file status", "error", err)
+		return
+	}
+	var groups []struct {
+		Status string `bson:"_id"`
+		Count  int    `bson:"count"`
+	}
+	if err := cursor.All(ctx, &groups); err != nil {
+		slog.Error("Failed to count integrations by file status", "error", err)
+		return
+	}
+
+	for _, g := range groups {
+		ch <- prometheus.MustNewConstMetric(integrationsByStatusDesc, prometheus.GaugeValue, float64(g.Count), g.Status)
+	}
+}
@@ -0,0 +1,43 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.mongodb.org/mongo-driver/event"
+)
+
+var mongoDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+	Name:    "mongodb_command_duration_seconds",
+	Help:    "Time taken by MongoDB commands, by command and outcome.",
+	Buckets: prometheus.DefBuckets,
+}, []string{"command", "status"})
+
+// MongoMonitor records the latency of every MongoDB command and passes the events
+// on to next, since a client only takes one monitor.
+func MongoMonitor(next *event.CommandMonitor) *event.CommandMonitor {
+	if next == nil {
+		next = &event.CommandMonitor{}
+	}
+	return &event.CommandMonitor{
+		Started: next.Started,
+		Succeeded: func(ctx context.Context, e *event.CommandSucceededEvent) {
+			observeMongo(e.CommandName, "success", e.Duration)
+			if next.Succeeded != nil {
+				next.Succeeded(ctx, e)
+			}
+		},
+		Failed: func(ctx context.Context, e *event.CommandFailedEvent) {
+			observeMongo(e.CommandName, "failure", e.Duration)
+			if next.Failed != nil {
+				next.Failed(ctx, e)
+			}
+		},
+	}
+}
+
+func observeMongo(command string, status string, duration time.Duration) {
+	mongoDuration.WithLabelValues(command, status).Observe(duration.Seconds())
+}
@@ -1,6 +1,8 @@
 package models
 
 import (
+	"net/url"
+	"path"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -28,6 +30,9 @@ type AdditionalInfo struct {
 	PublicBaseURL string `bson:"public_base_url" json:"public_base_url"`
 	//documentation URL
 	DocumentationURL string `bson:"documentation_url" json:"documentation_url"`
+	// DocumentationKey is the file the documentation URL names, indexed so uploads can be
+	// matched to the integrations they document
+	DocumentationKey string `bson:"documentation_key" json:"-"`
 	IsLocallyStored  bool   `bson:"is_locally_stored" json:"is_locally_stored"`
 }
 
@@ -40,3 +45,26 @@ const (
 	FileStatusFailed    FileStatus = "failed"
 	FileStatusReady     FileStatus = "ready"
 )
+
+// IsValidFileStatus reports whether value is one of the known file statuses
+func IsValidFileStatus(value string) bool {
+	switch FileStatus(value) {
+	case FileStatusNoUpload, FileStatusUploaded, FileStatusPreparing, FileStatusFailed, FileStatusReady:
+		return true
+	}
+	return false
+}
+
+// AttachmentKey returns the key of the uploaded file a URL names, its last path segment.
+// Gateway file URLs end with the key, which starts with a UUID so it's unique.
+func AttachmentKey(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Path == "" {
+		return ""
+	}
+	key := path.Base(u.Path)
+	if key == "/" || key == "." {
+		return ""
+	}
+	return key
+}
@@ -25,6 +25,7 @@ const (
 	IntegrationService_SearchIntegration_FullMethodName                 = "/integration.IntegrationService/SearchIntegration"
 	IntegrationService_UpdateIntegration_FullMethodName                 = "/integration.IntegrationService/UpdateIntegration"
 	IntegrationService_GetPaginatedCommunityIntegrations_FullMethodName = "/integration.IntegrationService/GetPaginatedCommunityIntegrations"
+	IntegrationService_GetFileAttachment_FullMethodName                 = "/integration.IntegrationService/GetFileAttachment"
 )
 
 // IntegrationServiceClient is the client API for IntegrationService service.
@@ -37,6 +38,7 @@ type IntegrationServiceClient interface {
 	SearchIntegration(ctx context.Context, in *SearchIntegrationRequest, opts ...grpc.CallOption) (*SearchIntegrationResponse, error)
 	UpdateIntegration(ctx context.Context, in *UpdateIntegrationRequest, opts ...grpc.CallOption) (*UpdateIntegrationResponse, error)
 	GetPaginatedCommunityIntegrations(ctx context.Context, in *GetPaginatedCommunityIntegrationsRequest, opts ...grpc.CallOption) (*GetPaginatedCommunityIntegrationsResponse, error)
+	GetFileAttachment(ctx context.Context, in *GetFileAttachmentRequest, opts ...grpc.CallOption) (*GetFileAttachmentResponse, error)
 }
 
 type integrationServiceClient struct {
@@ -107,6 +109,16 @@ func (c *integrationServiceClient) GetPaginatedCommunityIntegrations(ctx context
 	return out, nil
 }
 
+func (c *integrationServiceClient) GetFileAttachment(ctx context.Context, in *GetFileAttachmentRequest, opts ...grpc.CallOption) (*GetFileAttachmentResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetFileAttachmentResponse)
+	err := c.cc.Invoke(ctx, IntegrationService_GetFileAttachment_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // IntegrationServiceServer is the server API for IntegrationService service.
 // All implementations must embed UnimplementedIntegrationServiceServer
 // for forward compatibility.
@@ -117,6 +129,7 @@ type IntegrationServiceServer interface {
 	SearchIntegration(context.Context, *SearchIntegrationRequest) (*SearchIntegrationResponse, error)
 	UpdateIntegration(context.Context, *UpdateIntegrationRequest) (*UpdateIntegrationResponse, error)
 	GetPaginatedCommunityIntegrations(context.Context, *GetPaginatedCommunityIntegrationsRequest) (*GetPaginatedCommunityIntegrationsResponse, error)
+	GetFileAttachment(context.Context, *GetFileAttachmentRequest) (*GetFileAttachmentResponse, error)
 	mustEmbedUnimplementedIntegrationServiceServer()
 }
 
@@ -145,6 +158,9 @@ func (UnimplementedIntegrationServiceServer) UpdateIntegration(context.Context,
 func (UnimplementedIntegrationServiceServer) GetPaginatedCommunityIntegrations(context.Context, *GetPaginatedCommunityIntegrationsRequest) (*GetPaginatedCommunityIntegrationsResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetPaginatedCommunityIntegrations not implemented")
 }
+func (UnimplementedIntegrationServiceServer) GetFileAttachment(context.Context, *GetFileAttachmentRequest) (*GetFileAttachmentResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetFileAttachment not implemented")
+}
 func (UnimplementedIntegrationServiceServer) mustEmbedUnimplementedIntegrationServiceServer() {}
 func (UnimplementedIntegrationServiceServer) testEmbeddedByValue()                            {}
 
@@ -274,6 +290,24 @@ func _IntegrationService_GetPaginatedCommunityIntegrations_Handler(srv interface
 	return interceptor(ctx, in, info, handler)
 }
 
+func _IntegrationService_GetFileAttachment_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetFileAttachmentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IntegrationServiceServer).GetFileAttachment(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: IntegrationService_GetFileAttachment_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IntegrationServiceServer).GetFileAttachment(ctx, req.(*GetFileAttachmentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // IntegrationService_ServiceDesc is the grpc.ServiceDesc for IntegrationService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -305,6 +339,10 @@ var IntegrationService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetPaginatedCommunityIntegrations",
 			Handler:    _IntegrationService_GetPaginatedCommunityIntegrations_Handler,
 		},
+		{
+			MethodName: "GetFileAttachment",
+			Handler:    _IntegrationService_GetFileAttachment_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "integration.proto",
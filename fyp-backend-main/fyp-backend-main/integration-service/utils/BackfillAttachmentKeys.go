@@ -0,0 +1,60 @@
+package utils
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"integration-service/config"
+	"integration-service/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// BackfillAttachmentKeys sets the documentation key of integrations created before it was
+// stored, once, so GetFileAttachment can match them
+func BackfillAttachmentKeys(client *mongo.Client) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	collection := client.Database(config.Current.MongoDatabase).Collection("integrations")
+	cursor, err := collection.Find(ctx, bson.M{
+		"additional_info.documentation_key": bson.M{"$exists": false},
+	}, options.Find().SetProjection(bson.M{"additional_info.documentation_url": 1}))
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	updated := 0
+	for cursor.Next(ctx) {
+		var integration struct {
+			ID             primitive.ObjectID `bson:"_id"`
+			AdditionalInfo struct {
+				DocumentationURL string `bson:"documentation_url"`
+			} `bson:"additional_info"`
+		}
+		if err := cursor.Decode(&integration); err != nil {
+			return err
+		}
+		// Integrations without a documentation URL get an empty key, so they aren't read again
+		_, err := collection.UpdateByID(ctx, integration.ID, bson.M{"$set": bson.M{
+			"additional_info.documentation_key": models.AttachmentKey(integration.AdditionalInfo.DocumentationURL),
+		}})
+		if err != nil {
+			return err
+		}
+		updated++
+	}
+	if err := cursor.Err(); err != nil {
+		return err
+	}
+
+	if updated > 0 {
+		slog.Info("Stored the documentation key of existing integrations", "count", updated)
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package utils
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"integration-service/config"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// EnsureIndexes creates the indexes the integration service relies on
+func EnsureIndexes(client *mongo.Client) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	// Text index used by SearchIntegration, names rank above the description
+	_, err := client.Database(config.Current.MongoDatabase).Collection("integrations").Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{
+			{Key: "display_name", Value: "text"},
+			{Key: "unique_name", Value: "text"},
+			{Key: "description", Value: "text"},
+		},
+		Options: options.Index().
+			SetName("integration_text_search").
+			SetWeights(bson.D{
+				{Key: "display_name", Value: 10},
+				{Key: "unique_name", Value: 8},
+				{Key: "description", Value: 2},
+			}),
+	})
+	if err != nil {
+		return err
+	}
+
+	// Used by GetFileAttachment to find the integrations an uploaded file documents
+	_, err = client.Database(config.Current.MongoDatabase).Collection("integrations").Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "additional_info.documentation_key", Value: 1}},
+		Options: options.Index().SetName("integration_documentation_key"),
+	})
+	if err != nil {
+		return err
+	}
+
+	slog.Info("MongoDB indexes are up to date")
+	return nil
+}
@@ -0,0 +1,216 @@
+package envconfig
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Settings are declared as fields of a flat struct tagged with
+//
+//	env:"NAME"        environment variable, also the key in the config file
+//	default:"value"   typed default
+//	required:"true"   must be set after loading
+//	secret:"true"     masked by Redacted
+//	usage:"text"      flag help text
+//
+// Each setting also gets a flag named after it, e.g. MONGO_URL is -mongo-url.
+// Precedence, lowest first: defaults, config file, environment, flags. Empty
+// values in the config file or environment count as unset.
+// The config file holds KEY=value lines and is set with -config or CONFIG_FILE.
+
+// fileValues holds the config file, kept for Lookup
+var fileValues = map[string]string{}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// Load fills cfg, a pointer to a tagged struct, from every source in order of precedence.
+func Load(cfg any, args []string) error {
+	v := reflect.ValueOf(cfg).Elem()
+	t := v.Type()
+
+	// Parse the flags first, they decide which config file is read
+	fs := flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+	configFile := fs.String("config", os.Getenv("CONFIG_FILE"), "config file of KEY=value lines")
+	flagValues := map[string]*string{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		key := field.Tag.Get("env")
+		if key == "" {
+			continue
+		}
+		flagValues[key] = fs.String(flagName(key), "", field.Tag.Get("usage"))
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	setFlags := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) { setFlags[f.Name] = true })
+
+	if *configFile != "" {
+		values, err := readConfigFile(*configFile)
+		if err != nil {
+			return err
+		}
+		fileValues = values
+	}
+
+	var errs []error
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		key := field.Tag.Get("env")
+		if key == "" {
+			continue
+		}
+
+		value, source := field.Tag.Get("default"), "default"
+		if fileValue := fileValues[key]; fileValue != "" {
+			value, source = fileValue, *configFile
+		}
+		if envValue := os.Getenv(key); envValue != "" {
+			value, source = envValue, "environment"
+		}
+		if setFlags[flagName(key)] {
+			value, source = *flagValues[key], "flag -"+flagName(key)
+		}
+
+		if err := setField(v.Field(i), value); err != nil {
+			errs = append(errs, fmt.Errorf("%s (from %s): %w", key, source, err))
+			continue
+		}
+		if field.Tag.Get("required") == "true" && v.Field(i).IsZero() {
+			errs = append(errs, fmt.Errorf("%s is required", key))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Lookup returns a setting that isn't a field of the config, such as per provider
+// settings with dynamic names. The environment takes precedence over the config file.
+func Lookup(key string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fileValues[key]
+}
+
+// Redacted returns the effective settings as KEY=value lines with secrets masked.
+func Redacted(cfg any) string {
+	v := reflect.ValueOf(cfg).Elem()
+	t := v.Type()
+
+	var b strings.Builder
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		key := field.Tag.Get("env")
+		if key == "" {
+			continue
+		}
+		value := formatField(v.Field(i))
+		if field.Tag.Get("secret") == "true" && value != "" {
+			value = "[REDACTED]"
+		}
+		fmt.Fprintf(&b, "  %s=%s\n", key, value)
+	}
+	return b.String()
+}
+
+// flagName turns MONGO_URL into mongo-url
+func flagName(key string) string {
+	return strings.ReplaceAll(strings.ToLower(key), "_", "-")
+}
+
+// setField parses value into a string, int, bool, duration or string list field
+func setField(field reflect.Value, value string) error {
+	value = strings.TrimSpace(value)
+	switch {
+	case field.Type() == durationType:
+		if value == "" {
+			field.SetInt(0)
+			return nil
+		}
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q", value)
+		}
+		field.SetInt(int64(d))
+	case field.Kind() == reflect.String:
+		field.SetString(value)
+	case field.Kind() == reflect.Int:
+		if value == "" {
+			field.SetInt(0)
+			return nil
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("invalid number %q", value)
+		}
+		field.SetInt(int64(n))
+	case field.Kind() == reflect.Bool:
+		if value == "" {
+			field.SetBool(false)
+			return nil
+		}
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", value)
+		}
+		field.SetBool(b)
+	case field.Kind() == reflect.Slice && field.Type().Elem().Kind() == reflect.String:
+		var items []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		field.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("unsupported setting type %s", field.Type())
+	}
+	return nil
+}
+
+// formatField is the inverse of setField
+func formatField(field reflect.Value) string {
+	switch {
+	case field.Type() == durationType:
+		return time.Duration(field.Int()).String()
+	case field.Kind() == reflect.Slice:
+		return strings.Join(field.Interface().([]string), ",")
+	}
+	return fmt.Sprint(field.Interface())
+}
+
+// readConfigFile parses KEY=value lines, ignoring blank lines and # comments
+func readConfigFile(path string) (map[string]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+	defer file.Close()
+
+	values := map[string]string{}
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(strings.TrimPrefix(text, "export "), "=")
+		if !ok {
+			return nil, fmt.Errorf("%s:%d: expected KEY=value", path, line)
+		}
+		value = strings.TrimSpace(value)
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+		values[strings.TrimSpace(key)] = value
+	}
+	return values, scanner.Err()
+}
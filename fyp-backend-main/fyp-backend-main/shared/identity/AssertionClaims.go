@@ -0,0 +1,16 @@
+package identity
+
+import (
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// AssertionClaims are the claims of an internal assertion, signed by the gateway and
+// checked by Verifier.
+type AssertionClaims struct {
+	jwt.RegisteredClaims
+	Scopes     []string `json:"scp,omitempty"`
+	AuthMethod string   `json:"amr,omitempty"`
+	AuthTime   int64    `json:"auth_time,omitempty"`
+	ClientIP   string   `json:"cip,omitempty"`
+	RequestID  string   `json:"rid,omitempty"`
+}
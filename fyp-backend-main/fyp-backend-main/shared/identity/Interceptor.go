@@ -0,0 +1,74 @@
+package identity
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// AssertionMetadataKey is the metadata key the gateway sends the signed assertion in.
+const AssertionMetadataKey = "x-internal-assertion"
+
+// healthServicePrefix matches the methods of the gRPC health service, which
+// orchestrator probes call without an assertion.
+const healthServicePrefix = "/grpc.health.v1.Health/"
+
+// UnaryServerInterceptor rejects calls without a valid gateway assertion and
+// places the caller's principal in the context.
+func UnaryServerInterceptor(v *Verifier) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := authenticate(ctx, v, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor is the streaming counterpart of UnaryServerInterceptor.
+func StreamServerInterceptor(v *Verifier) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := authenticate(ss.Context(), v, info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &principalStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// authenticate verifies the assertion in the incoming metadata
+func authenticate(ctx context.Context, v *Verifier, method string) (context.Context, error) {
+	if strings.HasPrefix(method, healthServicePrefix) {
+		return ctx, nil
+	}
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "missing internal assertion")
+	}
+	values := md.Get(AssertionMetadataKey)
+	if len(values) != 1 {
+		return nil, status.Error(codes.Unauthenticated, "missing internal assertion")
+	}
+
+	p, err := v.Verify(values[0])
+	if err != nil {
+		slog.WarnContext(ctx, "Rejected call", "method", method, "error", err)
+		return nil, status.Error(codes.Unauthenticated, "invalid internal assertion")
+	}
+	return NewContext(ctx, p), nil
+}
+
+// principalStream overrides the context of a server stream
+type principalStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *principalStream) Context() context.Context {
+	return s.ctx
+}
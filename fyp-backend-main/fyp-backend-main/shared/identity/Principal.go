@@ -0,0 +1,42 @@
+package identity
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Principal is the caller of an RPC, as asserted by the api-gateway.
+type Principal struct {
+	// UserID is empty for calls made on behalf of anonymous users (e.g. Login)
+	UserID     string
+	Scopes     []string
+	AuthMethod string
+	AuthTime   time.Time
+	ClientIP   string
+	RequestID  string
+}
+
+type principalKey struct{}
+
+// NewContext returns a copy of ctx carrying the principal.
+func NewContext(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// FromContext returns the principal placed in the context by the interceptor.
+func FromContext(ctx context.Context) (*Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(*Principal)
+	return p, ok && p != nil
+}
+
+// UserID returns the ID of the signed in user making the call.
+func UserID(ctx context.Context) (string, error) {
+	p, ok := FromContext(ctx)
+	if !ok || p.UserID == "" {
+		return "", status.Error(codes.Unauthenticated, "no signed in user")
+	}
+	return p.UserID, nil
+}
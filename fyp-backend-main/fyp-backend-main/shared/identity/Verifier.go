@@ -0,0 +1,99 @@
+package identity
+
+import (
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// Issuer is the iss claim of every internal assertion, the gateway is the only signer.
+const Issuer = "api-gateway"
+
+// Verifier checks internal assertions signed by the gateway.
+type Verifier struct {
+	Audience string
+	keys     []ed25519.PublicKey
+}
+
+// LoadVerifier reads the gateway's public keys from PEM files. More than one key can be
+// given so a new key can be rolled out before the old one is dropped.
+// The audience is the name of this service.
+func LoadVerifier(audience string, keyFiles []string) (*Verifier, error) {
+	v := &Verifier{Audience: audience}
+	for _, path := range keyFiles {
+		key, err := readPublicKey(path)
+		if err != nil {
+			return nil, err
+		}
+		v.keys = append(v.keys, key)
+	}
+	if len(v.keys) == 0 {
+		return nil, errors.New("no internal auth public keys configured")
+	}
+	return v, nil
+}
+
+// Verify checks the signature, issuer, audience and expiry of an assertion and returns its principal.
+func (v *Verifier) Verify(assertion string) (*Principal, error) {
+	var lastErr error
+	for _, key := range v.keys {
+		claims := &AssertionClaims{}
+		_, err := jwt.ParseWithClaims(assertion, claims, func(*jwt.Token) (interface{}, error) {
+			return key, nil
+		}, jwt.WithValidMethods([]string{jwt.SigningMethodEdDSA.Alg()}))
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		if claims.Issuer != Issuer {
+			return nil, errors.New("unexpected issuer")
+		}
+		if !claims.VerifyAudience(v.Audience, true) {
+			return nil, errors.New("unexpected audience")
+		}
+		if claims.ExpiresAt == nil {
+			return nil, errors.New("assertion has no expiry")
+		}
+
+		p := &Principal{
+			UserID:     claims.Subject,
+			Scopes:     claims.Scopes,
+			AuthMethod: claims.AuthMethod,
+			ClientIP:   claims.ClientIP,
+			RequestID:  claims.RequestID,
+		}
+		if claims.AuthTime > 0 {
+			p.AuthTime = time.Unix(claims.AuthTime, 0)
+		}
+		return p, nil
+	}
+	return nil, lastErr
+}
+
+// readPublicKey parses a PEM encoded Ed25519 public key
+func readPublicKey(path string) (ed25519.PublicKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%s does not contain a PEM block", path)
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	edKey, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("%s is not an Ed25519 public key", path)
+	}
+	return edKey, nil
+}
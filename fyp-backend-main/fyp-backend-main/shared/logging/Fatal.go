@@ -0,0 +1,12 @@
+package logging
+
+import (
+	"log/slog"
+	"os"
+)
+
+// Fatal logs at error level and exits, for failures the process can't start without.
+func Fatal(msg string, args ...any) {
+	slog.Error(msg, args...)
+	os.Exit(1)
+}
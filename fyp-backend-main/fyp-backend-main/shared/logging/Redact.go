@@ -0,0 +1,58 @@
+package logging
+
+import (
+	"encoding/json"
+	"strings"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// redactedValue replaces the value of sensitive fields
+const redactedValue = "[REDACTED]"
+
+// Redact returns a request as a JSON value for logging, with passwords, tokens, codes
+// and other secrets replaced by "[REDACTED]".
+func Redact(msg any) any {
+	m, ok := msg.(proto.Message)
+	if !ok {
+		return nil
+	}
+	data, err := protojson.Marshal(m)
+	if err != nil {
+		return nil
+	}
+	var value any
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil
+	}
+	return redactValue(value)
+}
+
+func redactValue(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for field, inner := range v {
+			if isSensitive(field) {
+				v[field] = redactedValue
+			} else {
+				v[field] = redactValue(inner)
+			}
+		}
+	case []any:
+		for i, inner := range v {
+			v[i] = redactValue(inner)
+		}
+	}
+	return value
+}
+
+// isSensitive reports whether a field holds a credential
+func isSensitive(field string) bool {
+	name := strings.ToLower(field)
+	switch name {
+	case "code", "key", "secret", "assertion":
+		return true
+	}
+	return strings.Contains(name, "password") || strings.Contains(name, "token") || strings.Contains(name, "secret")
+}
@@ -0,0 +1,74 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"time"
+
+	"shared/identity"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// RequestIDMetadataKey is the metadata key the gateway sends the request ID in.
+const RequestIDMetadataKey = "x-request-id"
+
+// healthServicePrefix matches the methods of the gRPC health service
+const healthServicePrefix = "/grpc.health.v1.Health/"
+
+// UnaryServerInterceptor logs every call with its method, status code and latency.
+// The caller's request ID and user ID are attached to the context, so every line
+// logged while handling the call carries them. Requests are logged at debug level
+// with their secrets redacted.
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		// Health checks are polled by the gateway and would drown out the calls
+		if strings.HasPrefix(info.FullMethod, healthServicePrefix) {
+			return handler(ctx, req)
+		}
+		start := time.Now()
+		ctx = With(ctx, callAttrs(ctx, info.FullMethod)...)
+		slog.DebugContext(ctx, "Received call", "request", Redact(req))
+
+		res, err := handler(ctx, req)
+
+		code := status.Code(err)
+		args := []any{"code", code.String(), "latency_ms", time.Since(start).Milliseconds()}
+		if err != nil {
+			args = append(args, "error", err.Error())
+		}
+		slog.Log(ctx, levelForCode(code), "Handled call", args...)
+		return res, err
+	}
+}
+
+// callAttrs identifies the call, preferring the IDs of the verified assertion
+func callAttrs(ctx context.Context, method string) []slog.Attr {
+	attrs := []slog.Attr{slog.String("method", method)}
+	if p, ok := identity.FromContext(ctx); ok {
+		if p.RequestID != "" {
+			attrs = append(attrs, slog.String("request_id", p.RequestID))
+		}
+		if p.UserID != "" {
+			attrs = append(attrs, slog.String("user_id", p.UserID))
+		}
+		return attrs
+	}
+	if ids := metadata.ValueFromIncomingContext(ctx, RequestIDMetadataKey); len(ids) == 1 {
+		attrs = append(attrs, slog.String("request_id", ids[0]))
+	}
+	return attrs
+}
+
+// levelForCode logs server side failures as errors
+func levelForCode(code codes.Code) slog.Level {
+	switch code {
+	case codes.Internal, codes.Unknown, codes.DataLoss:
+		return slog.LevelError
+	}
+	return slog.LevelInfo
+}
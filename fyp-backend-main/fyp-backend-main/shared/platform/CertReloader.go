@@ -0,0 +1,125 @@
+package platform
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+)
+
+// certReloadInterval is how often the certificate files are checked for changes
+const certReloadInterval = 10 * time.Second
+
+// CertReloader keeps a key pair and CA bundle loaded from disk, picking up rotated
+// files without a restart. If a reload fails the previous certificates stay in use.
+type CertReloader struct {
+	certFile, keyFile, caFile string
+
+	mu        sync.RWMutex
+	cert      *tls.Certificate
+	pool      *x509.CertPool
+	modTimes  map[string]time.Time
+	lastCheck time.Time
+}
+
+// NewCertReloader loads the key pair and CA bundle, either of which may be left empty.
+func NewCertReloader(certFile, keyFile, caFile string) (*CertReloader, error) {
+	if (certFile == "") != (keyFile == "") {
+		return nil, errors.New("a certificate and its key must be set together")
+	}
+	r := &CertReloader{certFile: certFile, keyFile: keyFile, caFile: caFile}
+	if err := r.reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Certificate returns the current key pair.
+func (r *CertReloader) Certificate() *tls.Certificate {
+	r.maybeReload()
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.cert
+}
+
+// CAPool returns the current CA bundle.
+func (r *CertReloader) CAPool() *x509.CertPool {
+	r.maybeReload()
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.pool
+}
+
+// maybeReload reloads the files if they changed, at most once per interval
+func (r *CertReloader) maybeReload() {
+	r.mu.Lock()
+	if time.Since(r.lastCheck) < certReloadInterval {
+		r.mu.Unlock()
+		return
+	}
+	r.lastCheck = time.Now()
+	changed := false
+	for _, path := range []string{r.certFile, r.keyFile, r.caFile} {
+		if path == "" {
+			continue
+		}
+		if info, err := os.Stat(path); err == nil && !info.ModTime().Equal(r.modTimes[path]) {
+			changed = true
+		}
+	}
+	r.mu.Unlock()
+
+	if changed {
+		if err := r.reload(); err != nil {
+			slog.Error("Failed to reload TLS certificates, keeping the current ones", "error", err)
+			return
+		}
+		slog.Info("Reloaded TLS certificates")
+	}
+}
+
+// reload reads every file and swaps them in at once
+func (r *CertReloader) reload() error {
+	modTimes := map[string]time.Time{}
+	for _, path := range []string{r.certFile, r.keyFile, r.caFile} {
+		if path == "" {
+			continue
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+		modTimes[path] = info.ModTime()
+	}
+
+	var cert *tls.Certificate
+	if r.certFile != "" {
+		pair, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+		if err != nil {
+			return fmt.Errorf("failed to load key pair: %w", err)
+		}
+		cert = &pair
+	}
+
+	var pool *x509.CertPool
+	if r.caFile != "" {
+		data, err := os.ReadFile(r.caFile)
+		if err != nil {
+			return err
+		}
+		pool = x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(data) {
+			return fmt.Errorf("%s does not contain any certificates", r.caFile)
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cert, r.pool, r.modTimes = cert, pool, modTimes
+	r.lastCheck = time.Now()
+	return nil
+}
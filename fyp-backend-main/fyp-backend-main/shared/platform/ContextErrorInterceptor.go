@@ -0,0 +1,21 @@
+package platform
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// ContextErrorUnaryInterceptor reports calls that failed because the caller's deadline
+// passed or the caller went away as DeadlineExceeded or Canceled, instead of whatever
+// the handler wrapped the MongoDB or Redis error in.
+func ContextErrorUnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		res, err := handler(ctx, req)
+		if err != nil && ctx.Err() != nil {
+			return nil, status.FromContextError(ctx.Err()).Err()
+		}
+		return res, err
+	}
+}
@@ -0,0 +1,25 @@
+package platform
+
+import (
+	"log/slog"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+// GracefulStop stops accepting new calls and waits up to timeout for the
+// in-flight ones to finish before closing them.
+func GracefulStop(server *grpc.Server, timeout time.Duration) {
+	done := make(chan struct{})
+	go func() {
+		server.GracefulStop()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(timeout):
+		slog.Warn("Calls still running, stopping the server", "timeout", timeout)
+		server.Stop()
+	}
+}
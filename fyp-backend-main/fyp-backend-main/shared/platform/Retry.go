@@ -0,0 +1,46 @@
+package platform
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+const (
+	// retryInitialBackoff is the wait after the first failed attempt, it doubles after each one
+	retryInitialBackoff = 500 * time.Millisecond
+	// retryMaxBackoff caps the wait between attempts
+	retryMaxBackoff = 10 * time.Second
+	// retryAttemptTimeout bounds a single attempt
+	retryAttemptTimeout = 10 * time.Second
+)
+
+// Retry calls fn until it succeeds, backing off exponentially between attempts.
+// It returns the last error once maxWait has passed or ctx is done.
+func Retry(ctx context.Context, name string, maxWait time.Duration, fn func(ctx context.Context) error) error {
+	deadline := time.Now().Add(maxWait)
+	backoff := retryInitialBackoff
+	for attempt := 1; ; attempt++ {
+		attemptCtx, cancel := context.WithTimeout(ctx, retryAttemptTimeout)
+		err := fn(attemptCtx)
+		cancel()
+		if err == nil {
+			return nil
+		}
+
+		// The last wait is cut short so the final attempt happens at the deadline
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return fmt.Errorf("%s failed after %d attempts: %w", name, attempt, err)
+		}
+		wait := min(backoff, remaining)
+		slog.WarnContext(ctx, name+" failed, retrying", "attempt", attempt, "retry_in", wait.Round(time.Millisecond).String(), "error", err)
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%s: %w", name, ctx.Err())
+		case <-time.After(wait):
+		}
+		backoff = min(backoff*2, retryMaxBackoff)
+	}
+}
@@ -0,0 +1,77 @@
+package platform
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"log/slog"
+	"slices"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+// ServerTLSOptions are the transport security settings of a gRPC server.
+type ServerTLSOptions struct {
+	// CertFile is the PEM certificate served to clients, it enables TLS
+	CertFile string
+	KeyFile  string
+	// ClientCAFile is the CA bundle client certificates must chain to, it enables mutual TLS
+	ClientCAFile string
+	// AllowedClientIDs are the SPIFFE IDs (URI SANs) accepted from clients, any when empty
+	AllowedClientIDs []string
+}
+
+// ServerCredentials returns the gRPC server option for the configured transport security.
+// The files are reloaded when they change. Without a certificate the server runs in plaintext.
+func ServerCredentials(opts ServerTLSOptions) (grpc.ServerOption, error) {
+	certFile, keyFile, caFile := opts.CertFile, opts.KeyFile, opts.ClientCAFile
+	if certFile == "" {
+		slog.Info("GRPC_TLS_CERT_FILE is not set, serving gRPC without TLS")
+		return grpc.EmptyServerOption{}, nil
+	}
+
+	reloader, err := NewCertReloader(certFile, keyFile, caFile)
+	if err != nil {
+		return nil, err
+	}
+	allowedIDs := opts.AllowedClientIDs
+
+	tlsConfig := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		// A fresh config per handshake picks up reloaded certificates and CAs
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			c := &tls.Config{
+				MinVersion:   tls.VersionTLS12,
+				Certificates: []tls.Certificate{*reloader.Certificate()},
+			}
+			if caFile != "" {
+				c.ClientAuth = tls.RequireAndVerifyClientCert
+				c.ClientCAs = reloader.CAPool()
+				if len(allowedIDs) > 0 {
+					c.VerifyPeerCertificate = func(_ [][]byte, chains [][]*x509.Certificate) error {
+						return checkSPIFFEID(chains[0][0], allowedIDs)
+					}
+				}
+			}
+			return c, nil
+		},
+	}
+
+	if caFile != "" {
+		slog.Info("Serving gRPC with mutual TLS")
+	} else {
+		slog.Info("Serving gRPC with TLS")
+	}
+	return grpc.Creds(credentials.NewTLS(tlsConfig)), nil
+}
+
+// checkSPIFFEID accepts the certificate if one of its URI SANs is an allowed ID
+func checkSPIFFEID(cert *x509.Certificate, allowedIDs []string) error {
+	for _, uri := range cert.URIs {
+		if uri.Scheme == "spiffe" && slices.Contains(allowedIDs, uri.String()) {
+			return nil
+		}
+	}
+	return fmt.Errorf("client certificate %q has no allowed SPIFFE ID", cert.Subject.CommonName)
+}
@@ -0,0 +1,21 @@
+package platform
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// TracingSampleRatio returns the ratio of new traces the sampler setting asks to record.
+func TracingSampleRatio(sampler string) (float64, error) {
+	switch sampler {
+	case "always_on":
+		return 1, nil
+	case "always_off":
+		return 0, nil
+	}
+	ratio, err := strconv.ParseFloat(sampler, 64)
+	if err != nil || ratio < 0 || ratio > 1 {
+		return 0, fmt.Errorf("TRACING_SAMPLER must be always_on, always_off or a ratio between 0 and 1, got %q", sampler)
+	}
+	return ratio, nil
+}
@@ -0,0 +1,57 @@
+package platform
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+const (
+	// HealthCheckInterval is how often the dependencies are pinged
+	HealthCheckInterval = 10 * time.Second
+	// healthCheckTimeout bounds a single round of pings
+	healthCheckTimeout = 2 * time.Second
+)
+
+// HealthCheck pings a dependency the service can't serve without.
+type HealthCheck struct {
+	// Name is the dependency as logged, e.g. MongoDB
+	Name string
+	Ping func(ctx context.Context) error
+}
+
+// WatchHealth keeps the status reported by the gRPC health service in line with
+// the dependencies, serving only while every check passes. The status is set for
+// the whole server ("") and for the named service.
+func WatchHealth(hs *health.Server, service string, checks ...HealthCheck) {
+	last := healthpb.HealthCheckResponse_UNKNOWN
+	check := func() {
+		ctx, cancel := context.WithTimeout(context.Background(), healthCheckTimeout)
+		defer cancel()
+
+		status := healthpb.HealthCheckResponse_SERVING
+		for _, c := range checks {
+			if err := c.Ping(ctx); err != nil {
+				slog.Warn("Health check: "+c.Name+" ping failed", "error", err)
+				status = healthpb.HealthCheckResponse_NOT_SERVING
+			}
+		}
+
+		if status != last {
+			slog.Info("Health status changed", "status", status.String())
+			last = status
+		}
+		hs.SetServingStatus("", status)
+		hs.SetServingStatus(service, status)
+	}
+
+	check()
+	go func() {
+		for range time.Tick(HealthCheckInterval) {
+			check()
+		}
+	}()
+}
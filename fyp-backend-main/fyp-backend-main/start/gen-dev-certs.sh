@@ -0,0 +1,58 @@
+#!/bin/sh
+# Generates a self-signed CA and certificates for local mutual TLS between the
+# gateway and the gRPC services. Re-running it rotates the leaf certificates,
+# which the running services pick up without a restart.
+#
+#   certs/ca-key.pem                 CA private key, never mounted into a container
+#   certs/<service>/ca.pem           CA certificate
+#   certs/<service>/tls.pem          certificate with DNS and SPIFFE (URI) SANs
+#   certs/<service>/tls-key.pem      its private key
+set -eu
+
+DIR=${CERTS_DIR:-certs}
+TRUST_DOMAIN=${TRUST_DOMAIN:-flomny.local}
+DAYS=${DAYS:-365}
+
+mkdir -p "$DIR"
+
+if [ ! -f "$DIR/ca-key.pem" ]; then
+	echo "Generating development CA..."
+	openssl ecparam -name prime256v1 -genkey -noout -out "$DIR/ca-key.pem"
+	openssl req -x509 -new -key "$DIR/ca-key.pem" -sha256 -days 3650 \
+		-subj "/CN=Flomny development CA" -out "$DIR/ca.pem"
+fi
+
+# issue <name> <extended key usage>
+issue() {
+	name=$1
+	usage=$2
+	out="$DIR/$name"
+	mkdir -p "$out"
+	echo "Issuing certificate for $name..."
+
+	cat > "$out/ext.cnf" <<EXT
+basicConstraints=CA:FALSE
+keyUsage=digitalSignature,keyEncipherment
+extendedKeyUsage=$usage
+subjectAltName=DNS:$name,DNS:localhost,IP:127.0.0.1,URI:spiffe://$TRUST_DOMAIN/$name
+EXT
+
+	openssl ecparam -name prime256v1 -genkey -noout -out "$out/key.tmp"
+	openssl req -new -key "$out/key.tmp" -subj "/CN=$name" -out "$out/csr.tmp"
+	openssl x509 -req -in "$out/csr.tmp" -CA "$DIR/ca.pem" -CAkey "$DIR/ca-key.pem" \
+		-CAcreateserial -days "$DAYS" -sha256 -extfile "$out/ext.cnf" -out "$out/cert.tmp"
+
+	# Swap the files in with renames so a reload never sees half written files
+	cp "$DIR/ca.pem" "$out/ca.tmp"
+	mv "$out/ca.tmp" "$out/ca.pem"
+	mv "$out/key.tmp" "$out/tls-key.pem"
+	mv "$out/cert.tmp" "$out/tls.pem"
+	rm -f "$out/csr.tmp" "$out/ext.cnf"
+}
+
+issue auth-service serverAuth
+issue integration-service serverAuth
+issue workflow-service serverAuth
+issue api-gateway clientAuth
+
+echo "Certificates written to $DIR"
@@ -0,0 +1,112 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"shared/envconfig"
+	"shared/platform"
+)
+
+// Config holds every setting of the workflow service, see shared/envconfig for how it is read.
+type Config struct {
+	// Server
+	Port            int           `env:"PORT" default:"50002" usage:"gRPC listen port"`
+	AdminPort       int           `env:"ADMIN_PORT" default:"9103" usage:"port of the admin server serving /metrics, keep it private"`
+	ShutdownTimeout time.Duration `env:"SHUTDOWN_TIMEOUT" default:"20s" usage:"how long in-flight calls may drain after SIGTERM"`
+	StartupMaxWait  time.Duration `env:"STARTUP_MAX_WAIT" default:"2m" usage:"how long connections to dependencies are retried at startup"`
+
+	// Storage
+	MongoURL      string `env:"MONGO_URL" required:"true" secret:"true" usage:"MongoDB connection string"`
+	MongoDatabase string `env:"MONGO_DATABASE" default:"fyp-db" usage:"MongoDB database name"`
+
+	// Calls from the gateway
+	InternalAuthPublicKeyFiles []string `env:"INTERNAL_AUTH_PUBLIC_KEY_FILES" required:"true" usage:"gateway public keys (PEM files) internal assertions are verified with"`
+	TLSCertFile                string   `env:"GRPC_TLS_CERT_FILE" usage:"certificate served to clients, enables TLS"`
+	TLSKeyFile                 string   `env:"GRPC_TLS_KEY_FILE" usage:"private key of the certificate"`
+	TLSClientCAFile            string   `env:"GRPC_TLS_CLIENT_CA_FILE" usage:"CA bundle client certificates must chain to, enables mutual TLS"`
+	TLSAllowedClientIDs        []string `env:"GRPC_TLS_ALLOWED_CLIENT_IDS" usage:"SPIFFE IDs accepted from clients"`
+
+	// Logging
+	LogLevel  string `env:"LOG_LEVEL" default:"info" usage:"debug, info, warn or error"`
+	LogFormat string `env:"LOG_FORMAT" default:"json" usage:"json or text"`
+
+	// Tracing
+	TracingExporter string `env:"TRACING_EXPORTER" default:"none" usage:"where spans are sent: none, stdout or otlp"`
+	TracingSampler  string `env:"TRACING_SAMPLER" default:"always_on" usage:"always_on, always_off or the ratio of new traces sampled, calls follow the caller's decision"`
+	OTLPEndpoint    string `env:"OTLP_ENDPOINT" default:"localhost:4317" usage:"OTLP gRPC collector host:port"`
+	OTLPInsecure    bool   `env:"OTLP_INSECURE" usage:"send spans to the collector without TLS"`
+}
+
+// Current is the configuration loaded at startup.
+var Current *Config
+
+// Load reads the configuration from the defaults, config file, environment and command line flags.
+func Load() (*Config, error) {
+	cfg := &Config{}
+	if err := envconfig.Load(cfg, os.Args[1:]); err != nil {
+		return nil, fmt.Errorf("invalid configuration:\n%w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid configuration:\n%w", err)
+	}
+	return cfg, nil
+}
+
+// Validate checks settings that depend on each other.
+func (c *Config) Validate() error {
+	var errs []error
+	if c.Port < 1 || c.Port > 65535 {
+		errs = append(errs, fmt.Errorf("PORT must be between 1 and 65535, got %d", c.Port))
+	}
+	if c.AdminPort < 1 || c.AdminPort > 65535 || c.AdminPort == c.Port {
+		errs = append(errs, fmt.Errorf("ADMIN_PORT must be between 1 and 65535 and differ from PORT, got %d", c.AdminPort))
+	}
+	if c.ShutdownTimeout <= 0 {
+		errs = append(errs, fmt.Errorf("SHUTDOWN_TIMEOUT must be positive, got %s", c.ShutdownTimeout))
+	}
+	if c.StartupMaxWait <= 0 {
+		errs = append(errs, fmt.Errorf("STARTUP_MAX_WAIT must be positive, got %s", c.StartupMaxWait))
+	}
+	if c.MongoDatabase == "" {
+		errs = append(errs, errors.New("MONGO_DATABASE is required"))
+	}
+	if (c.TLSCertFile == "") != (c.TLSKeyFile == "") {
+		errs = append(errs, errors.New("GRPC_TLS_CERT_FILE and GRPC_TLS_KEY_FILE must be set together"))
+	}
+	if c.TLSClientCAFile != "" && c.TLSCertFile == "" {
+		errs = append(errs, errors.New("GRPC_TLS_CLIENT_CA_FILE requires GRPC_TLS_CERT_FILE"))
+	}
+	if len(c.TLSAllowedClientIDs) > 0 && c.TLSClientCAFile == "" {
+		errs = append(errs, errors.New("GRPC_TLS_ALLOWED_CLIENT_IDS requires GRPC_TLS_CLIENT_CA_FILE"))
+	}
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(c.LogLevel)); err != nil {
+		errs = append(errs, fmt.Errorf("LOG_LEVEL must be debug, info, warn or error, got %q", c.LogLevel))
+	}
+	if c.LogFormat != "json" && c.LogFormat != "text" {
+		errs = append(errs, fmt.Errorf("LOG_FORMAT must be json or text, got %q", c.LogFormat))
+	}
+	switch c.TracingExporter {
+	case "none", "stdout", "otlp":
+	default:
+		errs = append(errs, fmt.Errorf("TRACING_EXPORTER must be none, stdout or otlp, got %q", c.TracingExporter))
+	}
+	if _, err := platform.TracingSampleRatio(c.TracingSampler); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// AdminAddr is the address the admin server listens on.
+func (c *Config) AdminAddr() string {
+	return fmt.Sprintf(":%d", c.AdminPort)
+}
+
+// ListenAddr is the address the gRPC server listens on.
+func (c *Config) ListenAddr() string {
+	return fmt.Sprintf(":%d", c.Port)
+}
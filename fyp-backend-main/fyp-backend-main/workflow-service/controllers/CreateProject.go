@@ -4,33 +4,26 @@ package controllers
 
 import (
 	"context"
-	"errors"
+	"shared/identity"
 	"time"
-
+	"workflow-service/config"
 	"workflow-service/models"
 	workflow_service "workflow-service/proto/generated/github.com/multiagentai/backend/workflow-service"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
 
 	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 // CreateProject implements the CreateProject method of the WorkflowServiceServer interface
 func (s *WorkflowServer) CreateProject(ctx context.Context, in *workflow_service.CreateProjectRequest) (*workflow_service.CreateProjectResponse, error) {
-	// Extract userID from gRPC metadata
-	md, ok := metadata.FromIncomingContext(ctx)
-	if !ok {
-		return nil, errors.New("metadata not provided in gRPC context")
-	}
-
-	userIDs := md.Get("userID")
-	if len(userIDs) == 0 {
-		return nil, errors.New("userID not found in metadata")
+	// Extract the userID of the verified caller
+	userID, err := identity.UserID(ctx)
+	if err != nil {
+		return nil, err
 	}
-	userID := userIDs[0]
 	project := models.Project{
 		Title:       in.Title,
 		Description: in.Description,
@@ -41,7 +34,7 @@ func (s *WorkflowServer) CreateProject(ctx context.Context, in *workflow_service
 	}
 
 	// Insert the project into the database
-	insertedProject, err := s.DocDB.Database("fyp-db").Collection("projects").InsertOne(ctx, project)
+	insertedProject, err := s.DocDB.Database(config.Current.MongoDatabase).Collection("projects").InsertOne(ctx, project)
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "Failed to insert project: %v", err)
 	}
@@ -3,36 +3,33 @@ package controllers
 //WORKS
 import (
 	"context"
-	"errors"
+	"shared/identity"
 	"time"
+	"workflow-service/config"
+	"workflow-service/metrics"
 	"workflow-service/models"
 	workflow_service "workflow-service/proto/generated/github.com/multiagentai/backend/workflow-service"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
 
 	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 func (s *WorkflowServer) CreateWorkflow(ctx context.Context, in *workflow_service.CreateWorkflowRequest) (*workflow_service.CreateWorkflowResponse, error) {
-	// Extract userID from gRPC metadata
-	md, ok := metadata.FromIncomingContext(ctx)
-	if !ok {
-		return nil, errors.New("metadata not provided in gRPC context")
-	}
-	userIDs := md.Get("userID")
-	if len(userIDs) == 0 {
-		return nil, errors.New("userID not found in metadata")
+	// Extract the userID of the verified caller
+	userID, err := identity.UserID(ctx)
+	if err != nil {
+		return nil, err
 	}
-	userID := userIDs[0]
 
 	workflow := models.Workflow{
 		Name:        in.Name,
 		Description: in.Description,
 		Public:      in.Public,
 		WorkflowURL: in.WorkflowURL,
+		WorkflowKey: models.AttachmentKey(in.WorkflowURL),
 		CreatedBy:   userID,
 		CreatedAt:   time.Now(),
 		UpdatedAt:   time.Now(),
@@ -43,10 +40,11 @@ func (s *WorkflowServer) CreateWorkflow(ctx context.Context, in *workflow_servic
 		workflow.ProjectID = in.ProjectId
 	}
 	// insert into database
-	result, err := s.DocDB.Database("fyp-db").Collection("workflows").InsertOne(ctx, workflow)
+	result, err := s.DocDB.Database(config.Current.MongoDatabase).Collection("workflows").InsertOne(ctx, workflow)
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "failed to create workflow: %v", err)
 	}
+	metrics.WorkflowsCreated.Inc()
 
 	// return response
 	return &workflow_service.CreateWorkflowResponse{
@@ -3,36 +3,30 @@ package controllers
 // WORKS
 import (
 	"context"
-	"errors"
+	"shared/identity"
 	"time"
+	"workflow-service/config"
 	workflow_service "workflow-service/proto/generated/github.com/multiagentai/backend/workflow-service"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 )
 
 func (s *WorkflowServer) DeleteProject(ctx context.Context, in *workflow_service.DeleteProjectRequest) (*workflow_service.DeleteProjectResponse, error) {
-	// Extract userID from gRPC metadata
-	md, ok := metadata.FromIncomingContext(ctx)
-	if !ok {
-		return nil, errors.New("metadata not provided in gRPC context")
-	}
-
-	userIDs := md.Get("userID")
-	if len(userIDs) == 0 {
-		return nil, errors.New("userID not found in metadata")
+	// Extract the userID of the verified caller
+	userID, err := identity.UserID(ctx)
+	if err != nil {
+		return nil, err
 	}
-	userID := userIDs[0]
 	objectID, err := primitive.ObjectIDFromHex(in.Id)
 	if err != nil {
 		return nil, status.Errorf(codes.InvalidArgument, "invalid project ID: %v", err)
 	}
 	// fetch
-	result := s.DocDB.Database("fyp-db").Collection("projects").FindOne(ctx, bson.M{
+	result := s.DocDB.Database(config.Current.MongoDatabase).Collection("projects").FindOne(ctx, bson.M{
 		"_id":       objectID,
 		"createdBy": userID,
 	})
@@ -44,14 +38,14 @@ func (s *WorkflowServer) DeleteProject(ctx context.Context, in *workflow_service
 	}
 
 	// soft delete
-	_, err = s.DocDB.Database("fyp-db").Collection("projects").UpdateOne(ctx, bson.M{
+	_, err = s.DocDB.Database(config.Current.MongoDatabase).Collection("projects").UpdateOne(ctx, bson.M{
 		"_id": objectID,
 	}, bson.M{"$set": bson.M{"deletedAt": time.Now()}})
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "failed to delete project: %v", err)
 	}
 	// soft delete workflows
-	_, err = s.DocDB.Database("fyp-db").Collection("workflows").UpdateMany(ctx, bson.M{
+	_, err = s.DocDB.Database(config.Current.MongoDatabase).Collection("workflows").UpdateMany(ctx, bson.M{
 		"projectId": in.Id,
 	}, bson.M{"$set": bson.M{"deletedAt": time.Now()}})
 	if err != nil {
@@ -3,9 +3,9 @@ package controllers
 // WORKS
 import (
 	"context"
-	"errors"
+	"shared/identity"
 	"time"
-
+	"workflow-service/config"
 	workflow_service "workflow-service/proto/generated/github.com/multiagentai/backend/workflow-service"
 
 	"go.mongodb.org/mongo-driver/bson"
@@ -13,29 +13,22 @@ import (
 	"go.mongodb.org/mongo-driver/mongo"
 
 	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 )
 
 func (s *WorkflowServer) DeleteWorkflow(ctx context.Context, in *workflow_service.DeleteWorkflowRequest) (*workflow_service.DeleteWorkflowResponse, error) {
-	// Extract userID from gRPC metadata
-	md, ok := metadata.FromIncomingContext(ctx)
-	if !ok {
-		return nil, errors.New("metadata not provided in gRPC context")
-	}
-
-	userIDs := md.Get("userID")
-	if len(userIDs) == 0 {
-		return nil, errors.New("userID not found in metadata")
+	// Extract the userID of the verified caller
+	userID, err := identity.UserID(ctx)
+	if err != nil {
+		return nil, err
 	}
-	userID := userIDs[0]
 
 	objectID, err := primitive.ObjectIDFromHex(in.Id)
 	if err != nil {
 		return nil, status.Errorf(codes.InvalidArgument, "Invalid ID format: %v", err)
 	}
 	// fetch
-	result := s.DocDB.Database("fyp-db").Collection("workflows").FindOne(ctx, bson.M{
+	result := s.DocDB.Database(config.Current.MongoDatabase).Collection("workflows").FindOne(ctx, bson.M{
 		"_id":       objectID,
 		"createdBy": userID,
 	})
@@ -47,7 +40,7 @@ func (s *WorkflowServer) DeleteWorkflow(ctx context.Context, in *workflow_servic
 	}
 
 	// soft delete
-	_, err = s.DocDB.Database("fyp-db").Collection("workflows").UpdateOne(ctx, bson.M{
+	_, err = s.DocDB.Database(config.Current.MongoDatabase).Collection("workflows").UpdateOne(ctx, bson.M{
 		"_id": objectID,
 	}, bson.M{"$set": bson.M{"deletedAt": time.Now()}})
 	if err != nil {
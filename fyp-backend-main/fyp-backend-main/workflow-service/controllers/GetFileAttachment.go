@@ -0,0 +1,72 @@
+package controllers
+
+import (
+	"context"
+	"shared/identity"
+	"strings"
+	"time"
+	"workflow-service/config"
+	workflow_service "workflow-service/proto/generated/github.com/multiagentai/backend/workflow-service"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// GetFileAttachment tells the gateway whether an uploaded file is the code of a public workflow
+// or of one of the caller's. The gateway relies on it for files uploaded before its registry,
+// and sets owner for the others so only their uploader's workflows publish them.
+func (w *WorkflowServer) GetFileAttachment(ctx context.Context, in *workflow_service.GetFileAttachmentRequest) (*workflow_service.GetFileAttachmentResponse, error) {
+	// Extract the userID of the verified caller
+	userID, err := identity.UserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Validate the file name, uploads are stored under a single path segment
+	fileName := in.GetFileName()
+	if fileName == "" || strings.Contains(fileName, "/") {
+		return nil, status.Errorf(codes.InvalidArgument, "Invalid file name")
+	}
+
+	// Match the indexed key of the workflow URL, file names start with a UUID so they're unique
+	cursor, err := w.DocDB.Database(config.Current.MongoDatabase).Collection("workflows").Find(ctx, bson.M{
+		"workflowKey": fileName,
+		"deletedAt": bson.M{
+			"$exists": false,
+		},
+	}, options.Find().SetProjection(bson.M{"public": 1, "createdBy": 1, "createdAt": 1}))
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to fetch workflows: %v", err)
+	}
+	defer cursor.Close(ctx)
+
+	res := &workflow_service.GetFileAttachmentResponse{}
+	for cursor.Next(ctx) {
+		var workflow struct {
+			Public    bool      `bson:"public"`
+			CreatedBy string    `bson:"createdBy"`
+			CreatedAt time.Time `bson:"createdAt"`
+		}
+		if err := cursor.Decode(&workflow); err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to decode workflow: %v", err)
+		}
+		// Attaching a file someone else uploaded doesn't publish it
+		if workflow.Public && (in.GetOwner() == "" || workflow.CreatedBy == in.GetOwner()) {
+			res.Public = true
+		}
+		if workflow.CreatedBy == userID {
+			res.Owned = true
+			if res.OwnedSince == nil || workflow.CreatedAt.Before(res.OwnedSince.AsTime()) {
+				res.OwnedSince = timestamppb.New(workflow.CreatedAt)
+			}
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to fetch workflows: %v", err)
+	}
+
+	return res, nil
+}
@@ -2,6 +2,7 @@ package controllers
 
 import (
 	"context"
+	"workflow-service/config"
 	"workflow-service/models"
 	workflow_service "workflow-service/proto/generated/github.com/multiagentai/backend/workflow-service"
 
@@ -23,7 +24,7 @@ func (w *WorkflowServer) GetPaginatedCommunityWorkflows(ctx context.Context, req
 		SetSort(bson.M{"createdAt": -1}) // Sort by creation date, newest first
 
 	// Find public workflows
-	cursor, err := w.DocDB.Database("fyp-db").Collection("workflows").Find(ctx, bson.M{
+	cursor, err := w.DocDB.Database(config.Current.MongoDatabase).Collection("workflows").Find(ctx, bson.M{
 		"public": true,
 		"deletedAt": bson.M{
 			"$exists": false,
@@ -35,7 +36,7 @@ func (w *WorkflowServer) GetPaginatedCommunityWorkflows(ctx context.Context, req
 	defer cursor.Close(ctx)
 
 	// Count total number of public workflows
-	total, err := w.DocDB.Database("fyp-db").Collection("workflows").CountDocuments(ctx, bson.M{
+	total, err := w.DocDB.Database(config.Current.MongoDatabase).Collection("workflows").CountDocuments(ctx, bson.M{
 		"public": true,
 		"deletedAt": bson.M{
 			"$exists": false,
@@ -3,9 +3,8 @@ package controllers
 // WORKS
 import (
 	"context"
-	"errors"
-	"fmt"
-
+	"shared/identity"
+	"workflow-service/config"
 	"workflow-service/models"
 	workflow_service "workflow-service/proto/generated/github.com/multiagentai/backend/workflow-service"
 
@@ -14,23 +13,16 @@ import (
 	"go.mongodb.org/mongo-driver/mongo"
 
 	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 func (s *WorkflowServer) GetProjectById(ctx context.Context, in *workflow_service.GetProjectByIdRequest) (*workflow_service.GetProjectByIdResponse, error) {
-	// Extract userID from gRPC metadata
-	md, ok := metadata.FromIncomingContext(ctx)
-	if !ok {
-		return nil, errors.New("metadata not provided in gRPC context")
-	}
-
-	userIDs := md.Get("userID")
-	if len(userIDs) == 0 {
-		return nil, errors.New("userID not found in metadata")
+	// Extract the userID of the verified caller
+	userID, err := identity.UserID(ctx)
+	if err != nil {
+		return nil, err
 	}
-	userID := userIDs[0]
 
 	// Convert string ID to ObjectID
 	objectID, err := primitive.ObjectIDFromHex(in.Id)
@@ -39,7 +31,7 @@ func (s *WorkflowServer) GetProjectById(ctx context.Context, in *workflow_servic
 	}
 
 	// fetch
-	result := s.DocDB.Database("fyp-db").Collection("projects").FindOne(ctx, bson.M{
+	result := s.DocDB.Database(config.Current.MongoDatabase).Collection("projects").FindOne(ctx, bson.M{
 		"_id":       objectID,
 		"createdBy": userID,
 	})
@@ -52,7 +44,7 @@ func (s *WorkflowServer) GetProjectById(ctx context.Context, in *workflow_servic
 	}
 
 	// Fetch list of workflows
-	workflows, err := s.DocDB.Database("fyp-db").Collection("workflows").Find(ctx, bson.M{
+	workflows, err := s.DocDB.Database(config.Current.MongoDatabase).Collection("workflows").Find(ctx, bson.M{
 		"projectId": in.Id,
 	})
 	if err != nil {
@@ -65,7 +57,6 @@ func (s *WorkflowServer) GetProjectById(ctx context.Context, in *workflow_servic
 		if err := workflows.Decode(&workflow); err != nil {
 			return nil, status.Errorf(codes.Internal, "failed to decode workflow: %v", err)
 		}
-		fmt.Println(workflow)
 		workflowList = append(workflowList, &workflow_service.Workflow{
 			Id:          workflow.ID.Hex(),
 			Name:        workflow.Name,
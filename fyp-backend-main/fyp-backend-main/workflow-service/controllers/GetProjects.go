@@ -3,13 +3,12 @@ package controllers
 // WORKS
 import (
 	"context"
-	"errors"
-
+	"shared/identity"
+	"workflow-service/config"
 	"workflow-service/models"
 	workflow_service "workflow-service/proto/generated/github.com/multiagentai/backend/workflow-service"
 
 	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/timestamppb"
 
@@ -17,19 +16,13 @@ import (
 )
 
 func (s *WorkflowServer) GetProjects(ctx context.Context, in *workflow_service.GetProjectsRequest) (*workflow_service.GetProjectsResponse, error) {
-	// Extract userID from gRPC metadata
-	md, ok := metadata.FromIncomingContext(ctx)
-	if !ok {
-		return nil, errors.New("metadata not provided in gRPC context")
-	}
-
-	userIDs := md.Get("userID")
-	if len(userIDs) == 0 {
-		return nil, errors.New("userID not found in metadata")
+	// Extract the userID of the verified caller
+	userID, err := identity.UserID(ctx)
+	if err != nil {
+		return nil, err
 	}
-	userID := userIDs[0]
 
-	projects, err := s.DocDB.Database("fyp-db").Collection("projects").Find(ctx, bson.M{
+	projects, err := s.DocDB.Database(config.Current.MongoDatabase).Collection("projects").Find(ctx, bson.M{
 		"createdBy": userID,
 	})
 	if err != nil {
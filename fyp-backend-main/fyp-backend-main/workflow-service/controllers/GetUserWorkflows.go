@@ -3,34 +3,27 @@ package controllers
 // WORKS
 import (
 	"context"
-	"errors"
-
+	"shared/identity"
+	"workflow-service/config"
 	"workflow-service/models"
 	workflow_service "workflow-service/proto/generated/github.com/multiagentai/backend/workflow-service"
 
 	"go.mongodb.org/mongo-driver/bson"
 
 	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 func (s *WorkflowServer) GetUserWorkflows(ctx context.Context, in *workflow_service.GetUserWorkflowsRequest) (*workflow_service.GetUserWorkflowsResponse, error) {
-	// Extract userID from gRPC metadata
-	md, ok := metadata.FromIncomingContext(ctx)
-	if !ok {
-		return nil, errors.New("metadata not provided in gRPC context")
-	}
-
-	userIDs := md.Get("userID")
-	if len(userIDs) == 0 {
-		return nil, errors.New("userID not found in metadata")
+	// Extract the userID of the verified caller
+	userID, err := identity.UserID(ctx)
+	if err != nil {
+		return nil, err
 	}
-	userID := userIDs[0]
 
 	// get workflows from database
-	workflows, err := s.DocDB.Database("fyp-db").Collection("workflows").Find(ctx, bson.M{"createdBy": userID})
+	workflows, err := s.DocDB.Database(config.Current.MongoDatabase).Collection("workflows").Find(ctx, bson.M{"createdBy": userID})
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "failed to get workflows: %v", err)
 	}
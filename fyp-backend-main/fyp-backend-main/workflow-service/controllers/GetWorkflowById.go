@@ -3,32 +3,25 @@ package controllers
 // WORKS
 import (
 	"context"
-	"errors"
+	"shared/identity"
+	"workflow-service/config"
 	"workflow-service/models"
-
 	workflow_service "workflow-service/proto/generated/github.com/multiagentai/backend/workflow-service"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 func (s *WorkflowServer) GetWorkflowById(ctx context.Context, in *workflow_service.GetWorkflowByIdRequest) (*workflow_service.GetWorkflowByIdResponse, error) {
-	// Extract userID from gRPC metadata
-	md, ok := metadata.FromIncomingContext(ctx)
-	if !ok {
-		return nil, errors.New("metadata not provided in gRPC context")
-	}
-
-	userIDs := md.Get("userID")
-	if len(userIDs) == 0 {
-		return nil, errors.New("userID not found in metadata")
+	// Extract the userID of the verified caller
+	userID, err := identity.UserID(ctx)
+	if err != nil {
+		return nil, err
 	}
-	userID := userIDs[0]
 
 	// Convert string ID to ObjectID
 	objectID, err := primitive.ObjectIDFromHex(in.Id)
@@ -37,7 +30,7 @@ func (s *WorkflowServer) GetWorkflowById(ctx context.Context, in *workflow_servi
 	}
 
 	var workflowDoc models.Workflow
-	err = s.DocDB.Database("fyp-db").Collection("workflows").FindOne(ctx, bson.M{
+	err = s.DocDB.Database(config.Current.MongoDatabase).Collection("workflows").FindOne(ctx, bson.M{
 		"_id":       objectID,
 		"createdBy": userID,
 	}).Decode(&workflowDoc)
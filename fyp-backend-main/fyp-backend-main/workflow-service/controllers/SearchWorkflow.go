@@ -0,0 +1,111 @@
+package controllers
+
+import (
+	"context"
+	"shared/identity"
+	"strings"
+	"workflow-service/config"
+	"workflow-service/models"
+	workflow_service "workflow-service/proto/generated/github.com/multiagentai/backend/workflow-service"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 100
+)
+
+// SearchWorkflow runs a ranked full text search over the caller's workflows and public workflows
+func (s *WorkflowServer) SearchWorkflow(ctx context.Context, in *workflow_service.SearchWorkflowRequest) (*workflow_service.SearchWorkflowResponse, error) {
+	// Extract the userID of the verified caller
+	userID, err := identity.UserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Validate the search query and paging
+	query := strings.TrimSpace(in.Query)
+	if query == "" {
+		return nil, status.Error(codes.InvalidArgument, "search query cannot be empty")
+	}
+	if in.Offset < 0 {
+		return nil, status.Error(codes.InvalidArgument, "offset cannot be negative")
+	}
+	limit := in.Limit
+	if limit <= 0 {
+		limit = defaultSearchLimit
+	}
+	if limit > maxSearchLimit {
+		limit = maxSearchLimit
+	}
+
+	// Only the caller's workflows and public ones that haven't been deleted
+	filter := bson.M{
+		"$text": bson.M{"$search": query},
+		"$or": []bson.M{
+			{"createdBy": userID},
+			{"public": true},
+		},
+		"deletedAt": bson.M{"$exists": false},
+	}
+	if in.ProjectId != nil && *in.ProjectId != "" {
+		filter["projectId"] = *in.ProjectId
+	}
+
+	// Rank by text score, newest first on ties
+	findOptions := options.Find().
+		SetProjection(bson.M{"score": bson.M{"$meta": "textScore"}}).
+		SetSort(bson.D{
+			{Key: "score", Value: bson.M{"$meta": "textScore"}},
+			{Key: "createdAt", Value: -1},
+		}).
+		SetSkip(int64(in.Offset)).
+		SetLimit(int64(limit))
+
+	collection := s.DocDB.Database(config.Current.MongoDatabase).Collection("workflows")
+	cursor, err := collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to search workflows: %v", err)
+	}
+	defer cursor.Close(ctx)
+
+	total, err := collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to count workflows: %v", err)
+	}
+
+	workflows := []*workflow_service.Workflow{}
+	for cursor.Next(ctx) {
+		var workflow models.Workflow
+		if err := cursor.Decode(&workflow); err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to decode workflow: %v", err)
+		}
+
+		workflows = append(workflows, &workflow_service.Workflow{
+			Id:          workflow.ID.Hex(),
+			Name:        workflow.Name,
+			Description: workflow.Description,
+			CreatedBy:   workflow.CreatedBy,
+			Public:      workflow.Public,
+			WorkflowURL: workflow.WorkflowURL,
+			ProjectId:   workflow.ProjectID,
+			CreatedAt:   timestamppb.New(workflow.CreatedAt),
+			UpdatedAt:   timestamppb.New(workflow.UpdatedAt),
+			DeletedAt:   nil,
+		})
+	}
+
+	if err := cursor.Err(); err != nil {
+		return nil, status.Errorf(codes.Internal, "cursor error: %v", err)
+	}
+
+	return &workflow_service.SearchWorkflowResponse{
+		Workflows: workflows,
+		Total:     int32(total),
+	}, nil
+}
@@ -2,32 +2,26 @@ package controllers
 
 import (
 	"context"
-	"errors"
+	"shared/identity"
 	"strings"
 	"time"
+	"workflow-service/config"
 	"workflow-service/models"
 	workflow_service "workflow-service/proto/generated/github.com/multiagentai/backend/workflow-service"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 func (s *WorkflowServer) UpdateProject(ctx context.Context, in *workflow_service.UpdateProjectRequest) (*workflow_service.UpdateProjectResponse, error) {
-	// Extract userID from gRPC metadata
-	md, ok := metadata.FromIncomingContext(ctx)
-	if !ok {
-		return nil, errors.New("metadata not provided in gRPC context")
-	}
-
-	userIDs := md.Get("userID")
-	if len(userIDs) == 0 {
-		return nil, errors.New("userID not found in metadata")
+	// Extract the userID of the verified caller
+	userID, err := identity.UserID(ctx)
+	if err != nil {
+		return nil, err
 	}
-	userID := userIDs[0]
 	// Convert string ID to ObjectID
 	objectID, err := primitive.ObjectIDFromHex(in.Id)
 	if err != nil {
@@ -46,7 +40,7 @@ func (s *WorkflowServer) UpdateProject(ctx context.Context, in *workflow_service
 	}
 
 	// perform update with permission check
-	updateResult, err := s.DocDB.Database("fyp-db").Collection("projects").UpdateOne(ctx, bson.M{
+	updateResult, err := s.DocDB.Database(config.Current.MongoDatabase).Collection("projects").UpdateOne(ctx, bson.M{
 		"_id":       objectID,
 		"createdBy": userID,
 	}, bson.M{"$set": update})
@@ -58,7 +52,7 @@ func (s *WorkflowServer) UpdateProject(ctx context.Context, in *workflow_service
 	}
 
 	// fetch updated project
-	updatedResult := s.DocDB.Database("fyp-db").Collection("projects").FindOne(ctx, bson.M{
+	updatedResult := s.DocDB.Database(config.Current.MongoDatabase).Collection("projects").FindOne(ctx, bson.M{
 		"_id": objectID,
 	})
 	if updatedResult.Err() != nil {
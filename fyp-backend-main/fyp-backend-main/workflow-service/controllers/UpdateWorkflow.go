@@ -2,9 +2,10 @@ package controllers
 
 import (
 	"context"
-	"errors"
+	"shared/identity"
 	"strings"
 	"time"
+	"workflow-service/config"
 	"workflow-service/models"
 	workflow_service "workflow-service/proto/generated/github.com/multiagentai/backend/workflow-service"
 
@@ -13,23 +14,16 @@ import (
 	"go.mongodb.org/mongo-driver/mongo"
 
 	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 func (s *WorkflowServer) UpdateWorkflow(ctx context.Context, in *workflow_service.UpdateWorkflowRequest) (*workflow_service.UpdateWorkflowResponse, error) {
-	// Extract userID from gRPC metadata
-	md, ok := metadata.FromIncomingContext(ctx)
-	if !ok {
-		return nil, errors.New("metadata not provided in gRPC context")
-	}
-
-	userIDs := md.Get("userID")
-	if len(userIDs) == 0 {
-		return nil, errors.New("userID not found in metadata")
+	// Extract the userID of the verified caller
+	userID, err := identity.UserID(ctx)
+	if err != nil {
+		return nil, err
 	}
-	userID := userIDs[0]
 
 	// Convert string ID to ObjectID
 	objectID, err := primitive.ObjectIDFromHex(in.Id)
@@ -52,6 +46,7 @@ func (s *WorkflowServer) UpdateWorkflow(ctx context.Context, in *workflow_servic
 
 	if strings.TrimSpace(in.WorkflowURL) != "" {
 		update["workflowURL"] = in.WorkflowURL
+		update["workflowKey"] = models.AttachmentKey(in.WorkflowURL)
 	}
 
 	// Handle optional projectId
@@ -61,7 +56,7 @@ func (s *WorkflowServer) UpdateWorkflow(ctx context.Context, in *workflow_servic
 
 	// Perform the update and get the updated document in one operation
 	var workflowDoc models.Workflow
-	err = s.DocDB.Database("fyp-db").Collection("workflows").FindOneAndUpdate(
+	err = s.DocDB.Database(config.Current.MongoDatabase).Collection("workflows").FindOneAndUpdate(
 		ctx,
 		bson.M{
 			"_id":       objectID,
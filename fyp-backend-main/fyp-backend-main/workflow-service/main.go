@@ -1,39 +1,156 @@
 package main
 
 import (
-	"fmt"
+	"context"
 	"log"
+	"log/slog"
 	"net"
+	"os"
+	"os/signal"
+	"shared/envconfig"
+	"shared/identity"
+	"shared/logging"
+	"shared/platform"
+	"syscall"
+	"time"
+	"workflow-service/config"
 	"workflow-service/controllers"
+	"workflow-service/metrics"
 	workflow_service "workflow-service/proto/generated/github.com/multiagentai/backend/workflow-service"
 	"workflow-service/utils"
 
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc/filters"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/keepalive"
 )
 
 func main() {
+	// Load the configuration
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatal(err)
+	}
+	config.Current = cfg
+	if err := logging.Init(cfg.LogLevel, cfg.LogFormat); err != nil {
+		log.Fatal(err)
+	}
+	slog.Info("Effective configuration", "config", envconfig.Redacted(cfg))
+
+	// Shut down gracefully on SIGINT or SIGTERM
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Export spans, continuing the traces started by the gateway
+	shutdownTracing, err := platform.InitTracing(ctx, platform.TracingOptions{
+		Exporter:     cfg.TracingExporter,
+		Sampler:      cfg.TracingSampler,
+		OTLPEndpoint: cfg.OTLPEndpoint,
+		OTLPInsecure: cfg.OTLPInsecure,
+	}, "workflow-service")
+	if err != nil {
+		logging.Fatal("Failed to set up tracing", "error", err)
+	}
+
+	// Serve /metrics on the admin port
+	adminServer := metrics.Serve(cfg.AdminAddr())
+
 	// Connect to the MongoDB Database
-	db, err := utils.ConnectToDB()
+	db, err := utils.ConnectToDB(ctx, cfg)
 	if err != nil {
-		log.Fatalf("Failed to connect to MongoDB: %v", err)
+		logging.Fatal("Failed to connect to MongoDB", "error", err)
+	}
+	// Make sure the search indexes exist
+	if err := utils.EnsureIndexes(db); err != nil {
+		logging.Fatal("Failed to create MongoDB indexes", "error", err)
+	}
+	// Index the files attached to workflows created before the key was stored
+	if err := utils.BackfillAttachmentKeys(db); err != nil {
+		logging.Fatal("Failed to backfill workflow keys", "error", err)
 	}
 	// Set up gRPC server
-	fmt.Println("Starting gRPC server on port 50002")
+	slog.Info("Starting gRPC server", "port", cfg.Port)
 
-	listener, err := net.Listen("tcp", ":50002")
+	listener, err := net.Listen("tcp", cfg.ListenAddr())
 	if err != nil {
-		log.Fatalf("Failed to listen: %v", err)
+		logging.Fatal("Failed to listen", "error", err)
 	}
 
-	grpcServer := grpc.NewServer()
+	// Only accept calls carrying an assertion signed by the gateway
+	verifier, err := identity.LoadVerifier("workflow-service", cfg.InternalAuthPublicKeyFiles)
+	if err != nil {
+		logging.Fatal("Failed to load internal auth keys", "error", err)
+	}
+
+	// TLS or mutual TLS with the gateway, depending on the certificates configured
+	creds, err := platform.ServerCredentials(platform.ServerTLSOptions{
+		CertFile:         cfg.TLSCertFile,
+		KeyFile:          cfg.TLSKeyFile,
+		ClientCAFile:     cfg.TLSClientCAFile,
+		AllowedClientIDs: cfg.TLSAllowedClientIDs,
+	})
+	if err != nil {
+		logging.Fatal("Failed to load TLS credentials", "error", err)
+	}
+
+	grpcServer := grpc.NewServer(
+		creds,
+		// Trace every call except health checks, the trace context comes in the call metadata
+		grpc.StatsHandler(otelgrpc.NewServerHandler(otelgrpc.WithFilter(filters.Not(filters.HealthCheck())))),
+		// Allow the gateway's keepalive pings, and close connections after a while so
+		// clients resolve the service again and spread their calls over new replicas
+		grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{MinTime: 10 * time.Second, PermitWithoutStream: true}),
+		grpc.KeepaliveParams(keepalive.ServerParameters{MaxConnectionAge: 5 * time.Minute, MaxConnectionAgeGrace: 30 * time.Second}),
+		grpc.ChainUnaryInterceptor(
+			metrics.UnaryServerInterceptor(),
+			identity.UnaryServerInterceptor(verifier),
+			// Log every call with the request ID and user ID from the verified assertion
+			logging.UnaryServerInterceptor(),
+			// Deadlines and cancellation from the gateway reach MongoDB through the call context
+			platform.ContextErrorUnaryInterceptor(),
+		),
+		grpc.ChainStreamInterceptor(identity.StreamServerInterceptor(verifier)),
+	)
 
 	// Register the Workflow service
 	workflow_service.RegisterWorkflowServiceServer(grpcServer, &controllers.WorkflowServer{
 		DocDB: db, // Pass the database connection to the server
 	})
 
+	// Report NOT_SERVING on the standard health service while MongoDB can't be reached
+	healthServer := health.NewServer()
+	healthpb.RegisterHealthServer(grpcServer, healthServer)
+	platform.WatchHealth(healthServer, workflow_service.WorkflowService_ServiceDesc.ServiceName,
+		platform.HealthCheck{Name: "MongoDB", Ping: func(ctx context.Context) error { return db.Ping(ctx, nil) }},
+	)
+
 	// Start the server
-	if err := grpcServer.Serve(listener); err != nil {
-		log.Fatalf("Failed to serve: %v", err)
+	go func() {
+		if err := grpcServer.Serve(listener); err != nil {
+			logging.Fatal("Failed to serve", "error", err)
+		}
+	}()
+
+	// Report NOT_SERVING so no new calls are routed here, then drain the in-flight ones
+	<-ctx.Done()
+	stop()
+	slog.Info("Shutting down")
+	healthServer.Shutdown()
+	platform.GracefulStop(grpcServer, cfg.ShutdownTimeout)
+
+	// Close the connections to MongoDB
+	closeCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := db.Disconnect(closeCtx); err != nil {
+		slog.Error("Failed to disconnect from MongoDB", "error", err)
+	}
+	if err := adminServer.Shutdown(closeCtx); err != nil {
+		slog.Error("Failed to stop the admin server", "error", err)
+	}
+	if err := shutdownTracing(closeCtx); err != nil {
+		slog.Error("Failed to flush spans", "error", err)
 	}
+	slog.Info("Stopped")
 }
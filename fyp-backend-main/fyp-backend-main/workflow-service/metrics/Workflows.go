@@ -0,0 +1,12 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// WorkflowsCreated counts workflows created by users
+var WorkflowsCreated = promauto.NewCounter(prometheus.CounterOpts{
+	Name: "workflows_created_total",
+	Help: "Workflows created.",
+})
@@ -1,6 +1,8 @@
 package models
 
 import (
+	"net/url"
+	"path"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -17,4 +19,21 @@ type Workflow struct {
 	CreatedAt   time.Time          `bson:"createdAt,omitempty"`
 	UpdatedAt   time.Time          `bson:"updatedAt,omitempty"`
 	DeletedAt   *time.Time         `bson:"deletedAt,omitempty"`
+	// WorkflowKey is the file the workflow URL names, indexed so uploads can be matched
+	// to the workflows they're the code of
+	WorkflowKey string `bson:"workflowKey"`
+}
+
+// AttachmentKey returns the key of the uploaded file a URL names, its last path segment.
+// Gateway file URLs end with the key, which starts with a UUID so it's unique.
+func AttachmentKey(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Path == "" {
+		return ""
+	}
+	key := path.Base(u.Path)
+	if key == "/" || key == "." {
+		return ""
+	}
+	return key
 }
@@ -0,0 +1,57 @@
+package utils
+
+import (
+	"context"
+	"log/slog"
+	"time"
+	"workflow-service/config"
+	"workflow-service/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// BackfillAttachmentKeys sets the workflow key of workflows created before it was stored,
+// once, so GetFileAttachment can match them
+func BackfillAttachmentKeys(client *mongo.Client) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	collection := client.Database(config.Current.MongoDatabase).Collection("workflows")
+	cursor, err := collection.Find(ctx, bson.M{
+		"workflowKey": bson.M{"$exists": false},
+	}, options.Find().SetProjection(bson.M{"workflowURL": 1}))
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	updated := 0
+	for cursor.Next(ctx) {
+		var workflow struct {
+			ID          primitive.ObjectID `bson:"_id"`
+			WorkflowURL string             `bson:"workflowURL"`
+		}
+		if err := cursor.Decode(&workflow); err != nil {
+			return err
+		}
+		// Workflows without a URL get an empty key, so they aren't read again
+		_, err := collection.UpdateByID(ctx, workflow.ID, bson.M{"$set": bson.M{
+			"workflowKey": models.AttachmentKey(workflow.WorkflowURL),
+		}})
+		if err != nil {
+			return err
+		}
+		updated++
+	}
+	if err := cursor.Err(); err != nil {
+		return err
+	}
+
+	if updated > 0 {
+		slog.Info("Stored the workflow key of existing workflows", "count", updated)
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package utils
+
+import (
+	"context"
+	"log/slog"
+	"time"
+	"workflow-service/config"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// EnsureIndexes creates the indexes the workflow service relies on
+func EnsureIndexes(client *mongo.Client) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	// Text index used by SearchWorkflow, matches in the name rank higher
+	_, err := client.Database(config.Current.MongoDatabase).Collection("workflows").Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{
+			{Key: "name", Value: "text"},
+			{Key: "description", Value: "text"},
+		},
+		Options: options.Index().
+			SetName("workflow_text_search").
+			SetWeights(bson.D{
+				{Key: "name", Value: 10},
+				{Key: "description", Value: 2},
+			}),
+	})
+	if err != nil {
+		return err
+	}
+
+	// Used by GetFileAttachment to find the workflows an uploaded file is the code of
+	_, err = client.Database(config.Current.MongoDatabase).Collection("workflows").Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "workflowKey", Value: 1}},
+		Options: options.Index().SetName("workflow_key"),
+	})
+	if err != nil {
+		return err
+	}
+
+	slog.Info("MongoDB indexes are up to date")
+	return nil
+}